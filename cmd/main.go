@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 	"os/signal"
@@ -28,6 +29,21 @@ func waitForCtrlC() {
 }
 
 func main() {
+	chartConfig := finance_svc.DefaultChartSVCConfig()
+
+	chartRenderer := flag.String("chart_renderer", string(finance_svc.ChartRendererGo), "chart renderer to use (go or python)")
+	flag.IntVar(&chartConfig.RenewalWorkers, "chart_workers", chartConfig.RenewalWorkers, "number of charts renewed concurrently")
+	flag.DurationVar(&chartConfig.RenderTimeout, "chart_render_timeout", chartConfig.RenderTimeout, "max time of a chart render")
+	chartDiskBudgetMB := flag.Int64("chart_disk_budget_mb", chartConfig.DiskBudget/(1024*1024), "max total size of chart files in MB, 0 for no limit")
+	flag.DurationVar(&chartConfig.IntradayRefresh, "chart_intraday_refresh", chartConfig.IntradayRefresh, "how often intraday charts are renewed while their market is open")
+	flag.DurationVar(&chartConfig.CleanupInterval, "chart_cleanup_interval", chartConfig.CleanupInterval, "how often chart files are cleaned up")
+	quoteProviders := flag.String("quote_providers", "yahoo,stooq", "comma separated quote providers in failover order (yahoo, stooq)")
+	displayCurrency := flag.String("display_currency", "", "currency prices are shown in (USD or KRW), empty for their own currency; indexes are not converted if known by ^ or the yahoo quote type")
+	adminAddress := flag.String("admin_address", "127.0.0.1:8080", "address of the admin pages listener, keep it local, empty to disable")
+	flag.Parse()
+
+	chartConfig.DiskBudget = *chartDiskBudgetMB * 1024 * 1024
+
 	log.Info("Starting Time Service...")
 	timeSVC, err := finance_svc.InitTimeSVC()
 	if err != nil {
@@ -36,8 +52,21 @@ func main() {
 	defer timeSVC.Close()
 	log.Info("Time Service Started")
 
+	log.Info("Starting History Service...")
+	historySVC, err := finance_svc.InitHistorySVC()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer historySVC.Close()
+	log.Info("History Service Started")
+
 	log.Info("Starting Chart Service...")
-	chartSVC, err := finance_svc.InitChartSVC(timeSVC)
+	renderer, err := finance_svc.NewChartRenderer(finance_svc.ChartRendererType(*chartRenderer), timeSVC, historySVC)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	chartSVC, err := finance_svc.InitChartSVC(timeSVC, renderer, chartConfig)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -45,7 +74,12 @@ func main() {
 	log.Info("Chart Service Started")
 
 	log.Info("Starting Price Service...")
-	priceSVC, err := finance_svc.InitPriceSVC(timeSVC)
+	providers, err := finance_svc.NewQuoteProviders(*quoteProviders)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	priceSVC, err := finance_svc.InitPriceSVC(timeSVC, providers)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -61,7 +95,7 @@ func main() {
 	log.Info("Price Feer & Greed Index  Started")
 
 	log.Info("Starting Web Service...")
-	webSVC, err := web_svc.InitWebSVC(timeSVC, chartSVC, priceSVC, feerGreedSVC)
+	webSVC, err := web_svc.InitWebSVC(timeSVC, chartSVC, priceSVC, feerGreedSVC, historySVC, *displayCurrency, *adminAddress)
 	if err != nil {
 		log.Fatal(err)
 	}
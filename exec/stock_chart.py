@@ -9,28 +9,296 @@ import matplotlib.pyplot as plt
 #https://www.codementor.io/@hachimy15/quantitative-finance-and-data-visualization-in-python-for-beginners-16apvwc49b
 #https://matplotlib.org/2.0.2/examples/pylab_examples/simple_plot.html
 
+UP_COLOR = "#26a69a"
+DOWN_COLOR = "#ef5350"
+
+# figure size in pixels and dots per inch
+FIGURE = {"width": 640, "height": 480, "dpi": 100}
+
+def newFigure(rows=1, **kwargs):
+    dpi = FIGURE["dpi"]
+    return plt.subplots(rows, 1, figsize=(FIGURE["width"] / dpi, FIGURE["height"] / dpi), dpi=dpi, **kwargs)
+
+def writeFigure(fig, filepath):
+    fig.tight_layout()
+    fig.savefig(filepath, dpi=FIGURE["dpi"])
+
+def isIntraday(interval):
+    return interval.endswith("m") and not interval.endswith("mo") or interval.endswith("h")
 
 def getData(ticker, period, interval):
-    data = yf.download(ticker, period=period, interval=interval, progress=False)
+    # extended hours bars are shown on intraday charts
+    data = yf.download(ticker, period=period, interval=interval, prepost=isIntraday(interval), progress=False)
     return data
 
-def saveChart(data, period, filepath):
-    data["Adj Close"].plot()
-    plt.xlabel("Time %s" % period)
-    plt.ylabel("Price")
-    plt.savefig(filepath, bbox_inches='tight')
+def barSessions(data, boundaries):
+    # boundaries are pre-market start, market start, market end and after-market end in new york time
+    preStart, marketStart, marketEnd, afterEnd = boundaries
+    index = data.index
+    if index.tz is not None:
+        index = index.tz_convert("America/New_York")
+    sessions = []
+    for t in index:
+        hm = t.strftime("%H:%M")
+        if hm < preStart or hm >= afterEnd:
+            sessions.append("overnight")
+        elif hm < marketStart:
+            sessions.append("pre")
+        elif hm < marketEnd:
+            sessions.append("market")
+        else:
+            sessions.append("after")
+    return sessions
+
+def plotSessions(axes, data, boundaries):
+    # shade bars outside market hours and mark the open and close
+    sessions = barSessions(data, boundaries)
+    start = 0
+    for idx in range(1, len(sessions) + 1):
+        if idx < len(sessions) and sessions[idx] == sessions[start]:
+            continue
+        for ax in axes:
+            if sessions[start] != "market":
+                ax.axvspan(start - 0.5, idx - 0.5, color="gray", alpha=0.15, linewidth=0)
+            if idx < len(sessions) and "market" in (sessions[start], sessions[idx]):
+                ax.axvline(idx - 0.5, color="gray", linewidth=0.8, linestyle="--")
+        start = idx
+
+def barColors(data):
+    return [DOWN_COLOR if c < o else UP_COLOR for o, c in zip(data["Open"], data["Close"])]
+
+def plotPrice(ax, data, style):
+    x = range(len(data))
+    if style == "candle":
+        colors = barColors(data)
+        ax.vlines(x, data["Low"], data["High"], colors=colors, linewidth=1)
+        bottoms = [min(o, c) for o, c in zip(data["Open"], data["Close"])]
+        heights = [abs(c - o) for o, c in zip(data["Open"], data["Close"])]
+        ax.bar(x, heights, bottom=bottoms, color=colors, width=0.7)
+    elif style == "ohlc":
+        colors = barColors(data)
+        ax.vlines(x, data["Low"], data["High"], colors=colors, linewidth=1)
+        ax.hlines(data["Open"], [i - 0.35 for i in x], x, colors=colors, linewidth=1)
+        ax.hlines(data["Close"], x, [i + 0.35 for i in x], colors=colors, linewidth=1)
+    elif style == "area":
+        ax.plot(x, data["Adj Close"])
+        ax.fill_between(x, data["Adj Close"], min(data["Adj Close"]), alpha=0.25)
+    else:
+        ax.plot(x, data["Adj Close"])
+
+def setTimeLabels(ax, data):
+    count = min(5, len(data))
+    if count == 0:
+        return
+    ticks = [round(i * (len(data) - 1) / max(count - 1, 1)) for i in range(count)]
+    ax.set_xticks(ticks)
+    ax.set_xticklabels([str(data.index[i])[:16] for i in ticks], rotation=15)
+
+def parseIndicators(spec):
+    indicators = {"sma": [], "ema": [], "bb": 0, "vwap": False, "rsi": 0, "macd": False}
+    for part in spec.split("_"):
+        if part.startswith("sma"):
+            indicators["sma"] = [int(w) for w in part[3:].split("-")]
+        elif part.startswith("ema"):
+            indicators["ema"] = [int(w) for w in part[3:].split("-")]
+        elif part.startswith("bb"):
+            indicators["bb"] = int(part[2:])
+        elif part == "vwap":
+            indicators["vwap"] = True
+        elif part.startswith("rsi"):
+            indicators["rsi"] = int(part[3:])
+        elif part == "macd":
+            indicators["macd"] = True
+    return indicators
+
+def priceSeries(data, style):
+    if style in ("candle", "ohlc"):
+        return data["Close"]
+    return data["Adj Close"]
+
+def plotOverlays(ax, data, style, indicators):
+    x = range(len(data))
+    price = priceSeries(data, style)
+    for window in indicators["sma"]:
+        ax.plot(x, price.rolling(window).mean(), linewidth=1, label="SMA%d" % window)
+    for window in indicators["ema"]:
+        ax.plot(x, price.ewm(span=window, adjust=False).mean(), linewidth=1, label="EMA%d" % window)
+    if indicators["bb"] > 0:
+        window = indicators["bb"]
+        middle = price.rolling(window).mean()
+        stddev = price.rolling(window).std(ddof=0)
+        ax.plot(x, middle, color="#7f7f7f", linewidth=1, label="BB%d" % window)
+        ax.fill_between(x, middle + 2 * stddev, middle - 2 * stddev, color="#7f7f7f", alpha=0.15)
+    if indicators["vwap"]:
+        typical = (data["High"] + data["Low"] + data["Close"]) / 3
+        vwap = (typical * data["Volume"]).cumsum() / data["Volume"].cumsum()
+        ax.plot(x, vwap, color="#d62728", linewidth=1, label="VWAP")
+    if ax.get_legend_handles_labels()[0]:
+        ax.legend(loc="upper left", fontsize="small")
+
+def plotRSI(ax, data, style, window):
+    change = priceSeries(data, style).diff()
+    gain = change.clip(lower=0).ewm(alpha=1.0 / window, adjust=False).mean()
+    loss = (-change.clip(upper=0)).ewm(alpha=1.0 / window, adjust=False).mean()
+    rsi = 100 - 100 / (1 + gain / loss)
+    ax.plot(range(len(data)), rsi, color="#9467bd", linewidth=1)
+    ax.axhline(70, color="#e0e0e0")
+    ax.axhline(30, color="#e0e0e0")
+    ax.set_ylim(0, 100)
+    ax.set_ylabel("RSI%d" % window)
+
+def plotMACD(ax, data, style):
+    price = priceSeries(data, style)
+    macd = price.ewm(span=12, adjust=False).mean() - price.ewm(span=26, adjust=False).mean()
+    signal = macd.ewm(span=9, adjust=False).mean()
+    histogram = macd - signal
+    x = range(len(data))
+    ax.bar(x, histogram, color=[UP_COLOR if v >= 0 else DOWN_COLOR for v in histogram], width=0.7)
+    ax.plot(x, macd, color="#1f77b4", linewidth=1)
+    ax.plot(x, signal, color="#ff7f0e", linewidth=1)
+    ax.set_ylabel("MACD")
+
+def saveCompareChart(ticker, compareTickers, period, interval, filepath):
+    fig, ax = newFigure()
+    base = None
+    prices = {}
+    for symbol in [ticker] + compareTickers:
+        data = getData(symbol, period, interval)
+        if len(data) == 0:
+            continue
+        if base is None:
+            base = data
+        prices[symbol] = data["Adj Close"].reindex(base.index, method="ffill")
+
+    # changes from the first bar at which all symbols have a price
+    if base is not None:
+        common = pd.DataFrame(prices).dropna()
+        for symbol, price in prices.items():
+            if len(common) == 0:
+                break
+            change = (price / common[symbol].iloc[0] - 1) * 100
+            change[price.index < common.index[0]] = float("nan")
+            ax.plot(range(len(base)), change, linewidth=1, label="%s %+.2f%%" % (symbol, change.dropna().iloc[-1]))
+
+    ax.axhline(0, color="#9e9e9e", linewidth=1)
+    if base is not None:
+        setTimeLabels(ax, base)
+        ax.legend(loc="upper left", fontsize="small")
+    ax.set_xlabel("Time %s" % period)
+    ax.set_ylabel("Change (%)")
+    writeFigure(fig, filepath)
+
+def getEvents(ticker):
+    # earnings dates, ex-dividend dates and splits as (date, kind, label)
+    t = yf.Ticker(ticker)
+    events = []
+    for date, amount in t.dividends.items():
+        events.append((date, "dividend", "D %g" % amount))
+    for date, ratio in t.splits.items():
+        events.append((date, "split", "S %g:1" % ratio))
+    try:
+        for date in t.earnings_dates.index:
+            events.append((date, "earnings", "E"))
+    except Exception:
+        # earnings dates are not available for all tickers
+        pass
+    return events
+
+EVENT_COLORS = {"earnings": "#1f77b4", "dividend": "#2ca02c", "split": "#ff7f0e"}
+
+def plotEvents(ax, data, events):
+    # mark events at the first bar of their day, or the bar containing them
+    if len(data) == 0:
+        return
+    days = [str(t)[:10] for t in data.index]
+    for date, kind, label in events:
+        day = str(date)[:10]
+        if day < days[0] or day > days[-1]:
+            continue
+        idx = max(i for i, d in enumerate(days) if d <= day)
+        if day in days:
+            idx = days.index(day)
+        ax.axvline(idx, color=EVENT_COLORS[kind], alpha=0.4, linewidth=1)
+        ax.annotate(label, xy=(idx, 0), xycoords=("data", "axes fraction"), ha="center", va="bottom",
+            fontsize="x-small", color="white", bbox=dict(boxstyle="square,pad=0.2", fc=EVENT_COLORS[kind], lw=0))
+
+def saveChart(data, period, filepath, style, volume, indicators, boundaries, events):
+    subplots = []
+    if volume:
+        subplots.append("volume")
+    if indicators["rsi"] > 0:
+        subplots.append("rsi")
+    if indicators["macd"]:
+        subplots.append("macd")
+
+    fig, axes = newFigure(1 + len(subplots), sharex=True, squeeze=False, gridspec_kw={"height_ratios": [3] + [1] * len(subplots)})
+    ax = axes[0][0]
+    for name, sax in zip(subplots, [row[0] for row in axes[1:]]):
+        if name == "volume":
+            sax.bar(range(len(data)), data["Volume"], color=barColors(data), width=0.7)
+            sax.set_ylabel("Volume")
+        elif name == "rsi":
+            plotRSI(sax, data, style, indicators["rsi"])
+        elif name == "macd":
+            plotMACD(sax, data, style)
+
+    if len(boundaries) == 4:
+        plotSessions([row[0] for row in axes], data, boundaries)
+
+    bottom = axes[-1][0]
+    setTimeLabels(bottom, data)
+    bottom.set_xlabel("Time %s" % period)
+
+    plotPrice(ax, data, style)
+    plotOverlays(ax, data, style, indicators)
+    plotEvents(ax, data, events)
+    ax.set_ylabel("Price")
+    writeFigure(fig, filepath)
 
 def main(argv):
+    global UP_COLOR, DOWN_COLOR
     if len(argv) < 4:
-        print("command : ./stock_chart.py ticker period interval filepath")
+        print("command : ./stock_chart.py ticker period interval filepath [style] [volume] [indicators] [compare tickers] [width] [height] [dpi] [theme] [up color] [down color] [session boundaries] [events]")
     else:
         ticker = argv[0]
         period = argv[1]
         interval = argv[2]
         filepath = argv[3]
+        style = "line"
+        volume = False
+        indicators = parseIndicators("")
+        compareTickers = []
+        boundaries = []
+        showEvents = False
+        if len(argv) > 4:
+            style = argv[4]
+        if len(argv) > 5:
+            volume = argv[5].lower() == "true"
+        if len(argv) > 6:
+            indicators = parseIndicators(argv[6])
+        if len(argv) > 7 and len(argv[7]) > 0:
+            compareTickers = argv[7].split(",")
+        if len(argv) > 10:
+            FIGURE["width"] = int(argv[8])
+            FIGURE["height"] = int(argv[9])
+            FIGURE["dpi"] = int(argv[10])
+        if len(argv) > 11 and argv[11] == "dark":
+            plt.style.use("dark_background")
+        if len(argv) > 12 and len(argv[12]) > 0:
+            UP_COLOR = "#" + argv[12]
+        if len(argv) > 13 and len(argv[13]) > 0:
+            DOWN_COLOR = "#" + argv[13]
+        if len(argv) > 14 and len(argv[14]) > 0:
+            boundaries = argv[14].split(",")
+        if len(argv) > 15:
+            showEvents = argv[15].lower() == "true"
 
-        data = getData(ticker, period, interval)
-        saveChart(data, period, filepath)
+        if len(compareTickers) > 0:
+            saveCompareChart(ticker, compareTickers, period, interval, filepath)
+        else:
+            data = getData(ticker, period, interval)
+            events = getEvents(ticker) if showEvents else []
+            saveChart(data, period, filepath, style, volume, indicators, boundaries, events)
 
 if __name__ == "__main__":
     main(sys.argv[1:])
@@ -0,0 +1,311 @@
+package finance_svc
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+// textAnchor is horizontal alignment of a text
+type textAnchor int
+
+const (
+	textAnchorStart textAnchor = iota
+	textAnchorMiddle
+	textAnchorEnd
+)
+
+type chartPoint struct {
+	X float64
+	Y float64
+}
+
+// chartCanvas is a drawing surface of a chart
+type chartCanvas interface {
+	// Size returns width and height of the canvas
+	Size() (float64, float64)
+	// FillRect fills a rectangle
+	FillRect(x float64, y float64, w float64, h float64, c color.Color)
+	// FillPolygon fills a closed polygon
+	FillPolygon(points []chartPoint, c color.Color)
+	// StrokeLine draws a polyline
+	StrokeLine(points []chartPoint, width float64, c color.Color)
+	// DrawText draws a single line text, y is the baseline
+	DrawText(x float64, y float64, text string, anchor textAnchor, c color.Color)
+	// TextHeight returns height of a text line
+	TextHeight() float64
+	// TextWidth returns width of a single line text
+	TextWidth(text string) float64
+	// Encode writes the canvas in its image format
+	Encode(w io.Writer) error
+}
+
+const (
+	// size of the scalable font matching metrics of basicfont.Face7x13
+	rasterFontSize = 11.5
+)
+
+var (
+	rasterFont     *sfnt.Font
+	rasterFontErr  error
+	rasterFontOnce sync.Once
+)
+
+// newChartCanvas creates a canvas for the given image format. width and height are
+// in pixels, and scale enlarges coordinates, lines and texts drawn on the canvas.
+func newChartCanvas(format ChartFormat, width int, height int, scale float64, background color.Color) chartCanvas {
+	if scale <= 0 {
+		scale = 1
+	}
+
+	switch format {
+	case ChartFormatSVG:
+		return newSVGCanvas(width, height, scale, background)
+	default:
+		return newRasterCanvas(width, height, scale, background)
+	}
+}
+
+// rasterCanvas draws chart elements on an RGBA image
+type rasterCanvas struct {
+	Image *image.RGBA
+	Face  font.Face
+	Scale float64
+}
+
+func newRasterCanvas(width int, height int, scale float64, background color.Color) *rasterCanvas {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+
+	return &rasterCanvas{
+		Image: img,
+		Face:  newRasterFace(scale),
+		Scale: scale,
+	}
+}
+
+// newRasterFace returns the bitmap font at the default scale, or a scalable font of the same metrics
+func newRasterFace(scale float64) font.Face {
+	if scale == 1 {
+		return basicfont.Face7x13
+	}
+
+	rasterFontOnce.Do(func() {
+		rasterFont, rasterFontErr = opentype.Parse(gomono.TTF)
+	})
+
+	if rasterFontErr != nil {
+		return basicfont.Face7x13
+	}
+
+	face, err := opentype.NewFace(rasterFont, &opentype.FaceOptions{
+		Size:    rasterFontSize * scale,
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		return basicfont.Face7x13
+	}
+	return face
+}
+
+// Size returns width and height of the canvas
+func (canvas *rasterCanvas) Size() (float64, float64) {
+	bounds := canvas.Image.Bounds()
+	return float64(bounds.Dx()) / canvas.Scale, float64(bounds.Dy()) / canvas.Scale
+}
+
+// toPixels converts canvas coordinates to image pixels
+func (canvas *rasterCanvas) toPixels(points []chartPoint) []chartPoint {
+	if canvas.Scale == 1 {
+		return points
+	}
+
+	pixels := make([]chartPoint, 0, len(points))
+	for _, p := range points {
+		pixels = append(pixels, chartPoint{X: p.X * canvas.Scale, Y: p.Y * canvas.Scale})
+	}
+	return pixels
+}
+
+// FillRect fills a rectangle
+func (canvas *rasterCanvas) FillRect(x float64, y float64, w float64, h float64, c color.Color) {
+	canvas.FillPolygon([]chartPoint{
+		{X: x, Y: y},
+		{X: x + w, Y: y},
+		{X: x + w, Y: y + h},
+		{X: x, Y: y + h},
+	}, c)
+}
+
+// FillPolygon fills a closed polygon
+func (canvas *rasterCanvas) FillPolygon(points []chartPoint, c color.Color) {
+	if len(points) < 3 {
+		return
+	}
+
+	points = canvas.toPixels(points)
+	rasterizer, ok := canvas.newRasterizer(points, 0)
+	if !ok {
+		return
+	}
+
+	rasterizer.MoveTo(points[0].X, points[0].Y)
+	for _, p := range points[1:] {
+		rasterizer.LineTo(p.X, p.Y)
+	}
+	rasterizer.ClosePath()
+
+	rasterizer.Draw(canvas.Image, c)
+}
+
+// StrokeLine draws a polyline
+func (canvas *rasterCanvas) StrokeLine(points []chartPoint, width float64, c color.Color) {
+	if len(points) < 2 {
+		return
+	}
+
+	points = canvas.toPixels(points)
+	width *= canvas.Scale
+
+	halfWidth := width / 2
+	rasterizer, ok := canvas.newRasterizer(points, halfWidth)
+	if !ok {
+		return
+	}
+
+	for idx := 1; idx < len(points); idx++ {
+		p1 := points[idx-1]
+		p2 := points[idx]
+
+		dx := p2.X - p1.X
+		dy := p2.Y - p1.Y
+		length := math.Hypot(dx, dy)
+		if length == 0 {
+			continue
+		}
+
+		// normal vector
+		nx := -dy / length * halfWidth
+		ny := dx / length * halfWidth
+
+		// all segments share the same winding so overlapping areas are not cancelled
+		rasterizer.MoveTo(p1.X+nx, p1.Y+ny)
+		rasterizer.LineTo(p2.X+nx, p2.Y+ny)
+		rasterizer.LineTo(p2.X-nx, p2.Y-ny)
+		rasterizer.LineTo(p1.X-nx, p1.Y-ny)
+		rasterizer.ClosePath()
+	}
+
+	// joints
+	if width > 1 {
+		for _, p := range points[1 : len(points)-1] {
+			rasterizer.MoveTo(p.X-halfWidth, p.Y-halfWidth)
+			rasterizer.LineTo(p.X-halfWidth, p.Y+halfWidth)
+			rasterizer.LineTo(p.X+halfWidth, p.Y+halfWidth)
+			rasterizer.LineTo(p.X+halfWidth, p.Y-halfWidth)
+			rasterizer.ClosePath()
+		}
+	}
+
+	rasterizer.Draw(canvas.Image, c)
+}
+
+// DrawText draws a single line text, y is the baseline
+func (canvas *rasterCanvas) DrawText(x float64, y float64, text string, anchor textAnchor, c color.Color) {
+	drawer := &font.Drawer{
+		Dst:  canvas.Image,
+		Src:  image.NewUniform(c),
+		Face: canvas.Face,
+	}
+
+	x *= canvas.Scale
+	y *= canvas.Scale
+
+	textWidth := float64(drawer.MeasureString(text).Round())
+	switch anchor {
+	case textAnchorMiddle:
+		x -= textWidth / 2
+	case textAnchorEnd:
+		x -= textWidth
+	}
+
+	drawer.Dot = fixed.P(int(math.Round(x)), int(math.Round(y)))
+	drawer.DrawString(text)
+}
+
+// TextHeight returns height of a text line
+func (canvas *rasterCanvas) TextHeight() float64 {
+	return float64(canvas.Face.Metrics().Ascent.Round()) / canvas.Scale
+}
+
+// TextWidth returns width of a single line text
+func (canvas *rasterCanvas) TextWidth(text string) float64 {
+	return float64(font.MeasureString(canvas.Face, text).Round()) / canvas.Scale
+}
+
+// Encode writes the canvas in PNG format
+func (canvas *rasterCanvas) Encode(w io.Writer) error {
+	return png.Encode(w, canvas.Image)
+}
+
+// newRasterizer creates a rasterizer covering only the bounding box of points
+// expanded by margin, false if the box is out of the canvas
+func (canvas *rasterCanvas) newRasterizer(points []chartPoint, margin float64) (*boxRasterizer, bool) {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, p := range points {
+		minX = math.Min(minX, p.X)
+		minY = math.Min(minY, p.Y)
+		maxX = math.Max(maxX, p.X)
+		maxY = math.Max(maxY, p.Y)
+	}
+
+	box := image.Rect(int(math.Floor(minX-margin))-1, int(math.Floor(minY-margin))-1, int(math.Ceil(maxX+margin))+1, int(math.Ceil(maxY+margin))+1)
+	box = box.Intersect(canvas.Image.Bounds())
+	if box.Empty() {
+		return nil, false
+	}
+
+	rasterizer := vector.NewRasterizer(box.Dx(), box.Dy())
+	rasterizer.DrawOp = draw.Over
+
+	return &boxRasterizer{
+		Rasterizer: rasterizer,
+		Box:        box,
+	}, true
+}
+
+// boxRasterizer rasterizes paths in canvas coordinates into a part of the canvas
+type boxRasterizer struct {
+	Rasterizer *vector.Rasterizer
+	Box        image.Rectangle
+}
+
+func (r *boxRasterizer) MoveTo(x float64, y float64) {
+	r.Rasterizer.MoveTo(float32(x)-float32(r.Box.Min.X), float32(y)-float32(r.Box.Min.Y))
+}
+
+func (r *boxRasterizer) LineTo(x float64, y float64) {
+	r.Rasterizer.LineTo(float32(x)-float32(r.Box.Min.X), float32(y)-float32(r.Box.Min.Y))
+}
+
+func (r *boxRasterizer) ClosePath() {
+	r.Rasterizer.ClosePath()
+}
+
+func (r *boxRasterizer) Draw(dst draw.Image, c color.Color) {
+	r.Rasterizer.Draw(dst, r.Box, image.NewUniform(c), image.Point{})
+}
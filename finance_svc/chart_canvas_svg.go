@@ -0,0 +1,128 @@
+package finance_svc
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"image/color"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	svgFontFamily = "DejaVu Sans Mono, Menlo, Consolas, monospace"
+	svgFontSize   = 12
+)
+
+// svgCanvas draws chart elements as SVG elements
+type svgCanvas struct {
+	Width  int
+	Height int
+	// Scale maps canvas coordinates to pixels through the view box
+	Scale float64
+	Body  bytes.Buffer
+}
+
+func newSVGCanvas(width int, height int, scale float64, background color.Color) *svgCanvas {
+	canvas := &svgCanvas{
+		Width:  width,
+		Height: height,
+		Scale:  scale,
+	}
+
+	viewWidth, viewHeight := canvas.Size()
+	canvas.FillRect(0, 0, viewWidth, viewHeight, background)
+	return canvas
+}
+
+// Size returns width and height of the canvas
+func (canvas *svgCanvas) Size() (float64, float64) {
+	return float64(canvas.Width) / canvas.Scale, float64(canvas.Height) / canvas.Scale
+}
+
+// FillRect fills a rectangle
+func (canvas *svgCanvas) FillRect(x float64, y float64, w float64, h float64, c color.Color) {
+	fmt.Fprintf(&canvas.Body, "<rect x=\"%s\" y=\"%s\" width=\"%s\" height=\"%s\" %s/>\n", svgNumber(x), svgNumber(y), svgNumber(w), svgNumber(h), svgPaint("fill", c))
+}
+
+// FillPolygon fills a closed polygon
+func (canvas *svgCanvas) FillPolygon(points []chartPoint, c color.Color) {
+	if len(points) < 3 {
+		return
+	}
+
+	fmt.Fprintf(&canvas.Body, "<polygon points=\"%s\" %s/>\n", svgPoints(points), svgPaint("fill", c))
+}
+
+// StrokeLine draws a polyline
+func (canvas *svgCanvas) StrokeLine(points []chartPoint, width float64, c color.Color) {
+	if len(points) < 2 {
+		return
+	}
+
+	fmt.Fprintf(&canvas.Body, "<polyline points=\"%s\" fill=\"none\" stroke-width=\"%s\" stroke-linejoin=\"round\" %s/>\n", svgPoints(points), svgNumber(width), svgPaint("stroke", c))
+}
+
+// DrawText draws a single line text, y is the baseline
+func (canvas *svgCanvas) DrawText(x float64, y float64, text string, anchor textAnchor, c color.Color) {
+	textAnchor := "start"
+	switch anchor {
+	case textAnchorMiddle:
+		textAnchor = "middle"
+	case textAnchorEnd:
+		textAnchor = "end"
+	}
+
+	fmt.Fprintf(&canvas.Body, "<text x=\"%s\" y=\"%s\" text-anchor=\"%s\" %s>%s</text>\n", svgNumber(x), svgNumber(y), textAnchor, svgPaint("fill", c), html.EscapeString(text))
+}
+
+// TextHeight returns height of a text line
+func (canvas *svgCanvas) TextHeight() float64 {
+	return svgFontSize * 0.75
+}
+
+// TextWidth returns width of a single line text, assuming a monospace font
+func (canvas *svgCanvas) TextWidth(text string) float64 {
+	return svgFontSize * 0.6 * float64(len([]rune(text)))
+}
+
+// Encode writes the canvas in SVG format
+func (canvas *svgCanvas) Encode(w io.Writer) error {
+	viewWidth, viewHeight := canvas.Size()
+	header := fmt.Sprintf("<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %s %s\" font-family=\"%s\" font-size=\"%d\">\n", canvas.Width, canvas.Height, svgNumber(viewWidth), svgNumber(viewHeight), svgFontFamily, svgFontSize)
+	_, err := io.WriteString(w, header)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(canvas.Body.Bytes())
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "</svg>\n")
+	return err
+}
+
+func svgNumber(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+func svgPoints(points []chartPoint) string {
+	values := make([]string, 0, len(points))
+	for _, p := range points {
+		values = append(values, svgNumber(p.X)+","+svgNumber(p.Y))
+	}
+	return strings.Join(values, " ")
+}
+
+// svgPaint returns fill or stroke attributes of a color
+func svgPaint(attr string, c color.Color) string {
+	rgba := color.NRGBAModel.Convert(c).(color.NRGBA)
+	paint := fmt.Sprintf("%s=\"#%02x%02x%02x\"", attr, rgba.R, rgba.G, rgba.B)
+	if rgba.A != 0xff {
+		paint += fmt.Sprintf(" %s-opacity=\"%s\"", attr, strconv.FormatFloat(float64(rgba.A)/0xff, 'f', 3, 64))
+	}
+	return paint
+}
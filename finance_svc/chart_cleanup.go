@@ -0,0 +1,140 @@
+package finance_svc
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// chart files younger than this are kept even if untracked, as their cache entry may be on the way
+	stockChartOrphanGracePeriod = 10 * time.Minute // 10 min
+	// temp files older than this are left behind by crashed renders
+	stockChartTempFileGracePeriod = 1 * time.Hour // 1 hour
+)
+
+// trackedChartFile is a chart file with a cache entry
+type trackedChartFile struct {
+	Key        string
+	Path       string
+	Size       int64
+	Expiration time.Time
+}
+
+// cleanupCharts removes chart files that have no cache entry, and evicts least recently
+// requested charts while the total size of chart files exceeds the disk budget
+func (svc *ChartSVC) cleanupCharts() error {
+	logger := log.WithFields(log.Fields{
+		"package":  "ChartSVC",
+		"function": "cleanupCharts",
+	})
+
+	entries, err := ioutil.ReadDir(stockChartFileDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		logger.Error(err)
+		return err
+	}
+
+	// requests renew expiration of charts, so expiration orders charts by last request
+	trackedKeys := map[string]string{}
+	trackedExpirations := map[string]time.Time{}
+	svc.ChartsLock.Lock()
+	for key, item := range svc.Charts.Items() {
+		chartData := item.Object.(*StockChartData)
+		trackedKeys[filepath.Base(chartData.LocalFilePath)] = key
+		trackedExpirations[key] = time.Unix(0, item.Expiration)
+	}
+	svc.ChartsLock.Unlock()
+
+	now := time.Now()
+	registryFilename := filepath.Base(stockChartRegistryFile)
+
+	removedOrphans := 0
+	removedTempFiles := 0
+	freedBytes := int64(0)
+	totalBytes := int64(0)
+	trackedFiles := []trackedChartFile{}
+
+	for _, entry := range entries {
+		if entry.IsDir() || entry.Name() == registryFilename {
+			continue
+		}
+
+		path := filepath.Join(stockChartFileDir, entry.Name())
+		age := now.Sub(entry.ModTime())
+
+		if strings.HasPrefix(entry.Name(), stockChartTempFilePrefix) {
+			if age > stockChartTempFileGracePeriod && svc.removeChartFile(path) {
+				removedTempFiles++
+				freedBytes += entry.Size()
+			}
+			continue
+		}
+
+		key, tracked := trackedKeys[entry.Name()]
+		if !tracked {
+			if age > stockChartOrphanGracePeriod && svc.removeChartFile(path) {
+				removedOrphans++
+				freedBytes += entry.Size()
+			}
+			continue
+		}
+
+		totalBytes += entry.Size()
+		trackedFiles = append(trackedFiles, trackedChartFile{
+			Key:        key,
+			Path:       path,
+			Size:       entry.Size(),
+			Expiration: trackedExpirations[key],
+		})
+	}
+
+	evicted := 0
+	if svc.Config.DiskBudget > 0 && totalBytes > svc.Config.DiskBudget {
+		sort.Slice(trackedFiles, func(i int, j int) bool {
+			return trackedFiles[i].Expiration.Before(trackedFiles[j].Expiration)
+		})
+
+		for _, trackedFile := range trackedFiles {
+			if totalBytes <= svc.Config.DiskBudget {
+				break
+			}
+
+			svc.ChartsLock.Lock()
+			svc.Charts.Delete(trackedFile.Key)
+			svc.RegistryDirty = true
+			svc.ChartsLock.Unlock()
+
+			if svc.removeChartFile(trackedFile.Path) {
+				evicted++
+				totalBytes -= trackedFile.Size
+				freedBytes += trackedFile.Size
+			}
+		}
+	}
+
+	logger.Infof("Removed %d orphan charts, %d temp files and evicted %d charts, freed %d bytes, %d bytes in use (budget %d bytes)", removedOrphans, removedTempFiles, evicted, freedBytes, totalBytes, svc.Config.DiskBudget)
+	return nil
+}
+
+func (svc *ChartSVC) removeChartFile(path string) bool {
+	logger := log.WithFields(log.Fields{
+		"package":  "ChartSVC",
+		"function": "removeChartFile",
+	})
+
+	err := os.Remove(path)
+	if err != nil && !os.IsNotExist(err) {
+		logger.Error(err)
+		return false
+	}
+	return true
+}
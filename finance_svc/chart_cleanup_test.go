@@ -0,0 +1,173 @@
+package finance_svc
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	cache "github.com/patrickmn/go-cache"
+)
+
+// testChartFile is a file in the chart dir before cleanup
+type testChartFile struct {
+	Name string
+	Size int
+	Age  time.Duration
+	// Expiration of the cache entry of the file, 0 if untracked
+	Expiration time.Duration
+}
+
+func TestCleanupCharts(t *testing.T) {
+	tests := []struct {
+		name      string
+		budget    int64
+		files     []testChartFile
+		remaining []string
+	}{
+		{
+			name:   "orphans and temp files",
+			budget: 0,
+			files: []testChartFile{
+				{Name: "tracked.png", Size: 10, Age: 2 * time.Hour, Expiration: time.Hour},
+				{Name: "orphan.png", Size: 10, Age: stockChartOrphanGracePeriod + time.Minute},
+				{Name: "new_orphan.png", Size: 10, Age: time.Minute},
+				{Name: stockChartTempFilePrefix + "old.png", Size: 10, Age: stockChartTempFileGracePeriod + time.Minute},
+				{Name: stockChartTempFilePrefix + "new.png", Size: 10, Age: time.Minute},
+				{Name: filepath.Base(stockChartRegistryFile), Size: 10, Age: 2 * time.Hour},
+			},
+			remaining: []string{"new_orphan.png", "registry.json", "tmp_new.png", "tracked.png"},
+		},
+		{
+			name:   "within budget",
+			budget: 30,
+			files: []testChartFile{
+				{Name: "a.png", Size: 10, Expiration: time.Hour},
+				{Name: "b.png", Size: 20, Expiration: 2 * time.Hour},
+			},
+			remaining: []string{"a.png", "b.png"},
+		},
+		{
+			// requests renew expiration, so the earliest expiration is the least recently requested.
+			// eviction stops once the total is within budget
+			name:   "least recently requested first",
+			budget: 25,
+			files: []testChartFile{
+				{Name: "old.png", Size: 10, Expiration: time.Hour},
+				{Name: "recent.png", Size: 10, Expiration: 3 * time.Hour},
+				{Name: "older.png", Size: 10, Expiration: 30 * time.Minute},
+			},
+			remaining: []string{"old.png", "recent.png"},
+		},
+		{
+			name:   "orphans do not count",
+			budget: 15,
+			files: []testChartFile{
+				{Name: "old.png", Size: 10, Expiration: time.Hour},
+				{Name: "recent.png", Size: 10, Expiration: 2 * time.Hour},
+				{Name: "orphan.png", Size: 100, Age: time.Minute},
+			},
+			remaining: []string{"orphan.png", "recent.png"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			workDir, err := ioutil.TempDir("", "chart_cleanup_test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(workDir)
+
+			// charts are stored relative to the working dir
+			prevDir, err := os.Getwd()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = os.Chdir(workDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.Chdir(prevDir)
+
+			err = os.MkdirAll(stockChartFileDir, 0766)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			svc := &ChartSVC{
+				Config: &ChartSVCConfig{
+					DiskBudget: test.budget,
+				},
+				Charts: cache.New(cache.NoExpiration, cache.NoExpiration),
+			}
+
+			now := time.Now()
+			for _, file := range test.files {
+				path := filepath.Join(stockChartFileDir, file.Name)
+				err = ioutil.WriteFile(path, make([]byte, file.Size), 0644)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				err = os.Chtimes(path, now.Add(-file.Age), now.Add(-file.Age))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if file.Expiration > 0 {
+					svc.Charts.Set(strings.TrimSuffix(file.Name, ".png"), &StockChartData{
+						LocalFilePath: path,
+					}, file.Expiration)
+				}
+			}
+
+			err = svc.cleanupCharts()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			entries, err := ioutil.ReadDir(stockChartFileDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			remaining := []string{}
+			for _, entry := range entries {
+				remaining = append(remaining, entry.Name())
+			}
+			sort.Strings(remaining)
+
+			if strings.Join(remaining, ",") != strings.Join(test.remaining, ",") {
+				t.Fatalf("expected files %v, got %v", test.remaining, remaining)
+			}
+
+			// evicted charts are dropped from the cache with their files
+			for key, item := range svc.Charts.Items() {
+				chartData := item.Object.(*StockChartData)
+				if _, err := os.Stat(chartData.LocalFilePath); err != nil {
+					t.Fatalf("chart %s is tracked without its file - %v", key, err)
+				}
+			}
+
+			evicted := len(svc.Charts.Items()) < countTrackedFiles(test.files)
+			if evicted != svc.RegistryDirty {
+				t.Fatalf("expected registry dirty %v, got %v", evicted, svc.RegistryDirty)
+			}
+		})
+	}
+}
+
+func countTrackedFiles(files []testChartFile) int {
+	count := 0
+	for _, file := range files {
+		if file.Expiration > 0 {
+			count++
+		}
+	}
+	return count
+}
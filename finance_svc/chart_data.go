@@ -0,0 +1,333 @@
+package finance_svc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	yahooChartURL    = "https://query1.finance.yahoo.com/v8/finance/chart/"
+	yahooUserAgent   = "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/90.0.4430.93 Safari/537.36"
+	httpFetchTimeout = 30 * time.Second
+)
+
+// OHLCV is a single price bar
+type OHLCV struct {
+	Time     time.Time `json:"time"`
+	Open     float64   `json:"open"`
+	High     float64   `json:"high"`
+	Low      float64   `json:"low"`
+	Close    float64   `json:"close"`
+	AdjClose float64   `json:"adj_close"`
+	Volume   int64     `json:"volume"`
+}
+
+// OHLCVData is a series of price bars of a stock
+type OHLCVData struct {
+	Symbol   string        `json:"symbol"`
+	Currency string        `json:"currency"`
+	Period   ChartPeriod   `json:"period"`
+	Interval ChartInterval `json:"interval"`
+	Bars     []OHLCV       `json:"bars"`
+}
+
+type yahooChartResult struct {
+	Chart struct {
+		Result []struct {
+			Meta struct {
+				Currency             string `json:"currency"`
+				Symbol               string `json:"symbol"`
+				ExchangeTimezoneName string `json:"exchangeTimezoneName"`
+			} `json:"meta"`
+			Timestamp []int64 `json:"timestamp"`
+			Events    struct {
+				Dividends map[string]struct {
+					Amount float64 `json:"amount"`
+					Date   int64   `json:"date"`
+				} `json:"dividends"`
+				Splits map[string]struct {
+					Date        int64   `json:"date"`
+					Numerator   float64 `json:"numerator"`
+					Denominator float64 `json:"denominator"`
+				} `json:"splits"`
+			} `json:"events"`
+			Indicators struct {
+				Quote []struct {
+					Open   []*float64 `json:"open"`
+					High   []*float64 `json:"high"`
+					Low    []*float64 `json:"low"`
+					Close  []*float64 `json:"close"`
+					Volume []*int64   `json:"volume"`
+				} `json:"quote"`
+				AdjClose []struct {
+					AdjClose []*float64 `json:"adjclose"`
+				} `json:"adjclose"`
+			} `json:"indicators"`
+		} `json:"result"`
+		Error *struct {
+			Code        string `json:"code"`
+			Description string `json:"description"`
+		} `json:"error"`
+	} `json:"chart"`
+}
+
+// fetchOHLCV downloads price bars of the given symbol from yahoo finance
+func fetchOHLCV(ctx context.Context, symbol string, period ChartPeriod, interval ChartInterval) (*OHLCVData, error) {
+	logger := log.WithFields(log.Fields{
+		"package":  "ChartSVC",
+		"function": "fetchOHLCV",
+	})
+
+	query := url.Values{}
+	query.Set("range", string(period))
+	query.Set("interval", string(interval))
+	// extended hours bars are shown on intraday charts
+	query.Set("includePrePost", strconv.FormatBool(isIntradayInterval(interval)))
+
+	chartURL := yahooChartURL + url.PathEscape(symbol) + "?" + query.Encode()
+
+	body, err := httpGet(ctx, chartURL)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	result := yahooChartResult{}
+	err = json.Unmarshal(body, &result)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	if result.Chart.Error != nil {
+		return nil, fmt.Errorf("could not get chart data - %s, %s", symbol, result.Chart.Error.Description)
+	}
+
+	if len(result.Chart.Result) == 0 || len(result.Chart.Result[0].Indicators.Quote) == 0 {
+		return nil, fmt.Errorf("could not get chart data - %s, empty result", symbol)
+	}
+
+	chartResult := result.Chart.Result[0]
+	quote := chartResult.Indicators.Quote[0]
+
+	location := time.UTC
+	if len(chartResult.Meta.ExchangeTimezoneName) > 0 {
+		exchangeLocation, err := time.LoadLocation(chartResult.Meta.ExchangeTimezoneName)
+		if err != nil {
+			logger.Warn(err)
+		} else {
+			location = exchangeLocation
+		}
+	}
+
+	var adjClose []*float64
+	if len(chartResult.Indicators.AdjClose) > 0 {
+		adjClose = chartResult.Indicators.AdjClose[0].AdjClose
+	}
+
+	data := &OHLCVData{
+		Symbol:   symbol,
+		Currency: chartResult.Meta.Currency,
+		Period:   period,
+		Interval: interval,
+		Bars:     []OHLCV{},
+	}
+
+	for idx, timestamp := range chartResult.Timestamp {
+		closePrice, ok := floatAt(quote.Close, idx)
+		if !ok {
+			// missing bar
+			continue
+		}
+
+		bar := OHLCV{
+			Time:     time.Unix(timestamp, 0).In(location),
+			Open:     closePrice,
+			High:     closePrice,
+			Low:      closePrice,
+			Close:    closePrice,
+			AdjClose: closePrice,
+			Volume:   0,
+		}
+
+		if v, ok := floatAt(quote.Open, idx); ok {
+			bar.Open = v
+		}
+		if v, ok := floatAt(quote.High, idx); ok {
+			bar.High = v
+		}
+		if v, ok := floatAt(quote.Low, idx); ok {
+			bar.Low = v
+		}
+		if v, ok := floatAt(adjClose, idx); ok {
+			bar.AdjClose = v
+		}
+		if idx < len(quote.Volume) && quote.Volume[idx] != nil {
+			bar.Volume = *quote.Volume[idx]
+		}
+
+		data.Bars = append(data.Bars, bar)
+	}
+
+	if len(data.Bars) == 0 {
+		return nil, fmt.Errorf("could not get chart data - %s, no price bars", symbol)
+	}
+
+	return data, nil
+}
+
+// fetchStockEvents downloads earnings dates, dividends and splits of the given symbol from yahoo finance
+func fetchStockEvents(ctx context.Context, symbol string) ([]StockEvent, error) {
+	logger := log.WithFields(log.Fields{
+		"package":  "ChartSVC",
+		"function": "fetchStockEvents",
+	})
+
+	// the longest interval keeps the response small, events are reported regardless of bars
+	query := url.Values{}
+	query.Set("range", string(ChartPeriodMax))
+	query.Set("interval", string(ChartInteval3Month))
+	query.Set("events", "div|split")
+
+	chartURL := yahooChartURL + url.PathEscape(symbol) + "?" + query.Encode()
+
+	body, err := httpGet(ctx, chartURL)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	result := yahooChartResult{}
+	err = json.Unmarshal(body, &result)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	if result.Chart.Error != nil {
+		return nil, fmt.Errorf("could not get stock events - %s, %s", symbol, result.Chart.Error.Description)
+	}
+
+	if len(result.Chart.Result) == 0 {
+		return nil, fmt.Errorf("could not get stock events - %s, empty result", symbol)
+	}
+
+	chartEvents := result.Chart.Result[0].Events
+	events := []StockEvent{}
+	for _, dividend := range chartEvents.Dividends {
+		events = append(events, StockEvent{
+			Type:   StockEventDividend,
+			Time:   time.Unix(dividend.Date, 0).UTC(),
+			Amount: dividend.Amount,
+		})
+	}
+
+	for _, split := range chartEvents.Splits {
+		events = append(events, StockEvent{
+			Type:        StockEventSplit,
+			Time:        time.Unix(split.Date, 0).UTC(),
+			Numerator:   split.Numerator,
+			Denominator: split.Denominator,
+		})
+	}
+
+	// funds and indices have no earnings, their dividends and splits are still marked
+	earningsEvents, err := fetchEarningsEvents(ctx, symbol)
+	if err != nil {
+		logger.Warn(err)
+	} else {
+		events = append(events, earningsEvents...)
+	}
+
+	sortStockEvents(events)
+	return events, nil
+}
+
+// fetchEarningsEvents downloads the latest announced earnings date of the given symbol from yahoo finance.
+// The chart api does not report earnings, and quoteSummary reports only the next earnings,
+// so past earnings are kept by the history service.
+func fetchEarningsEvents(ctx context.Context, symbol string) ([]StockEvent, error) {
+	query := url.Values{}
+	query.Set("modules", "calendarEvents")
+
+	body, err := httpGet(ctx, yahooQuoteSummaryURL+url.PathEscape(strings.ToUpper(symbol))+"?"+query.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	return parseYahooEarningsEvents(symbol, body)
+}
+
+// parseYahooEarningsEvents converts calendarEvents of a quoteSummary response to earnings events.
+// Estimated windows of earnings dates are not events yet.
+func parseYahooEarningsEvents(symbol string, body []byte) ([]StockEvent, error) {
+	result := yahooQuoteSummaryResult{}
+	err := json.Unmarshal(body, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.QuoteSummary.Error != nil {
+		return nil, fmt.Errorf("could not get earnings dates - %s, %s", symbol, result.QuoteSummary.Error.Description)
+	}
+
+	if len(result.QuoteSummary.Result) == 0 {
+		return nil, fmt.Errorf("could not get earnings dates - %s, empty result", symbol)
+	}
+
+	events := []StockEvent{}
+	earningsDates := result.QuoteSummary.Result[0].CalendarEvents.Earnings.EarningsDate
+	if len(earningsDates) == 1 && earningsDates[0].Raw > 0 {
+		events = append(events, StockEvent{
+			Type: StockEventEarnings,
+			Time: time.Unix(int64(earningsDates[0].Raw), 0).UTC(),
+		})
+	}
+	return events, nil
+}
+
+func floatAt(values []*float64, idx int) (float64, bool) {
+	if idx >= len(values) || values[idx] == nil {
+		return 0, false
+	}
+	return *values[idx], true
+}
+
+func httpGet(ctx context.Context, requestURL string) ([]byte, error) {
+	request, err := http.NewRequestWithContext(ctx, "GET", requestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	request.Header.Set("User-Agent", yahooUserAgent)
+
+	client := &http.Client{
+		Timeout: httpFetchTimeout,
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("http request failed - %s, %s", requestURL, response.Status)
+	}
+
+	return body, nil
+}
@@ -0,0 +1,291 @@
+package finance_svc
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	indicatorMaxWindow = 500
+
+	bollingerDeviation = 2.0
+
+	macdFastWindow   = 12
+	macdSlowWindow   = 26
+	macdSignalWindow = 9
+
+	rsiOverbought = 70
+	rsiOversold   = 30
+)
+
+// ChartIndicators is a set of technical indicators drawn on a chart
+type ChartIndicators struct {
+	// SMA windows on the price pane
+	SMA []int
+	// EMA windows on the price pane
+	EMA []int
+	// Bollinger Bands window on the price pane, 0 to disable
+	Bollinger int
+	// VWAP on the price pane
+	VWAP bool
+	// RSI window on a sub pane, 0 to disable
+	RSI int
+	// MACD (12, 26, 9) on a sub pane
+	MACD bool
+}
+
+// IsEmpty returns true if no indicator is set
+func (indicators ChartIndicators) IsEmpty() bool {
+	return len(indicators.SMA) == 0 && len(indicators.EMA) == 0 && indicators.Bollinger == 0 && !indicators.VWAP && indicators.RSI == 0 && !indicators.MACD
+}
+
+// String returns a compact text of the indicator set, e.g., "sma20-50_bb20_rsi14"
+func (indicators ChartIndicators) String() string {
+	parts := []string{}
+	if len(indicators.SMA) > 0 {
+		parts = append(parts, "sma"+joinWindows(indicators.SMA))
+	}
+	if len(indicators.EMA) > 0 {
+		parts = append(parts, "ema"+joinWindows(indicators.EMA))
+	}
+	if indicators.Bollinger > 0 {
+		parts = append(parts, fmt.Sprintf("bb%d", indicators.Bollinger))
+	}
+	if indicators.VWAP {
+		parts = append(parts, "vwap")
+	}
+	if indicators.RSI > 0 {
+		parts = append(parts, fmt.Sprintf("rsi%d", indicators.RSI))
+	}
+	if indicators.MACD {
+		parts = append(parts, "macd")
+	}
+	return strings.Join(parts, "_")
+}
+
+// ParseIndicatorWindows converts comma separated windows, e.g., "20,50", to a sorted list
+func ParseIndicatorWindows(windows string) ([]int, error) {
+	result := []int{}
+	if len(windows) == 0 {
+		return result, nil
+	}
+
+	seen := map[int]bool{}
+	for _, field := range strings.Split(windows, ",") {
+		window, err := ParseIndicatorWindow(strings.TrimSpace(field))
+		if err != nil {
+			return nil, err
+		}
+
+		if !seen[window] {
+			seen[window] = true
+			result = append(result, window)
+		}
+	}
+
+	sort.Ints(result)
+	return result, nil
+}
+
+// ParseIndicatorWindow converts a string to an indicator window
+func ParseIndicatorWindow(window string) (int, error) {
+	value, err := strconv.Atoi(window)
+	if err != nil {
+		return 0, newInvalidChartParamError("invalid indicator window - %s", window)
+	}
+
+	if value < 2 || value > indicatorMaxWindow {
+		return 0, newInvalidChartParamError("indicator window must be between 2 and %d - %d", indicatorMaxWindow, value)
+	}
+	return value, nil
+}
+
+func joinWindows(windows []int) string {
+	values := make([]string, 0, len(windows))
+	for _, window := range windows {
+		values = append(values, strconv.Itoa(window))
+	}
+	return strings.Join(values, "-")
+}
+
+// computeSMA returns simple moving average, NaN until the window is filled
+func computeSMA(values []float64, window int) []float64 {
+	result := make([]float64, len(values))
+	sum := 0.0
+	for idx, v := range values {
+		sum += v
+		if idx >= window {
+			sum -= values[idx-window]
+		}
+
+		if idx >= window-1 {
+			result[idx] = sum / float64(window)
+		} else {
+			result[idx] = math.NaN()
+		}
+	}
+	return result
+}
+
+// computeEMA returns exponential moving average seeded with SMA of the first window
+func computeEMA(values []float64, window int) []float64 {
+	result := make([]float64, len(values))
+	alpha := 2 / float64(window+1)
+
+	sum := 0.0
+	for idx, v := range values {
+		switch {
+		case idx < window-1:
+			sum += v
+			result[idx] = math.NaN()
+		case idx == window-1:
+			sum += v
+			result[idx] = sum / float64(window)
+		default:
+			result[idx] = alpha*v + (1-alpha)*result[idx-1]
+		}
+	}
+	return result
+}
+
+// computeBollinger returns middle, upper and lower bands
+func computeBollinger(values []float64, window int, deviation float64) ([]float64, []float64, []float64) {
+	middle := computeSMA(values, window)
+	upper := make([]float64, len(values))
+	lower := make([]float64, len(values))
+
+	for idx := range values {
+		if math.IsNaN(middle[idx]) {
+			upper[idx] = math.NaN()
+			lower[idx] = math.NaN()
+			continue
+		}
+
+		variance := 0.0
+		for _, v := range values[idx-window+1 : idx+1] {
+			variance += (v - middle[idx]) * (v - middle[idx])
+		}
+		stddev := math.Sqrt(variance / float64(window))
+
+		upper[idx] = middle[idx] + deviation*stddev
+		lower[idx] = middle[idx] - deviation*stddev
+	}
+	return middle, upper, lower
+}
+
+// computeVWAP returns volume weighted average price of typical prices,
+// reset every day for intraday bars
+func computeVWAP(bars []OHLCV, intraday bool) []float64 {
+	result := make([]float64, len(bars))
+
+	priceVolume := 0.0
+	volume := 0.0
+	lastDay := ""
+	for idx, bar := range bars {
+		if intraday {
+			day := bar.Time.Format("2006-01-02")
+			if day != lastDay {
+				priceVolume = 0
+				volume = 0
+				lastDay = day
+			}
+		}
+
+		typicalPrice := (bar.High + bar.Low + bar.Close) / 3
+		priceVolume += typicalPrice * float64(bar.Volume)
+		volume += float64(bar.Volume)
+
+		if volume > 0 {
+			result[idx] = priceVolume / volume
+		} else {
+			result[idx] = math.NaN()
+		}
+	}
+	return result
+}
+
+// computeRSI returns relative strength index with Wilder's smoothing
+func computeRSI(values []float64, window int) []float64 {
+	result := make([]float64, len(values))
+	for idx := range result {
+		result[idx] = math.NaN()
+	}
+
+	if len(values) <= window {
+		return result
+	}
+
+	gain := 0.0
+	loss := 0.0
+	for idx := 1; idx <= window; idx++ {
+		change := values[idx] - values[idx-1]
+		if change > 0 {
+			gain += change
+		} else {
+			loss -= change
+		}
+	}
+	gain /= float64(window)
+	loss /= float64(window)
+	result[window] = rsiValue(gain, loss)
+
+	for idx := window + 1; idx < len(values); idx++ {
+		change := values[idx] - values[idx-1]
+		currentGain := math.Max(change, 0)
+		currentLoss := math.Max(-change, 0)
+
+		gain = (gain*float64(window-1) + currentGain) / float64(window)
+		loss = (loss*float64(window-1) + currentLoss) / float64(window)
+		result[idx] = rsiValue(gain, loss)
+	}
+	return result
+}
+
+func rsiValue(gain float64, loss float64) float64 {
+	if loss == 0 {
+		if gain == 0 {
+			return 50
+		}
+		return 100
+	}
+	return 100 - 100/(1+gain/loss)
+}
+
+// computeMACD returns MACD line, signal line and histogram
+func computeMACD(values []float64, fast int, slow int, signal int) ([]float64, []float64, []float64) {
+	fastEMA := computeEMA(values, fast)
+	slowEMA := computeEMA(values, slow)
+
+	macd := make([]float64, len(values))
+	validMACD := []float64{}
+	firstValid := -1
+	for idx := range values {
+		macd[idx] = fastEMA[idx] - slowEMA[idx]
+		if !math.IsNaN(macd[idx]) {
+			if firstValid < 0 {
+				firstValid = idx
+			}
+			validMACD = append(validMACD, macd[idx])
+		}
+	}
+
+	signalLine := make([]float64, len(values))
+	histogram := make([]float64, len(values))
+	for idx := range values {
+		signalLine[idx] = math.NaN()
+		histogram[idx] = math.NaN()
+	}
+
+	if firstValid >= 0 {
+		validSignal := computeEMA(validMACD, signal)
+		for i, v := range validSignal {
+			idx := firstValid + i
+			signalLine[idx] = v
+			histogram[idx] = macd[idx] - v
+		}
+	}
+	return macd, signalLine, histogram
+}
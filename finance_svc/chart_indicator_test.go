@@ -0,0 +1,227 @@
+package finance_svc
+
+import (
+	"math"
+	"testing"
+)
+
+const (
+	indicatorTestTolerance = 1e-9
+)
+
+var (
+	nan = math.NaN()
+)
+
+// assertSeries fails the test if the series differ, NaN equals NaN
+func assertSeries(t *testing.T, name string, expected []float64, actual []float64) {
+	t.Helper()
+
+	if len(expected) != len(actual) {
+		t.Fatalf("%s: expected %d values, got %d - %v", name, len(expected), len(actual), actual)
+	}
+
+	for idx := range expected {
+		if math.IsNaN(expected[idx]) && math.IsNaN(actual[idx]) {
+			continue
+		}
+
+		if math.IsNaN(expected[idx]) != math.IsNaN(actual[idx]) || math.Abs(expected[idx]-actual[idx]) > indicatorTestTolerance {
+			t.Fatalf("%s: expected %v at %d, got %v - %v", name, expected[idx], idx, actual[idx], actual)
+		}
+	}
+}
+
+func TestComputeSMA(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []float64
+		window   int
+		expected []float64
+	}{
+		{"empty", []float64{}, 3, []float64{}},
+		{"shorter than window", []float64{1, 2}, 3, []float64{nan, nan}},
+		{"rolling", []float64{1, 2, 3, 4, 5}, 3, []float64{nan, nan, 2, 3, 4}},
+		{"window of 1", []float64{3, 1, 2}, 1, []float64{3, 1, 2}},
+	}
+
+	for _, test := range tests {
+		assertSeries(t, test.name, test.expected, computeSMA(test.values, test.window))
+	}
+}
+
+func TestComputeEMA(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []float64
+		window   int
+		expected []float64
+	}{
+		{"shorter than window", []float64{1, 2}, 3, []float64{nan, nan}},
+		// alpha is 0.5, seeded with SMA 2
+		{"rolling", []float64{1, 2, 3, 4, 5}, 3, []float64{nan, nan, 2, 3, 4}},
+		{"jump", []float64{1, 1, 1, 5}, 3, []float64{nan, nan, 1, 3}},
+	}
+
+	for _, test := range tests {
+		assertSeries(t, test.name, test.expected, computeEMA(test.values, test.window))
+	}
+}
+
+func TestComputeBollinger(t *testing.T) {
+	tests := []struct {
+		name      string
+		values    []float64
+		window    int
+		deviation float64
+		middle    []float64
+		upper     []float64
+		lower     []float64
+	}{
+		{
+			name:      "flat",
+			values:    []float64{3, 3, 3},
+			window:    2,
+			deviation: bollingerDeviation,
+			middle:    []float64{nan, 3, 3},
+			upper:     []float64{nan, 3, 3},
+			lower:     []float64{nan, 3, 3},
+		},
+		{
+			// mean 5, population standard deviation 2
+			name:      "population deviation",
+			values:    []float64{2, 4, 4, 4, 5, 5, 7, 9},
+			window:    8,
+			deviation: bollingerDeviation,
+			middle:    []float64{nan, nan, nan, nan, nan, nan, nan, 5},
+			upper:     []float64{nan, nan, nan, nan, nan, nan, nan, 9},
+			lower:     []float64{nan, nan, nan, nan, nan, nan, nan, 1},
+		},
+		{
+			name:      "rolling",
+			values:    []float64{1, 3, 5},
+			window:    2,
+			deviation: 1,
+			middle:    []float64{nan, 2, 4},
+			upper:     []float64{nan, 3, 5},
+			lower:     []float64{nan, 1, 3},
+		},
+	}
+
+	for _, test := range tests {
+		middle, upper, lower := computeBollinger(test.values, test.window, test.deviation)
+		assertSeries(t, test.name+" middle", test.middle, middle)
+		assertSeries(t, test.name+" upper", test.upper, upper)
+		assertSeries(t, test.name+" lower", test.lower, lower)
+	}
+}
+
+func TestComputeRSI(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []float64
+		window   int
+		expected []float64
+	}{
+		{"not enough values", []float64{1, 2, 3}, 3, []float64{nan, nan, nan}},
+		{"only gains", []float64{1, 2, 3, 4}, 2, []float64{nan, nan, 100, 100}},
+		{"only losses", []float64{4, 3, 2, 1}, 2, []float64{nan, nan, 0, 0}},
+		{"flat", []float64{2, 2, 2, 2}, 2, []float64{nan, nan, 50, 50}},
+		// seeded with gain 0.5 and loss 0.5, then Wilder's smoothing gives gain 1.25 and loss 0.25
+		{"smoothed", []float64{1, 2, 1, 3}, 2, []float64{nan, nan, 50, 100 - 100.0/6}},
+	}
+
+	for _, test := range tests {
+		assertSeries(t, test.name, test.expected, computeRSI(test.values, test.window))
+	}
+}
+
+func TestComputeMACD(t *testing.T) {
+	// EMAs of a linear series lag it by (window - 1) / 2, so MACD of fast 2 and slow 4 is 1
+	linear := []float64{0, 1, 2, 3, 4, 5, 6}
+
+	tests := []struct {
+		name      string
+		values    []float64
+		fast      int
+		slow      int
+		signal    int
+		macd      []float64
+		signals   []float64
+		histogram []float64
+	}{
+		{
+			name:      "flat",
+			values:    []float64{5, 5, 5, 5, 5},
+			fast:      2,
+			slow:      3,
+			signal:    2,
+			macd:      []float64{nan, nan, 0, 0, 0},
+			signals:   []float64{nan, nan, nan, 0, 0},
+			histogram: []float64{nan, nan, nan, 0, 0},
+		},
+		{
+			name:      "linear",
+			values:    linear,
+			fast:      2,
+			slow:      4,
+			signal:    2,
+			macd:      []float64{nan, nan, nan, 1, 1, 1, 1},
+			signals:   []float64{nan, nan, nan, nan, 1, 1, 1},
+			histogram: []float64{nan, nan, nan, nan, 0, 0, 0},
+		},
+		{
+			name:      "shorter than slow window",
+			values:    []float64{1, 2},
+			fast:      macdFastWindow,
+			slow:      macdSlowWindow,
+			signal:    macdSignalWindow,
+			macd:      []float64{nan, nan},
+			signals:   []float64{nan, nan},
+			histogram: []float64{nan, nan},
+		},
+	}
+
+	for _, test := range tests {
+		macd, signals, histogram := computeMACD(test.values, test.fast, test.slow, test.signal)
+		assertSeries(t, test.name+" macd", test.macd, macd)
+		assertSeries(t, test.name+" signal", test.signals, signals)
+		assertSeries(t, test.name+" histogram", test.histogram, histogram)
+	}
+}
+
+func TestParseIndicatorWindows(t *testing.T) {
+	tests := []struct {
+		windows  string
+		expected []int
+		valid    bool
+	}{
+		{"", []int{}, true},
+		{"20", []int{20}, true},
+		{"50, 20,20", []int{20, 50}, true},
+		{"1", nil, false},
+		{"501", nil, false},
+		{"20,abc", nil, false},
+	}
+
+	for _, test := range tests {
+		windows, err := ParseIndicatorWindows(test.windows)
+		if (err == nil) != test.valid {
+			t.Fatalf("%q: expected valid %v, got error %v", test.windows, test.valid, err)
+		}
+
+		if !test.valid {
+			continue
+		}
+
+		if len(windows) != len(test.expected) {
+			t.Fatalf("%q: expected %v, got %v", test.windows, test.expected, windows)
+		}
+
+		for idx := range windows {
+			if windows[idx] != test.expected[idx] {
+				t.Fatalf("%q: expected %v, got %v", test.windows, test.expected, windows)
+			}
+		}
+	}
+}
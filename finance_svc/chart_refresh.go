@@ -0,0 +1,137 @@
+package finance_svc
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	stockChartRefreshTick = 1 * time.Minute // 1 min
+	// bars of a session are final a while after the close
+	stockChartCloseSettleTime = 15 * time.Minute // 15 min
+	// clients revalidate daily or longer charts at least this often
+	stockChartMaxAge = 1 * time.Hour // 1 hour
+	// failed renders are retried after this, doubled for each failure in a row
+	stockChartFailureBackoff    = 2 * time.Minute // 2 min
+	stockChartMaxFailureBackoff = 1 * time.Hour   // 1 hour
+)
+
+// isChartStale decides whether a chart needs a new render.
+// Intraday charts are stale every IntradayRefresh while their market trades, including
+// extended hours of the US market, and once after the close. Daily or longer charts are stale once after each close.
+// Charts failing to render are not stale again until their backoff passes, e.g., of a delisted symbol.
+func (svc *ChartSVC) isChartStale(chartData *StockChartData, now time.Time) bool {
+	if chartData.FailureCount > 0 && now.Before(chartData.LastFailureTime.Add(getChartFailureBackoff(chartData.FailureCount))) {
+		return false
+	}
+
+	if chartData.LastRenderTime.IsZero() {
+		return true
+	}
+
+	symbols := append([]string{chartData.StockSymbol}, chartData.Options.CompareSymbols...)
+	for _, symbol := range symbols {
+		session := svc.TimeService.GetMarketSession(symbol)
+
+		if isIntradayInterval(chartData.Interval) && svc.isIntradayTrading(symbol, session, now) {
+			if now.Sub(chartData.LastRenderTime) >= svc.getIntradayRefresh() {
+				return true
+			}
+			continue
+		}
+
+		// render once after bars of the last session are settled
+		settledTime := session.LastClose(now.Add(-stockChartCloseSettleTime)).Add(stockChartCloseSettleTime)
+		if chartData.LastRenderTime.Before(settledTime) {
+			return true
+		}
+	}
+	return false
+}
+
+// getChartFailureBackoff returns how long to wait before retrying a chart failed in a row failureCount times
+func getChartFailureBackoff(failureCount int) time.Duration {
+	backoff := stockChartFailureBackoff
+	for i := 1; i < failureCount; i++ {
+		backoff *= 2
+		if backoff >= stockChartMaxFailureBackoff {
+			return stockChartMaxFailureBackoff
+		}
+	}
+	return backoff
+}
+
+// getChartMaxAge returns how long clients may cache a chart before it may be renewed.
+// Intraday charts live for IntradayRefresh, daily or longer charts until the next
+// close is settled, at most stockChartMaxAge.
+func (svc *ChartSVC) getChartMaxAge(chartData *StockChartData, now time.Time) time.Duration {
+	if isIntradayInterval(chartData.Interval) {
+		return svc.getIntradayRefresh()
+	}
+
+	maxAge := stockChartMaxAge
+	symbols := append([]string{chartData.StockSymbol}, chartData.Options.CompareSymbols...)
+	for _, symbol := range symbols {
+		session := svc.TimeService.GetMarketSession(symbol)
+
+		settledTime := session.NextClose(now.Add(-stockChartCloseSettleTime)).Add(stockChartCloseSettleTime)
+		if untilSettled := settledTime.Sub(now); untilSettled < maxAge {
+			maxAge = untilSettled
+		}
+	}
+
+	if maxAge < 0 {
+		return 0
+	}
+	return maxAge
+}
+
+// isIntradayTrading returns true if intraday bars of the symbol may change at now
+func (svc *ChartSVC) isIntradayTrading(symbol string, session *MarketSession, now time.Time) bool {
+	if session.IsOpen(now) {
+		return true
+	}
+
+	if !hasExtendedSessions(svc.TimeService, symbol, ChartInteval1Min) {
+		return false
+	}
+
+	// extended hours are of the US market, closed on weekends and holidays
+	if !svc.TimeService.DefaultMarketSession.IsTradingDay(now) {
+		return false
+	}
+	return svc.TimeService.GetMarketType(now) != Overnight
+}
+
+func (svc *ChartSVC) getIntradayRefresh() time.Duration {
+	if svc.Config.IntradayRefresh > 0 {
+		return svc.Config.IntradayRefresh
+	}
+	return defaultChartIntradayRefresh
+}
+
+// renewStaleCharts queues tracked charts that are stale
+func (svc *ChartSVC) renewStaleCharts() {
+	logger := log.WithFields(log.Fields{
+		"package":  "ChartSVC",
+		"function": "renewStaleCharts",
+	})
+
+	now := time.Now()
+	queued := 0
+	for key, item := range svc.Charts.Items() {
+		chartData := item.Object.(*StockChartData)
+		if !svc.isChartStale(chartData, now) {
+			continue
+		}
+
+		if svc.RenewalScheduler.Enqueue(key, chartData) {
+			queued++
+		}
+	}
+
+	if queued > 0 {
+		logger.Infof("Queued %d charts for renewal, %d pending", queued, len(svc.GetPendingRenewals()))
+	}
+}
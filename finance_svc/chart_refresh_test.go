@@ -0,0 +1,188 @@
+package finance_svc
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	cache "github.com/patrickmn/go-cache"
+)
+
+func newRefreshTestChartSVC(t *testing.T) *ChartSVC {
+	timeService, err := InitTimeSVC()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &ChartSVC{
+		Config:      DefaultChartSVCConfig(),
+		TimeService: timeService,
+	}
+}
+
+func TestIsChartStale(t *testing.T) {
+	svc := newRefreshTestChartSVC(t)
+
+	// Thu, Mar 5, 2020 and the following days in New York
+	newyork := func(date int, hour int, min int) time.Time {
+		return time.Date(2020, 3, date, hour, min, 0, 0, svc.TimeService.NewYorkLocation)
+	}
+	utc := func(date int, hour int, min int) time.Time {
+		return time.Date(2020, 3, date, hour, min, 0, 0, time.UTC)
+	}
+	april := func(date int, hour int, min int) time.Time {
+		return time.Date(2020, 4, date, hour, min, 0, 0, svc.TimeService.NewYorkLocation)
+	}
+
+	tests := []struct {
+		name       string
+		symbol     string
+		interval   ChartInterval
+		compare    []string
+		renderTime time.Time
+		now        time.Time
+		expected   bool
+	}{
+		{"never rendered", "AAPL", ChartInteval1Day, nil, time.Time{}, newyork(5, 12, 0), true},
+		// daily charts are stale once bars of the close are settled
+		{"daily, rendered after settled close", "AAPL", ChartInteval1Day, nil, newyork(5, 16, 20), newyork(5, 20, 0), false},
+		{"daily, close settled after render", "AAPL", ChartInteval1Day, nil, newyork(5, 16, 10), newyork(5, 16, 20), true},
+		{"daily, close not settled yet", "AAPL", ChartInteval1Day, nil, newyork(5, 16, 10), newyork(5, 16, 14), false},
+		{"daily, weekend", "AAPL", ChartInteval1Day, nil, newyork(6, 16, 20), newyork(7, 12, 0), false},
+		{"daily, monday close", "AAPL", ChartInteval1Day, nil, newyork(6, 16, 20), newyork(9, 16, 20), true},
+		// intraday charts are stale every IntradayRefresh while the market trades
+		{"intraday, market hours, fresh", "AAPL", ChartInteval5Min, nil, newyork(5, 9, 56), newyork(5, 10, 0), false},
+		{"intraday, market hours, old", "AAPL", ChartInteval5Min, nil, newyork(5, 9, 54), newyork(5, 10, 0), true},
+		{"intraday, pre-market", "AAPL", ChartInteval5Min, nil, newyork(5, 7, 50), newyork(5, 8, 0), true},
+		{"intraday, overnight after the close", "AAPL", ChartInteval5Min, nil, newyork(5, 16, 10), newyork(5, 22, 0), true},
+		{"intraday, overnight", "AAPL", ChartInteval5Min, nil, newyork(5, 17, 5), newyork(5, 22, 0), false},
+		{"intraday, weekend", "AAPL", ChartInteval5Min, nil, newyork(6, 20, 0), newyork(7, 10, 0), false},
+		// NYSE is closed on Good Friday, Apr 10, 2020
+		{"daily, holiday", "AAPL", ChartInteval1Day, nil, april(9, 16, 20), april(10, 16, 20), false},
+		{"daily, close after holiday", "AAPL", ChartInteval1Day, nil, april(9, 16, 20), april(13, 16, 20), true},
+		{"intraday, holiday", "AAPL", ChartInteval5Min, nil, april(9, 20, 0), april(10, 10, 0), false},
+		{"intraday, pre-market on holiday", "AAPL", ChartInteval5Min, nil, april(9, 20, 0), april(10, 8, 0), false},
+		// crypto closes at the end of each UTC day
+		{"crypto, day settled", "BTC-USD", ChartInteval1Day, nil, utc(5, 23, 0), utc(6, 0, 20), true},
+		{"crypto, day not settled", "BTC-USD", ChartInteval1Day, nil, utc(5, 23, 0), utc(6, 0, 10), false},
+		{"crypto, intraday", "BTC-USD", ChartInteval5Min, nil, utc(7, 11, 54), utc(7, 12, 0), true},
+		// compared symbols of other markets are stale at their own close
+		{"compare, KRX close settled", "AAPL", ChartInteval1Day, []string{"005930.KS"}, newyork(5, 16, 20), newyork(6, 2, 0), true},
+		{"compare, KRX close not settled", "AAPL", ChartInteval1Day, []string{"005930.KS"}, newyork(5, 16, 20), newyork(6, 1, 40), false},
+	}
+
+	for _, test := range tests {
+		chartData := &StockChartData{
+			StockSymbol: test.symbol,
+			Period:      ChartPeriod1Month,
+			Interval:    test.interval,
+			Options: ChartOptions{
+				CompareSymbols: test.compare,
+			},
+			LastRenderTime: test.renderTime,
+		}
+
+		stale := svc.isChartStale(chartData, test.now)
+		if stale != test.expected {
+			t.Fatalf("%s: expected stale %v, got %v", test.name, test.expected, stale)
+		}
+	}
+}
+
+func TestIsChartStaleAfterFailures(t *testing.T) {
+	svc := newRefreshTestChartSVC(t)
+
+	// Thu, Mar 5, 2020 in New York, charts rendered last before the close are stale
+	now := time.Date(2020, 3, 5, 20, 0, 0, 0, svc.TimeService.NewYorkLocation)
+	renderTime := time.Date(2020, 3, 5, 12, 0, 0, 0, svc.TimeService.NewYorkLocation)
+
+	tests := []struct {
+		name         string
+		renderTime   time.Time
+		failureCount int
+		failedAgo    time.Duration
+		expected     bool
+	}{
+		{"no failure", renderTime, 0, 0, true},
+		{"first failure, backing off", renderTime, 1, time.Minute, false},
+		{"first failure, backoff passed", renderTime, 1, 3 * time.Minute, true},
+		{"never rendered, backing off", time.Time{}, 1, time.Minute, false},
+		{"never rendered, backoff passed", time.Time{}, 1, 3 * time.Minute, true},
+		// doubled for each failure in a row, 16 min after 4 failures
+		{"failures in a row, backing off", renderTime, 4, 10 * time.Minute, false},
+		{"failures in a row, backoff passed", renderTime, 4, 17 * time.Minute, true},
+		{"many failures, backing off", renderTime, 20, 59 * time.Minute, false},
+		{"many failures, backoff passed", renderTime, 20, 61 * time.Minute, true},
+	}
+
+	for _, test := range tests {
+		chartData := &StockChartData{
+			StockSymbol:     "AAPL",
+			Period:          ChartPeriod1Month,
+			Interval:        ChartInteval1Day,
+			LastRenderTime:  test.renderTime,
+			FailureCount:    test.failureCount,
+			LastFailureTime: now.Add(-test.failedAgo),
+		}
+
+		stale := svc.isChartStale(chartData, now)
+		if stale != test.expected {
+			t.Fatalf("%s: expected stale %v, got %v", test.name, test.expected, stale)
+		}
+	}
+}
+
+func TestRecordChartFailures(t *testing.T) {
+	svc := newRefreshTestChartSVC(t)
+	svc.Charts = cache.New(cache.NoExpiration, cache.NoExpiration)
+
+	options := ChartOptions{}
+	key := svc.makeChartFileName("AAPL", ChartPeriod1Month, ChartInteval1Day, options)
+	svc.renewChartCache("AAPL", ChartPeriod1Month, ChartInteval1Day, options)
+
+	getChart := func() *StockChartData {
+		chartData, ok := svc.getChartCache("AAPL", ChartPeriod1Month, ChartInteval1Day, options)
+		if !ok {
+			t.Fatal("chart is not tracked")
+		}
+		return chartData
+	}
+
+	renderErr := errors.New("no data")
+	svc.updateChartRenderStats(key, time.Second, renderErr)
+	svc.updateChartRenderStats(key, time.Second, renderErr)
+	if getChart().FailureCount != 2 || getChart().LastFailureTime.IsZero() {
+		t.Fatalf("expected 2 failures, got %+v", getChart())
+	}
+
+	// requests renew the cache entry without resetting failures
+	svc.renewChartCache("AAPL", ChartPeriod1Month, ChartInteval1Day, options)
+	if getChart().FailureCount != 2 {
+		t.Fatalf("expected 2 failures after renewing cache, got %+v", getChart())
+	}
+
+	svc.updateChartRenderStats(key, time.Second, nil)
+	if getChart().FailureCount != 0 || getChart().LastRenderTime.IsZero() {
+		t.Fatalf("expected failures reset by a render, got %+v", getChart())
+	}
+}
+
+func TestGetChartFailureBackoff(t *testing.T) {
+	tests := []struct {
+		failureCount int
+		expected     time.Duration
+	}{
+		{1, 2 * time.Minute},
+		{2, 4 * time.Minute},
+		{5, 32 * time.Minute},
+		{6, stockChartMaxFailureBackoff},
+		{100, stockChartMaxFailureBackoff},
+	}
+
+	for _, test := range tests {
+		backoff := getChartFailureBackoff(test.failureCount)
+		if backoff != test.expected {
+			t.Fatalf("%d failures: expected %s, got %s", test.failureCount, test.expected, backoff)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package finance_svc
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	stockChartRegistryFile     = stockChartFileDir + "/registry.json"
+	stockChartRegistrySaveTick = 1 * time.Minute // 1 min
+)
+
+// chartRegistryEntry is a tracked chart saved in the registry file
+type chartRegistryEntry struct {
+	Key        string
+	Chart      StockChartData
+	Expiration time.Time
+}
+
+// saveChartRegistryIfDirty saves the registry only when tracked charts have changed
+func (svc *ChartSVC) saveChartRegistryIfDirty() error {
+	svc.ChartsLock.Lock()
+	dirty := svc.RegistryDirty
+	svc.ChartsLock.Unlock()
+
+	if !dirty {
+		return nil
+	}
+	return svc.saveChartRegistry()
+}
+
+// saveChartRegistry writes tracked charts to the registry file
+func (svc *ChartSVC) saveChartRegistry() error {
+	logger := log.WithFields(log.Fields{
+		"package":  "ChartSVC",
+		"function": "saveChartRegistry",
+	})
+
+	svc.ChartsLock.Lock()
+	entries := []chartRegistryEntry{}
+	for key, item := range svc.Charts.Items() {
+		entries = append(entries, chartRegistryEntry{
+			Key:        key,
+			Chart:      *item.Object.(*StockChartData),
+			Expiration: time.Unix(0, item.Expiration),
+		})
+	}
+	svc.RegistryDirty = false
+	svc.ChartsLock.Unlock()
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	err = os.MkdirAll(stockChartFileDir, 0766)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	tempFile, err := ioutil.TempFile(stockChartFileDir, stockChartTempFilePrefix+"*_registry.json")
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+	defer os.Remove(tempFile.Name())
+
+	_, err = tempFile.Write(data)
+	if err != nil {
+		tempFile.Close()
+		logger.Error(err)
+		return err
+	}
+
+	err = tempFile.Close()
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	err = os.Rename(tempFile.Name(), stockChartRegistryFile)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	logger.Infof("Saved %d charts to registry", len(entries))
+	return nil
+}
+
+// loadChartRegistry restores tracked charts from the registry file.
+// Charts that are expired or have no chart file are dropped.
+func (svc *ChartSVC) loadChartRegistry() ([]*StockChartData, error) {
+	logger := log.WithFields(log.Fields{
+		"package":  "ChartSVC",
+		"function": "loadChartRegistry",
+	})
+
+	loaded := []*StockChartData{}
+
+	data, err := ioutil.ReadFile(stockChartRegistryFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return loaded, nil
+		}
+		logger.Error(err)
+		return nil, err
+	}
+
+	entries := []chartRegistryEntry{}
+	err = json.Unmarshal(data, &entries)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	now := time.Now()
+
+	svc.ChartsLock.Lock()
+	defer svc.ChartsLock.Unlock()
+
+	for _, entry := range entries {
+		if !entry.Expiration.After(now) {
+			continue
+		}
+
+		if _, err := os.Stat(entry.Chart.LocalFilePath); err != nil {
+			logger.Infof("Dropping chart %s from registry - %v", entry.Key, err)
+			continue
+		}
+
+		chartData := entry.Chart
+		svc.Charts.Set(entry.Key, &chartData, entry.Expiration.Sub(now))
+		loaded = append(loaded, &chartData)
+	}
+
+	return loaded, nil
+}
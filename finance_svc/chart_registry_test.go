@@ -0,0 +1,185 @@
+package finance_svc
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	cache "github.com/patrickmn/go-cache"
+)
+
+func TestChartRegistryRoundTrip(t *testing.T) {
+	workDir, err := ioutil.TempDir("", "chart_registry_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(workDir)
+
+	// the registry is stored relative to the working dir
+	prevDir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.Chdir(workDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(prevDir)
+
+	err = os.MkdirAll(stockChartFileDir, 0766)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	renderTime := time.Date(2020, 3, 5, 21, 20, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		chart      StockChartData
+		hasFile    bool
+		expiration time.Duration
+		loaded     bool
+	}{
+		{
+			name: "tracked",
+			chart: StockChartData{
+				StockSymbol:    "AAPL",
+				Period:         ChartPeriod1Month,
+				Interval:       ChartInteval1Day,
+				Options:        ChartOptions{Style: ChartStyleCandlestick, Volume: true, Format: ChartFormatSVG, CompareSymbols: []string{"MSFT", "^GSPC"}},
+				LastRenderTime: renderTime,
+			},
+			hasFile:    true,
+			expiration: time.Hour,
+			loaded:     true,
+		},
+		{
+			name: "intraday",
+			chart: StockChartData{
+				StockSymbol:    "MSFT",
+				Period:         ChartPeriod1Day,
+				Interval:       ChartInteval5Min,
+				Options:        DefaultChartOptions(),
+				LastRenderTime: renderTime,
+			},
+			hasFile:    true,
+			expiration: time.Hour,
+			loaded:     true,
+		},
+		// charts whose file is gone are rendered again on request
+		{
+			name: "no chart file",
+			chart: StockChartData{
+				StockSymbol: "TSLA",
+				Period:      ChartPeriod1Month,
+				Interval:    ChartInteval1Day,
+				Options:     DefaultChartOptions(),
+			},
+			hasFile:    false,
+			expiration: time.Hour,
+			loaded:     false,
+		},
+		{
+			name: "expired",
+			chart: StockChartData{
+				StockSymbol: "NFLX",
+				Period:      ChartPeriod1Month,
+				Interval:    ChartInteval1Day,
+				Options:     DefaultChartOptions(),
+			},
+			hasFile:    true,
+			expiration: 50 * time.Millisecond,
+			loaded:     false,
+		},
+	}
+
+	svc := &ChartSVC{
+		Charts: cache.New(cache.NoExpiration, cache.NoExpiration),
+	}
+
+	for _, test := range tests {
+		chart := test.chart
+		chart.LocalFilePath = filepath.Join(stockChartFileDir, test.chart.StockSymbol+".png")
+		if test.hasFile {
+			err = ioutil.WriteFile(chart.LocalFilePath, []byte("chart"), 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		svc.Charts.Set(test.name, &chart, test.expiration)
+	}
+
+	err = svc.saveChartRegistry()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// let short-lived charts expire before restoring
+	time.Sleep(100 * time.Millisecond)
+
+	restoredSvc := &ChartSVC{
+		Charts: cache.New(cache.NoExpiration, cache.NoExpiration),
+	}
+
+	loaded, err := restoredSvc.loadChartRegistry()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loadedCount := 0
+	for _, test := range tests {
+		item, ok := restoredSvc.Charts.Get(test.name)
+		if ok != test.loaded {
+			t.Fatalf("%s: expected loaded %v, got %v", test.name, test.loaded, ok)
+		}
+
+		if !test.loaded {
+			continue
+		}
+		loadedCount++
+
+		expected := test.chart
+		expected.LocalFilePath = filepath.Join(stockChartFileDir, test.chart.StockSymbol+".png")
+		if !reflect.DeepEqual(*item.(*StockChartData), expected) {
+			t.Fatalf("%s: expected %+v, got %+v", test.name, expected, *item.(*StockChartData))
+		}
+	}
+
+	if len(loaded) != loadedCount {
+		t.Fatalf("expected %d charts loaded, got %d", loadedCount, len(loaded))
+	}
+}
+
+func TestLoadChartRegistryMissing(t *testing.T) {
+	workDir, err := ioutil.TempDir("", "chart_registry_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(workDir)
+
+	prevDir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.Chdir(workDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(prevDir)
+
+	svc := &ChartSVC{
+		Charts: cache.New(cache.NoExpiration, cache.NoExpiration),
+	}
+
+	// no registry on the first start
+	loaded, err := svc.loadChartRegistry()
+	if err != nil || len(loaded) != 0 {
+		t.Fatalf("expected no charts, got %d charts and error %v", len(loaded), err)
+	}
+}
@@ -0,0 +1,112 @@
+package finance_svc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ChartTimeoutError is an error of a chart render exceeding its deadline
+type ChartTimeoutError struct {
+	Key     string
+	Timeout time.Duration
+}
+
+// Error ...
+func (err *ChartTimeoutError) Error() string {
+	return fmt.Sprintf("rendering chart %s timed out after %s", err.Key, err.Timeout)
+}
+
+// IsChartTimeoutError returns true if the error is caused by a render deadline
+func IsChartTimeoutError(err error) bool {
+	var timeoutErr *ChartTimeoutError
+	return errors.As(err, &timeoutErr)
+}
+
+// chartRenderCall is a render in progress shared by callers requesting the same chart
+type chartRenderCall struct {
+	Done    chan struct{}
+	Err     error
+	Waiters int
+	Cancel  context.CancelFunc
+}
+
+// chartRenderGroup coalesces concurrent renders of the same chart. A render runs
+// under the group context with a deadline, and is cancelled when all callers give up.
+type chartRenderGroup struct {
+	Context context.Context
+	Timeout time.Duration
+
+	Calls     map[string]*chartRenderCall
+	CallsLock sync.Mutex
+}
+
+func newChartRenderGroup(ctx context.Context, timeout time.Duration) *chartRenderGroup {
+	return &chartRenderGroup{
+		Context: ctx,
+		Timeout: timeout,
+		Calls:   map[string]*chartRenderCall{},
+	}
+}
+
+// Do runs render for the key unless a render of the key is in progress, and waits for
+// its result or cancellation of ctx
+func (group *chartRenderGroup) Do(ctx context.Context, key string, render func(ctx context.Context) error) error {
+	group.CallsLock.Lock()
+	call, ok := group.Calls[key]
+	if ok {
+		call.Waiters++
+	} else {
+		renderCtx, cancel := group.newRenderContext()
+		call = &chartRenderCall{
+			Done:    make(chan struct{}),
+			Waiters: 1,
+			Cancel:  cancel,
+		}
+		group.Calls[key] = call
+
+		go group.run(renderCtx, key, call, render)
+	}
+	group.CallsLock.Unlock()
+
+	select {
+	case <-call.Done:
+		return call.Err
+	case <-ctx.Done():
+		group.CallsLock.Lock()
+		call.Waiters--
+		if call.Waiters == 0 {
+			// nobody waits for the chart anymore
+			call.Cancel()
+		}
+		group.CallsLock.Unlock()
+		return ctx.Err()
+	}
+}
+
+func (group *chartRenderGroup) newRenderContext() (context.Context, context.CancelFunc) {
+	if group.Timeout > 0 {
+		return context.WithTimeout(group.Context, group.Timeout)
+	}
+	return context.WithCancel(group.Context)
+}
+
+func (group *chartRenderGroup) run(ctx context.Context, key string, call *chartRenderCall, render func(ctx context.Context) error) {
+	err := render(ctx)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = &ChartTimeoutError{
+			Key:     key,
+			Timeout: group.Timeout,
+		}
+	}
+
+	group.CallsLock.Lock()
+	delete(group.Calls, key)
+	call.Err = err
+	group.CallsLock.Unlock()
+
+	call.Cancel()
+	close(call.Done)
+}
@@ -0,0 +1,167 @@
+package finance_svc
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestChartRenderGroupCoalesce(t *testing.T) {
+	tests := []struct {
+		name            string
+		keys            []string
+		expectedRenders int32
+	}{
+		{"same chart", []string{"AAPL", "AAPL", "AAPL"}, 1},
+		{"different charts", []string{"AAPL", "MSFT"}, 2},
+		{"mixed", []string{"AAPL", "MSFT", "AAPL", "MSFT"}, 2},
+	}
+
+	for _, test := range tests {
+		group := newChartRenderGroup(context.Background(), 0)
+
+		var renders int32
+		release := make(chan struct{})
+		render := func(ctx context.Context) error {
+			atomic.AddInt32(&renders, 1)
+			<-release
+			return nil
+		}
+
+		wg := sync.WaitGroup{}
+		errs := make(chan error, len(test.keys))
+		for _, key := range test.keys {
+			wg.Add(1)
+			go func(key string) {
+				defer wg.Done()
+				errs <- group.Do(context.Background(), key, render)
+			}(key)
+		}
+
+		// renders are held until all callers wait
+		waitForChartRenderWaiters(t, group, len(test.keys))
+		close(release)
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			if err != nil {
+				t.Fatalf("%s: unexpected error %v", test.name, err)
+			}
+		}
+
+		if renders != test.expectedRenders {
+			t.Fatalf("%s: expected %d renders, got %d", test.name, test.expectedRenders, renders)
+		}
+	}
+}
+
+func TestChartRenderGroupTimeout(t *testing.T) {
+	group := newChartRenderGroup(context.Background(), 50*time.Millisecond)
+
+	tests := []struct {
+		name    string
+		render  func(ctx context.Context) error
+		timeout bool
+	}{
+		{"fast", func(ctx context.Context) error { return nil }, false},
+		{"failed", func(ctx context.Context) error { return errors.New("no data") }, false},
+		{"slow", func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}, true},
+	}
+
+	for _, test := range tests {
+		err := group.Do(context.Background(), test.name, test.render)
+		if IsChartTimeoutError(err) != test.timeout {
+			t.Fatalf("%s: expected timeout %v, got error %v", test.name, test.timeout, err)
+		}
+	}
+}
+
+func TestChartRenderGroupCancel(t *testing.T) {
+	tests := []struct {
+		name      string
+		waiters   int
+		cancelled int
+		expected  bool
+	}{
+		{"a waiter leaves", 2, 1, false},
+		{"all waiters leave", 2, 2, true},
+		{"the only waiter leaves", 1, 1, true},
+	}
+
+	for _, test := range tests {
+		group := newChartRenderGroup(context.Background(), 0)
+
+		renderCancelled := make(chan struct{})
+		release := make(chan struct{})
+		render := func(ctx context.Context) error {
+			select {
+			case <-ctx.Done():
+				close(renderCancelled)
+				return ctx.Err()
+			case <-release:
+				return nil
+			}
+		}
+
+		cancels := []context.CancelFunc{}
+		wg := sync.WaitGroup{}
+		for i := 0; i < test.waiters; i++ {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancels = append(cancels, cancel)
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				group.Do(ctx, "AAPL", render)
+			}()
+		}
+
+		waitForChartRenderWaiters(t, group, test.waiters)
+		for i := 0; i < test.cancelled; i++ {
+			cancels[i]()
+		}
+
+		select {
+		case <-renderCancelled:
+			if !test.expected {
+				t.Fatalf("%s: render is cancelled while a caller waits", test.name)
+			}
+		case <-time.After(100 * time.Millisecond):
+			if test.expected {
+				t.Fatalf("%s: render is not cancelled", test.name)
+			}
+		}
+
+		close(release)
+		for _, cancel := range cancels {
+			cancel()
+		}
+		wg.Wait()
+	}
+}
+
+// waitForChartRenderWaiters waits until renders in progress have the number of callers in total
+func waitForChartRenderWaiters(t *testing.T, group *chartRenderGroup, waiters int) {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		group.CallsLock.Lock()
+		total := 0
+		for _, call := range group.Calls {
+			total += call.Waiters
+		}
+		group.CallsLock.Unlock()
+
+		if total == waiters {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("expected %d callers waiting for renders", waiters)
+}
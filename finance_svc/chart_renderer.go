@@ -0,0 +1,40 @@
+package finance_svc
+
+import (
+	"context"
+	"fmt"
+)
+
+// ChartRendererType is a kind of chart renderer
+type ChartRendererType string
+
+const (
+	// ChartRendererGo draws charts in pure go
+	ChartRendererGo ChartRendererType = "go"
+	// ChartRendererPython draws charts by executing stock_chart.py
+	ChartRendererPython ChartRendererType = "python"
+)
+
+// ChartRenderer draws a chart image file of a stock
+type ChartRenderer interface {
+	// GetType returns type of the renderer
+	GetType() ChartRendererType
+	// Render draws a chart described by chartData and writes it to filepath,
+	// it stops when ctx is cancelled
+	Render(ctx context.Context, chartData *StockChartData, filepath string) error
+}
+
+// NewChartRenderer creates a chart renderer of the given type.
+// Renderers shade market sessions of timeService on intraday charts.
+// The go renderer, which also draws sparklines of the python renderer, reads price
+// bars from historyService if given.
+func NewChartRenderer(rendererType ChartRendererType, timeService *TimeSVC, historyService *HistorySVC) (ChartRenderer, error) {
+	switch rendererType {
+	case ChartRendererGo, "":
+		return NewGoChartRenderer(timeService, historyService), nil
+	case ChartRendererPython:
+		return NewPythonChartRenderer(stockChartBin, timeService, NewGoChartRenderer(timeService, historyService)), nil
+	default:
+		return nil, fmt.Errorf("unknown chart renderer - %s", rendererType)
+	}
+}
@@ -0,0 +1,140 @@
+package finance_svc
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+)
+
+// alignCompareSeries returns percent changes of each data from the first bar time of the first
+// data at which all symbols have a price, sampled at bar times of the first data. A symbol
+// without a bar at a time takes its latest price before the time.
+func alignCompareSeries(compareData []*OHLCVData) [][]float64 {
+	base := compareData[0]
+
+	prices := make([][]float64, 0, len(compareData))
+	for _, data := range compareData {
+		values := make([]float64, len(base.Bars))
+		last := math.NaN()
+
+		barIdx := 0
+		for idx, baseBar := range base.Bars {
+			for barIdx < len(data.Bars) && !data.Bars[barIdx].Time.After(baseBar.Time) {
+				if data.Bars[barIdx].AdjClose != 0 {
+					last = data.Bars[barIdx].AdjClose
+				}
+				barIdx++
+			}
+			values[idx] = last
+		}
+
+		prices = append(prices, values)
+	}
+
+	// the first bar at which all symbols have a price
+	firstIdx := len(base.Bars)
+	for idx := range base.Bars {
+		common := true
+		for _, values := range prices {
+			if math.IsNaN(values[idx]) {
+				common = false
+				break
+			}
+		}
+
+		if common {
+			firstIdx = idx
+			break
+		}
+	}
+
+	result := make([][]float64, 0, len(compareData))
+	for _, values := range prices {
+		changes := make([]float64, len(values))
+		for idx, v := range values {
+			if idx < firstIdx {
+				changes[idx] = math.NaN()
+			} else {
+				changes[idx] = (v/values[firstIdx] - 1) * 100
+			}
+		}
+
+		result = append(result, changes)
+	}
+	return result
+}
+
+// drawCompareChart draws percent changes of multiple symbols over the period of the first symbol
+func (renderer *GoChartRenderer) drawCompareChart(canvas chartCanvas, theme *chartTheme, compareData []*OHLCVData) {
+	width, height := canvas.Size()
+	base := compareData[0]
+
+	seriesValues := alignCompareSeries(compareData)
+
+	minValue, maxValue := 0.0, 0.0
+	for _, values := range seriesValues {
+		for _, v := range values {
+			if !math.IsNaN(v) {
+				minValue = math.Min(minValue, v)
+				maxValue = math.Max(maxValue, v)
+			}
+		}
+	}
+
+	ticks := niceTicks(minValue, maxValue, goChartYTicks)
+	if len(ticks) > 0 {
+		minValue = math.Min(minValue, ticks[0])
+		maxValue = math.Max(maxValue, ticks[len(ticks)-1])
+	}
+
+	pane := &chartPane{
+		Left:     goChartMarginLeft,
+		Top:      goChartMarginTop,
+		Width:    width - goChartMarginLeft - goChartMarginRight,
+		Height:   height - goChartMarginTop - goChartMarginBottom,
+		BarCount: len(base.Bars),
+		MinValue: minValue,
+		MaxValue: maxValue,
+	}
+
+	renderer.drawYAxis(canvas, theme, pane, ticks, "%")
+
+	zero := pane.Y(0)
+	canvas.StrokeLine([]chartPoint{{X: pane.Left, Y: zero}, {X: pane.Left + pane.Width, Y: zero}}, 1, theme.Axis)
+
+	series := make([]chartSeries, 0, len(compareData))
+	for idx, data := range compareData {
+		values := seriesValues[idx]
+
+		name := data.Symbol
+		for i := len(values) - 1; i >= 0; i-- {
+			if !math.IsNaN(values[i]) {
+				name = fmt.Sprintf("%s %+.2f%%", data.Symbol, values[i])
+				break
+			}
+		}
+
+		var lineColor color.Color = theme.Line
+		if idx > 0 {
+			lineColor = goChartOverlayColors[(idx-1)%len(goChartOverlayColors)]
+		}
+
+		series = append(series, chartSeries{
+			Name:   name,
+			Values: values,
+			Color:  lineColor,
+		})
+	}
+
+	// draw the first symbol last to keep it on top
+	for idx := len(series) - 1; idx >= 0; idx-- {
+		renderer.drawSeries(canvas, pane, series[idx])
+	}
+
+	renderer.drawLegend(canvas, theme, pane, series)
+	renderer.drawXAxis(canvas, theme, []*chartPane{pane}, base)
+	renderer.drawFrame(canvas, theme, pane)
+
+	canvas.DrawText(pane.Left, pane.Top-10, "Change", textAnchorStart, theme.Text)
+	canvas.DrawText(pane.Left+pane.Width/2, height-8, fmt.Sprintf("Time %s", base.Period), textAnchorMiddle, theme.Text)
+}
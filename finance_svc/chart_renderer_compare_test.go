@@ -0,0 +1,108 @@
+package finance_svc
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestAlignCompareSeries(t *testing.T) {
+	seoulLocation, err := time.LoadLocation("Asia/Seoul")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newyorkLocation, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// daily bars at the open of each market in January 2020, KRX opens before NYSE on the same date
+	nyse := func(date int) time.Time {
+		return time.Date(2020, 1, date, 9, 30, 0, 0, newyorkLocation)
+	}
+	krx := func(date int) time.Time {
+		return time.Date(2020, 1, date, 9, 0, 0, 0, seoulLocation)
+	}
+	data := func(symbol string, times []time.Time, closes []float64) *OHLCVData {
+		bars := []OHLCV{}
+		for idx, barTime := range times {
+			bars = append(bars, OHLCV{Time: barTime, Close: closes[idx], AdjClose: closes[idx]})
+		}
+		return &OHLCVData{Symbol: symbol, Interval: ChartInteval1Day, Bars: bars}
+	}
+
+	nan := math.NaN()
+	spy := data("SPY", []time.Time{nyse(2), nyse(3), nyse(6), nyse(7)}, []float64{100, 110, 121, 99})
+
+	tests := []struct {
+		name     string
+		compare  *OHLCVData
+		expected [][]float64
+	}{
+		{
+			"same calendar",
+			data("QQQ", []time.Time{nyse(2), nyse(3), nyse(6), nyse(7)}, []float64{50, 45, 60, 55}),
+			[][]float64{{0, 10, 21, -1}, {0, -10, 20, 10}},
+		},
+		// a KRX holiday on Jan 6 keeps the close of Jan 3
+		{
+			"KRX holiday",
+			data("005930.KS", []time.Time{krx(2), krx(3), krx(7)}, []float64{200, 210, 180}),
+			[][]float64{{0, 10, 21, -1}, {0, 5, 5, -10}},
+		},
+		// bars between NYSE bars are superseded by the latest one
+		{
+			"NYSE holiday",
+			data("005930.KS", []time.Time{krx(2), krx(3), krx(4), krx(6), krx(7)}, []float64{200, 210, 300, 220, 180}),
+			[][]float64{{0, 10, 21, -1}, {0, 5, 10, -10}},
+		},
+		// all series start at the first bar at which every symbol has a price
+		{
+			"later start",
+			data("NEW", []time.Time{nyse(3), nyse(6), nyse(7)}, []float64{20, 22, 18}),
+			[][]float64{{nan, 0, 10, -10}, {nan, 0, 10, -10}},
+		},
+		{
+			"later start, KRX",
+			data("005930.KS", []time.Time{krx(6), krx(7)}, []float64{200, 100}),
+			[][]float64{{nan, nan, 0, -18.181818}, {nan, nan, 0, -50}},
+		},
+		// KRX bars of Jan 3 are sampled at the NYSE open of the same date
+		{
+			"earlier start",
+			data("005930.KS", []time.Time{krx(1), krx(3), krx(6)}, []float64{100, 200, 150}),
+			[][]float64{{0, 10, 21, -1}, {0, 100, 50, 50}},
+		},
+		{
+			"no common bar",
+			data("LATE", []time.Time{nyse(8), nyse(9)}, []float64{20, 22}),
+			[][]float64{{nan, nan, nan, nan}, {nan, nan, nan, nan}},
+		},
+		{
+			"no bars",
+			data("NONE", []time.Time{}, []float64{}),
+			[][]float64{{nan, nan, nan, nan}, {nan, nan, nan, nan}},
+		},
+	}
+
+	for _, test := range tests {
+		series := alignCompareSeries([]*OHLCVData{spy, test.compare})
+		if len(series) != len(test.expected) {
+			t.Fatalf("%s: expected %d series, got %d", test.name, len(test.expected), len(series))
+		}
+
+		for seriesIdx, values := range series {
+			expected := test.expected[seriesIdx]
+			if len(values) != len(expected) {
+				t.Fatalf("%s: expected %v for series %d, got %v", test.name, expected, seriesIdx, values)
+			}
+
+			for idx, v := range values {
+				if math.IsNaN(v) != math.IsNaN(expected[idx]) || (!math.IsNaN(v) && math.Abs(v-expected[idx]) > 1e-4) {
+					t.Fatalf("%s: expected %v for series %d, got %v", test.name, expected, seriesIdx, values)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package finance_svc
+
+import (
+	"context"
+	"image/color"
+	"time"
+)
+
+var (
+	goChartEarningsColor = color.RGBA{R: 0x1f, G: 0x77, B: 0xb4, A: 0xff}
+	goChartDividendColor = color.RGBA{R: 0x2c, G: 0xa0, B: 0x2c, A: 0xff}
+	goChartSplitColor    = color.RGBA{R: 0xff, G: 0x7f, B: 0x0e, A: 0xff}
+	goChartEventColor    = color.RGBA{R: 0x9e, G: 0x9e, B: 0x9e, A: 0xff}
+	goChartEventText     = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+)
+
+// getEvents returns corporate events from the history service, or downloads them
+func (renderer *GoChartRenderer) getEvents(ctx context.Context, symbol string) ([]StockEvent, error) {
+	if renderer.HistoryService != nil {
+		return renderer.HistoryService.GetEvents(ctx, symbol)
+	}
+	return fetchStockEvents(ctx, symbol)
+}
+
+// getEventBarIndex returns the first bar of the event day, or the bar whose period contains
+// the event, false if the event is out of the chart
+func getEventBarIndex(data *OHLCVData, event StockEvent) (int, bool) {
+	if len(data.Bars) == 0 {
+		return 0, false
+	}
+
+	location := data.Bars[0].Time.Location()
+	eventDay := event.Time.In(location).Format("2006-01-02")
+
+	for idx, bar := range data.Bars {
+		barDay := bar.Time.Format("2006-01-02")
+		if barDay == eventDay {
+			return idx, true
+		}
+
+		if barDay > eventDay {
+			// e.g., a weekly bar starting before the event
+			return idx - 1, idx > 0
+		}
+	}
+
+	lastBar := data.Bars[len(data.Bars)-1]
+	barDuration := time.Duration(chartIntervalDays[data.Interval] * float64(24*time.Hour))
+	return len(data.Bars) - 1, event.Time.Before(lastBar.Time.Add(barDuration))
+}
+
+// drawEvents draws a line and a label at the bottom of the pane for each event in the chart,
+// events of unknown types are not drawn
+func (renderer *GoChartRenderer) drawEvents(canvas chartCanvas, pane *chartPane, data *OHLCVData, events []StockEvent) {
+	type eventMarker struct {
+		Index int
+		Event StockEvent
+	}
+
+	markers := []eventMarker{}
+	for _, event := range events {
+		// e.g., types stored by newer versions
+		if len(event.Label()) == 0 {
+			continue
+		}
+
+		if idx, ok := getEventBarIndex(data, event); ok {
+			markers = append(markers, eventMarker{Index: idx, Event: event})
+		}
+	}
+
+	if len(markers) == 0 {
+		return
+	}
+
+	// full labels only if they fit between markers
+	fullLabels := true
+	for _, marker := range markers {
+		if canvas.TextWidth(marker.Event.Label())+6 > pane.Width/float64(len(markers)) {
+			fullLabels = false
+			break
+		}
+	}
+
+	textHeight := canvas.TextHeight()
+	for _, marker := range markers {
+		eventColor := getEventColor(marker.Event.Type)
+		x := pane.X(marker.Index)
+		canvas.StrokeLine([]chartPoint{{X: x, Y: pane.Top}, {X: x, Y: pane.Bottom()}}, 1, color.NRGBA{R: eventColor.R, G: eventColor.G, B: eventColor.B, A: 0x60})
+
+		label := marker.Event.Label()
+		if !fullLabels {
+			label = label[:1]
+		}
+
+		labelWidth := canvas.TextWidth(label) + 4
+		labelTop := pane.Bottom() - textHeight - 5
+		canvas.FillRect(x-labelWidth/2, labelTop, labelWidth, textHeight+4, eventColor)
+		canvas.DrawText(x, labelTop+textHeight+1, label, textAnchorMiddle, goChartEventText)
+	}
+}
+
+func getEventColor(eventType StockEventType) color.RGBA {
+	switch eventType {
+	case StockEventEarnings:
+		return goChartEarningsColor
+	case StockEventDividend:
+		return goChartDividendColor
+	case StockEventSplit:
+		return goChartSplitColor
+	default:
+		return goChartEventColor
+	}
+}
@@ -0,0 +1,732 @@
+package finance_svc
+
+import (
+	"context"
+	"fmt"
+	"image/color"
+	"math"
+	"os"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	goChartMarginLeft   = 70
+	goChartMarginRight  = 20
+	goChartMarginTop    = 30
+	goChartMarginBottom = 45
+
+	goChartYTicks = 6
+	goChartXTicks = 5
+
+	goChartPaneGap       = 8
+	goChartSubPaneRatio  = 0.25
+	goChartMinPriceRatio = 0.4
+)
+
+var (
+	goChartOverlayColors = []color.Color{
+		color.RGBA{R: 0xff, G: 0x7f, B: 0x0e, A: 0xff},
+		color.RGBA{R: 0x94, G: 0x67, B: 0xbd, A: 0xff},
+		color.RGBA{R: 0x8c, G: 0x56, B: 0x4b, A: 0xff},
+		color.RGBA{R: 0xe3, G: 0x77, B: 0xc2, A: 0xff},
+		color.RGBA{R: 0x17, G: 0xbe, B: 0xcf, A: 0xff},
+		color.RGBA{R: 0xbc, G: 0xbd, B: 0x22, A: 0xff},
+	}
+	goChartBollingerColor = color.RGBA{R: 0x7f, G: 0x7f, B: 0x7f, A: 0xff}
+	goChartVWAPColor      = color.RGBA{R: 0xd6, G: 0x27, B: 0x28, A: 0xff}
+	goChartRSIColor       = color.RGBA{R: 0x94, G: 0x67, B: 0xbd, A: 0xff}
+	goChartMACDColor      = color.RGBA{R: 0x1f, G: 0x77, B: 0xb4, A: 0xff}
+	goChartSignalColor    = color.RGBA{R: 0xff, G: 0x7f, B: 0x0e, A: 0xff}
+)
+
+// GoChartRenderer draws charts in pure go from price bars
+type GoChartRenderer struct {
+	// TimeService defines market sessions shaded on intraday charts, no shading if nil
+	TimeService *TimeSVC
+	// HistoryService provides price bars, bars are downloaded for every render if nil
+	HistoryService *HistorySVC
+}
+
+// NewGoChartRenderer creates a GoChartRenderer
+func NewGoChartRenderer(timeService *TimeSVC, historyService *HistorySVC) *GoChartRenderer {
+	return &GoChartRenderer{
+		TimeService:    timeService,
+		HistoryService: historyService,
+	}
+}
+
+// GetType ...
+func (renderer *GoChartRenderer) GetType() ChartRendererType {
+	return ChartRendererGo
+}
+
+// Render ...
+func (renderer *GoChartRenderer) Render(ctx context.Context, chartData *StockChartData, filepath string) error {
+	logger := log.WithFields(log.Fields{
+		"package":  "GoChartRenderer",
+		"function": "Render",
+	})
+
+	data, err := renderer.getOHLCV(ctx, chartData.StockSymbol, chartData.Period, chartData.Interval)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	theme := getChartTheme(chartData.Options)
+	width, height, dpi := chartData.Options.GetImageSize()
+	canvas := newChartCanvas(chartData.Options.Format, width, height, float64(dpi)/defaultChartDPI, theme.Background)
+
+	if chartData.Options.Style == ChartStyleSparkline {
+		reference := renderer.getSparklineReference(ctx, data)
+		renderer.drawSparkline(canvas, &theme, data, reference)
+	} else if len(chartData.Options.CompareSymbols) > 0 {
+		compareData := []*OHLCVData{data}
+		for _, symbol := range chartData.Options.CompareSymbols {
+			symbolData, err := renderer.getOHLCV(ctx, symbol, chartData.Period, chartData.Interval)
+			if err != nil {
+				logger.Error(err)
+				return err
+			}
+			compareData = append(compareData, symbolData)
+		}
+
+		renderer.drawCompareChart(canvas, &theme, compareData)
+	} else {
+		var events []StockEvent
+		if chartData.Options.Events {
+			events, err = renderer.getEvents(ctx, chartData.StockSymbol)
+			if err != nil {
+				// the chart is still useful without markers
+				logger.Warn(err)
+			}
+		}
+
+		renderer.drawChart(canvas, &theme, data, events, chartData.Options)
+	}
+
+	f, err := os.Create(filepath)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	err = canvas.Encode(f)
+	if err != nil {
+		f.Close()
+		logger.Error(err)
+		return err
+	}
+
+	// writes may fail on close, the image is truncated then
+	err = f.Close()
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+	return nil
+}
+
+// getOHLCV returns price bars from the history service, or downloads them
+func (renderer *GoChartRenderer) getOHLCV(ctx context.Context, symbol string, period ChartPeriod, interval ChartInterval) (*OHLCVData, error) {
+	if renderer.HistoryService != nil {
+		return renderer.HistoryService.GetHistory(ctx, symbol, period, interval)
+	}
+	return fetchOHLCV(ctx, symbol, period, interval)
+}
+
+// chartPane maps data coordinates of a chart pane to canvas coordinates
+type chartPane struct {
+	Left   float64
+	Top    float64
+	Width  float64
+	Height float64
+
+	BarCount int
+	MinValue float64
+	MaxValue float64
+}
+
+// X returns the center of a bar
+func (pane *chartPane) X(idx int) float64 {
+	if pane.BarCount == 0 {
+		return pane.Left + pane.Width/2
+	}
+	return pane.Left + pane.Width*(float64(idx)+0.5)/float64(pane.BarCount)
+}
+
+// Y returns the vertical position of a value
+func (pane *chartPane) Y(value float64) float64 {
+	if pane.MaxValue == pane.MinValue {
+		return pane.Top + pane.Height/2
+	}
+	return pane.Top + pane.Height*(pane.MaxValue-value)/(pane.MaxValue-pane.MinValue)
+}
+
+// BarWidth returns the width of a candle or a volume bar
+func (pane *chartPane) BarWidth() float64 {
+	if pane.BarCount == 0 {
+		return 1
+	}
+	return math.Max(1, pane.Width/float64(pane.BarCount)*0.7)
+}
+
+// Bottom returns the bottom of the pane
+func (pane *chartPane) Bottom() float64 {
+	return pane.Top + pane.Height
+}
+
+// chartSeries is a line drawn on a pane
+type chartSeries struct {
+	Name   string
+	Values []float64
+	Color  color.Color
+}
+
+// drawChart draws price, indicators and sub panes, and marks events on the price pane
+func (renderer *GoChartRenderer) drawChart(canvas chartCanvas, theme *chartTheme, data *OHLCVData, events []StockEvent, options ChartOptions) {
+	width, height := canvas.Size()
+
+	if len(data.Bars) == 0 {
+		canvas.DrawText(width/2, height/2, "No data", textAnchorMiddle, theme.Text)
+		return
+	}
+
+	plotWidth := width - goChartMarginLeft - goChartMarginRight
+	plotHeight := height - goChartMarginTop - goChartMarginBottom
+
+	subPaneCount := 0
+	if options.Volume {
+		subPaneCount++
+	}
+	if options.Indicators.RSI > 0 {
+		subPaneCount++
+	}
+	if options.Indicators.MACD {
+		subPaneCount++
+	}
+
+	subPaneRatio := goChartSubPaneRatio
+	if subPaneCount > 1 {
+		subPaneRatio = (1 - goChartMinPriceRatio) / float64(subPaneCount)
+	}
+
+	subPaneHeight := (plotHeight - goChartPaneGap*float64(subPaneCount)) * subPaneRatio
+	priceHeight := plotHeight - (subPaneHeight+goChartPaneGap)*float64(subPaneCount)
+
+	overlays := renderer.getOverlays(data, options)
+
+	minValue, maxValue := renderer.getPriceRange(data, options.Style)
+	for _, overlay := range overlays {
+		for _, v := range overlay.Values {
+			if !math.IsNaN(v) {
+				minValue = math.Min(minValue, v)
+				maxValue = math.Max(maxValue, v)
+			}
+		}
+	}
+
+	ticks := niceTicks(minValue, maxValue, goChartYTicks)
+	if len(ticks) > 0 {
+		minValue = math.Min(minValue, ticks[0])
+		maxValue = math.Max(maxValue, ticks[len(ticks)-1])
+	}
+
+	pricePane := &chartPane{
+		Left:     goChartMarginLeft,
+		Top:      goChartMarginTop,
+		Width:    plotWidth,
+		Height:   priceHeight,
+		BarCount: len(data.Bars),
+		MinValue: minValue,
+		MaxValue: maxValue,
+	}
+
+	panes := []*chartPane{pricePane}
+
+	var sessions []MarketType
+	if hasExtendedSessions(renderer.TimeService, data.Symbol, data.Interval) {
+		sessions = renderer.getBarSessions(data)
+	}
+
+	renderer.drawSessions(canvas, theme, pricePane, sessions)
+	renderer.drawYAxis(canvas, theme, pricePane, ticks, "")
+	renderer.drawBollingerBand(canvas, theme, pricePane, data, options)
+	renderer.drawPrice(canvas, theme, pricePane, data, options.Style)
+	for _, overlay := range overlays {
+		renderer.drawSeries(canvas, pricePane, overlay)
+	}
+	renderer.drawEvents(canvas, pricePane, data, events)
+	renderer.drawLegend(canvas, theme, pricePane, overlays)
+
+	newSubPane := func() *chartPane {
+		lastPane := panes[len(panes)-1]
+		subPane := &chartPane{
+			Left:     goChartMarginLeft,
+			Top:      lastPane.Bottom() + goChartPaneGap,
+			Width:    plotWidth,
+			Height:   subPaneHeight,
+			BarCount: len(data.Bars),
+			MinValue: 0,
+			MaxValue: 0,
+		}
+		panes = append(panes, subPane)
+		renderer.drawSessions(canvas, theme, subPane, sessions)
+		return subPane
+	}
+
+	if options.Volume {
+		renderer.drawVolume(canvas, theme, newSubPane(), data)
+	}
+
+	if options.Indicators.RSI > 0 {
+		renderer.drawRSI(canvas, theme, newSubPane(), data, options)
+	}
+
+	if options.Indicators.MACD {
+		renderer.drawMACD(canvas, theme, newSubPane(), data, options)
+	}
+
+	renderer.drawXAxis(canvas, theme, panes, data)
+
+	for _, pane := range panes {
+		renderer.drawFrame(canvas, theme, pane)
+	}
+
+	canvas.DrawText(pricePane.Left, pricePane.Top-10, "Price", textAnchorStart, theme.Text)
+	canvas.DrawText(pricePane.Left+pricePane.Width/2, height-8, fmt.Sprintf("Time %s", data.Period), textAnchorMiddle, theme.Text)
+}
+
+// getPriceValues returns prices that indicators are computed on
+func (renderer *GoChartRenderer) getPriceValues(data *OHLCVData, style ChartStyle) []float64 {
+	values := make([]float64, 0, len(data.Bars))
+	for _, bar := range data.Bars {
+		switch style {
+		case ChartStyleCandlestick, ChartStyleOHLC:
+			values = append(values, bar.Close)
+		default:
+			values = append(values, bar.AdjClose)
+		}
+	}
+	return values
+}
+
+// getOverlays returns indicator lines drawn on the price pane
+func (renderer *GoChartRenderer) getOverlays(data *OHLCVData, options ChartOptions) []chartSeries {
+	overlays := []chartSeries{}
+	values := renderer.getPriceValues(data, options.Style)
+	colorIdx := 0
+
+	nextColor := func() color.Color {
+		c := goChartOverlayColors[colorIdx%len(goChartOverlayColors)]
+		colorIdx++
+		return c
+	}
+
+	for _, window := range options.Indicators.SMA {
+		overlays = append(overlays, chartSeries{
+			Name:   fmt.Sprintf("SMA%d", window),
+			Values: computeSMA(values, window),
+			Color:  nextColor(),
+		})
+	}
+
+	for _, window := range options.Indicators.EMA {
+		overlays = append(overlays, chartSeries{
+			Name:   fmt.Sprintf("EMA%d", window),
+			Values: computeEMA(values, window),
+			Color:  nextColor(),
+		})
+	}
+
+	if options.Indicators.Bollinger > 0 {
+		middle, upper, lower := computeBollinger(values, options.Indicators.Bollinger, bollingerDeviation)
+		overlays = append(overlays, chartSeries{
+			Name:   fmt.Sprintf("BB%d", options.Indicators.Bollinger),
+			Values: middle,
+			Color:  goChartBollingerColor,
+		}, chartSeries{
+			Values: upper,
+			Color:  goChartBollingerColor,
+		}, chartSeries{
+			Values: lower,
+			Color:  goChartBollingerColor,
+		})
+	}
+
+	if options.Indicators.VWAP {
+		overlays = append(overlays, chartSeries{
+			Name:   "VWAP",
+			Values: computeVWAP(data.Bars, isIntradayInterval(data.Interval)),
+			Color:  goChartVWAPColor,
+		})
+	}
+	return overlays
+}
+
+// drawBollingerBand fills the area between the upper and lower bands
+func (renderer *GoChartRenderer) drawBollingerBand(canvas chartCanvas, theme *chartTheme, pane *chartPane, data *OHLCVData, options ChartOptions) {
+	if options.Indicators.Bollinger == 0 {
+		return
+	}
+
+	values := renderer.getPriceValues(data, options.Style)
+	_, upper, lower := computeBollinger(values, options.Indicators.Bollinger, bollingerDeviation)
+
+	upperPoints := []chartPoint{}
+	lowerPoints := []chartPoint{}
+	for idx := range values {
+		if math.IsNaN(upper[idx]) {
+			continue
+		}
+		upperPoints = append(upperPoints, chartPoint{X: pane.X(idx), Y: pane.Y(upper[idx])})
+		lowerPoints = append([]chartPoint{{X: pane.X(idx), Y: pane.Y(lower[idx])}}, lowerPoints...)
+	}
+
+	canvas.FillPolygon(append(upperPoints, lowerPoints...), theme.BollingerFill)
+}
+
+func (renderer *GoChartRenderer) drawRSI(canvas chartCanvas, theme *chartTheme, pane *chartPane, data *OHLCVData, options ChartOptions) {
+	pane.MinValue = 0
+	pane.MaxValue = 100
+
+	for _, level := range []float64{rsiOversold, rsiOverbought} {
+		y := pane.Y(level)
+		canvas.StrokeLine([]chartPoint{{X: pane.Left, Y: y}, {X: pane.Left + pane.Width, Y: y}}, 1, theme.Grid)
+		canvas.DrawText(pane.Left-7, y+canvas.TextHeight()/2, strconv.Itoa(int(level)), textAnchorEnd, theme.Text)
+	}
+
+	rsi := computeRSI(renderer.getPriceValues(data, options.Style), options.Indicators.RSI)
+	renderer.drawSeries(canvas, pane, chartSeries{Values: rsi, Color: goChartRSIColor})
+	canvas.DrawText(pane.Left+4, pane.Top+canvas.TextHeight()+2, fmt.Sprintf("RSI%d", options.Indicators.RSI), textAnchorStart, goChartRSIColor)
+}
+
+func (renderer *GoChartRenderer) drawMACD(canvas chartCanvas, theme *chartTheme, pane *chartPane, data *OHLCVData, options ChartOptions) {
+	macd, signal, histogram := computeMACD(renderer.getPriceValues(data, options.Style), macdFastWindow, macdSlowWindow, macdSignalWindow)
+
+	maxAbs := 0.0
+	for _, series := range [][]float64{macd, signal, histogram} {
+		for _, v := range series {
+			if !math.IsNaN(v) {
+				maxAbs = math.Max(maxAbs, math.Abs(v))
+			}
+		}
+	}
+
+	if maxAbs == 0 {
+		canvas.DrawText(pane.Left+pane.Width/2, pane.Top+pane.Height/2, "Not enough data", textAnchorMiddle, theme.Text)
+		return
+	}
+
+	pane.MinValue = -maxAbs
+	pane.MaxValue = maxAbs
+
+	zero := pane.Y(0)
+	canvas.StrokeLine([]chartPoint{{X: pane.Left, Y: zero}, {X: pane.Left + pane.Width, Y: zero}}, 1, theme.Grid)
+	canvas.DrawText(pane.Left-7, zero+canvas.TextHeight()/2, "0", textAnchorEnd, theme.Text)
+
+	barWidth := pane.BarWidth()
+	for idx, v := range histogram {
+		if math.IsNaN(v) {
+			continue
+		}
+
+		barColor := theme.Up
+		if v < 0 {
+			barColor = theme.Down
+		}
+
+		y := pane.Y(v)
+		canvas.FillRect(pane.X(idx)-barWidth/2, math.Min(y, zero), barWidth, math.Abs(zero-y), barColor)
+	}
+
+	renderer.drawSeries(canvas, pane, chartSeries{Values: macd, Color: goChartMACDColor})
+	renderer.drawSeries(canvas, pane, chartSeries{Values: signal, Color: goChartSignalColor})
+	canvas.DrawText(pane.Left+4, pane.Top+canvas.TextHeight()+2, fmt.Sprintf("MACD(%d,%d,%d)", macdFastWindow, macdSlowWindow, macdSignalWindow), textAnchorStart, goChartMACDColor)
+}
+
+// drawSeries draws a line skipping undefined values
+func (renderer *GoChartRenderer) drawSeries(canvas chartCanvas, pane *chartPane, series chartSeries) {
+	points := []chartPoint{}
+	for idx, v := range series.Values {
+		if math.IsNaN(v) {
+			canvas.StrokeLine(points, 1, series.Color)
+			points = []chartPoint{}
+			continue
+		}
+		points = append(points, chartPoint{X: pane.X(idx), Y: pane.Y(v)})
+	}
+	canvas.StrokeLine(points, 1, series.Color)
+}
+
+// drawLegend draws names of overlays at the top left corner of the pane
+func (renderer *GoChartRenderer) drawLegend(canvas chartCanvas, theme *chartTheme, pane *chartPane, overlays []chartSeries) {
+	names := 0
+	legendWidth := 0.0
+	for _, overlay := range overlays {
+		if len(overlay.Name) > 0 {
+			names++
+			legendWidth = math.Max(legendWidth, canvas.TextWidth(overlay.Name)+24)
+		}
+	}
+
+	if names == 0 {
+		return
+	}
+
+	x := pane.Left + 6
+	y := pane.Top + canvas.TextHeight() + 4
+	canvas.FillRect(pane.Left+1, pane.Top+1, legendWidth, float64(names)*(canvas.TextHeight()+4)+6, theme.Legend)
+
+	for _, overlay := range overlays {
+		if len(overlay.Name) == 0 {
+			continue
+		}
+
+		canvas.FillRect(x, y-canvas.TextHeight()/2-1, 10, 2, overlay.Color)
+		canvas.DrawText(x+14, y, overlay.Name, textAnchorStart, overlay.Color)
+		y += canvas.TextHeight() + 4
+	}
+}
+
+// getPriceRange returns min and max of prices that the style draws
+func (renderer *GoChartRenderer) getPriceRange(data *OHLCVData, style ChartStyle) (float64, float64) {
+	minValue, maxValue := math.Inf(1), math.Inf(-1)
+	for _, bar := range data.Bars {
+		switch style {
+		case ChartStyleCandlestick, ChartStyleOHLC:
+			minValue = math.Min(minValue, bar.Low)
+			maxValue = math.Max(maxValue, bar.High)
+		default:
+			minValue = math.Min(minValue, bar.AdjClose)
+			maxValue = math.Max(maxValue, bar.AdjClose)
+		}
+	}
+	return minValue, maxValue
+}
+
+func (renderer *GoChartRenderer) drawPrice(canvas chartCanvas, theme *chartTheme, pane *chartPane, data *OHLCVData, style ChartStyle) {
+	switch style {
+	case ChartStyleCandlestick:
+		barWidth := pane.BarWidth()
+		for idx, bar := range data.Bars {
+			barColor := renderer.getBarColor(theme, bar)
+			x := pane.X(idx)
+			canvas.StrokeLine([]chartPoint{{X: x, Y: pane.Y(bar.High)}, {X: x, Y: pane.Y(bar.Low)}}, 1, barColor)
+
+			top := pane.Y(math.Max(bar.Open, bar.Close))
+			bottom := pane.Y(math.Min(bar.Open, bar.Close))
+			canvas.FillRect(x-barWidth/2, top, barWidth, math.Max(1, bottom-top), barColor)
+		}
+	case ChartStyleOHLC:
+		tickWidth := pane.BarWidth() / 2
+		for idx, bar := range data.Bars {
+			barColor := renderer.getBarColor(theme, bar)
+			x := pane.X(idx)
+			canvas.StrokeLine([]chartPoint{{X: x, Y: pane.Y(bar.High)}, {X: x, Y: pane.Y(bar.Low)}}, 1, barColor)
+			canvas.StrokeLine([]chartPoint{{X: x - tickWidth, Y: pane.Y(bar.Open)}, {X: x, Y: pane.Y(bar.Open)}}, 1, barColor)
+			canvas.StrokeLine([]chartPoint{{X: x, Y: pane.Y(bar.Close)}, {X: x + tickWidth, Y: pane.Y(bar.Close)}}, 1, barColor)
+		}
+	case ChartStyleArea:
+		points := renderer.getClosePoints(pane, data)
+		polygon := append([]chartPoint{{X: points[0].X, Y: pane.Bottom()}}, points...)
+		polygon = append(polygon, chartPoint{X: points[len(points)-1].X, Y: pane.Bottom()})
+		canvas.FillPolygon(polygon, theme.Area)
+		canvas.StrokeLine(points, 1.5, theme.Line)
+	default:
+		canvas.StrokeLine(renderer.getClosePoints(pane, data), 1.5, theme.Line)
+	}
+}
+
+func (renderer *GoChartRenderer) drawVolume(canvas chartCanvas, theme *chartTheme, pane *chartPane, data *OHLCVData) {
+	maxVolume := int64(0)
+	for _, bar := range data.Bars {
+		if bar.Volume > maxVolume {
+			maxVolume = bar.Volume
+		}
+	}
+
+	if maxVolume == 0 {
+		canvas.DrawText(pane.Left+pane.Width/2, pane.Top+pane.Height/2, "No volume", textAnchorMiddle, theme.Text)
+		return
+	}
+
+	pane.MinValue = 0
+	pane.MaxValue = float64(maxVolume)
+
+	barWidth := pane.BarWidth()
+	for idx, bar := range data.Bars {
+		top := pane.Y(float64(bar.Volume))
+		canvas.FillRect(pane.X(idx)-barWidth/2, top, barWidth, pane.Bottom()-top, renderer.getBarColor(theme, bar))
+	}
+
+	canvas.StrokeLine([]chartPoint{{X: pane.Left - 4, Y: pane.Top}, {X: pane.Left, Y: pane.Top}}, 1, theme.Axis)
+	canvas.DrawText(pane.Left-7, pane.Top+canvas.TextHeight(), formatVolume(maxVolume), textAnchorEnd, theme.Text)
+	canvas.DrawText(pane.Left-7, pane.Bottom(), "Vol", textAnchorEnd, theme.Text)
+}
+
+func (renderer *GoChartRenderer) getClosePoints(pane *chartPane, data *OHLCVData) []chartPoint {
+	points := make([]chartPoint, 0, len(data.Bars))
+	for idx, bar := range data.Bars {
+		points = append(points, chartPoint{X: pane.X(idx), Y: pane.Y(bar.AdjClose)})
+	}
+	return points
+}
+
+func (renderer *GoChartRenderer) getBarColor(theme *chartTheme, bar OHLCV) color.Color {
+	if bar.Close < bar.Open {
+		return theme.Down
+	}
+	return theme.Up
+}
+
+// drawYAxis draws horizontal grid lines and value labels followed by unit
+func (renderer *GoChartRenderer) drawYAxis(canvas chartCanvas, theme *chartTheme, pane *chartPane, ticks []float64, unit string) {
+	precision := tickPrecision(ticks)
+	for _, tick := range ticks {
+		y := pane.Y(tick)
+		canvas.StrokeLine([]chartPoint{{X: pane.Left, Y: y}, {X: pane.Left + pane.Width, Y: y}}, 1, theme.Grid)
+		canvas.StrokeLine([]chartPoint{{X: pane.Left - 4, Y: y}, {X: pane.Left, Y: y}}, 1, theme.Axis)
+		canvas.DrawText(pane.Left-7, y+canvas.TextHeight()/2, strconv.FormatFloat(tick, 'f', precision, 64)+unit, textAnchorEnd, theme.Text)
+	}
+}
+
+// drawXAxis draws vertical grid lines on all panes and time labels under the last pane
+func (renderer *GoChartRenderer) drawXAxis(canvas chartCanvas, theme *chartTheme, panes []*chartPane, data *OHLCVData) {
+	layout := timeLabelLayout(data.Period, data.Interval)
+	lastPane := panes[len(panes)-1]
+
+	for _, idx := range labelIndexes(len(data.Bars), goChartXTicks) {
+		x := lastPane.X(idx)
+		for _, pane := range panes {
+			canvas.StrokeLine([]chartPoint{{X: x, Y: pane.Top}, {X: x, Y: pane.Bottom()}}, 1, theme.Grid)
+		}
+
+		bottom := lastPane.Bottom()
+		canvas.StrokeLine([]chartPoint{{X: x, Y: bottom}, {X: x, Y: bottom + 4}}, 1, theme.Axis)
+		canvas.DrawText(x, bottom+6+canvas.TextHeight(), data.Bars[idx].Time.Format(layout), textAnchorMiddle, theme.Text)
+	}
+}
+
+func (renderer *GoChartRenderer) drawFrame(canvas chartCanvas, theme *chartTheme, pane *chartPane) {
+	canvas.StrokeLine([]chartPoint{
+		{X: pane.Left, Y: pane.Top},
+		{X: pane.Left + pane.Width, Y: pane.Top},
+		{X: pane.Left + pane.Width, Y: pane.Bottom()},
+		{X: pane.Left, Y: pane.Bottom()},
+		{X: pane.Left, Y: pane.Top},
+	}, 1, theme.Axis)
+}
+
+// niceTicks returns evenly spaced round values covering min and max
+func niceTicks(min float64, max float64, count int) []float64 {
+	if math.IsInf(min, 0) || math.IsInf(max, 0) || count < 2 {
+		return []float64{}
+	}
+
+	if min == max {
+		if min == 0 {
+			max = 1
+		} else {
+			delta := math.Abs(min) * 0.01
+			min -= delta
+			max += delta
+		}
+	}
+
+	rawStep := (max - min) / float64(count-1)
+	magnitude := math.Pow(10, math.Floor(math.Log10(rawStep)))
+	step := magnitude * 10
+	for _, candidate := range []float64{1, 2, 2.5, 5, 10} {
+		if rawStep <= candidate*magnitude {
+			step = candidate * magnitude
+			break
+		}
+	}
+
+	ticks := []float64{}
+	start := math.Floor(min/step) * step
+	for i := 0; i <= count*2; i++ {
+		tick := start + step*float64(i)
+		ticks = append(ticks, tick)
+		if tick >= max-step*1e-9 {
+			break
+		}
+	}
+	return ticks
+}
+
+// tickPrecision returns number of decimal places needed to tell ticks apart
+func tickPrecision(ticks []float64) int {
+	if len(ticks) < 2 {
+		return 2
+	}
+
+	step := math.Abs(ticks[1] - ticks[0])
+	precision := 0
+	for precision < 6 && step < 1 {
+		step *= 10
+		precision++
+	}
+
+	if math.Abs(step-math.Round(step)) > 1e-6 {
+		precision++
+	}
+	return precision
+}
+
+// labelIndexes returns bar indexes to put labels on
+func labelIndexes(barCount int, count int) []int {
+	indexes := []int{}
+	if barCount == 0 {
+		return indexes
+	}
+
+	if barCount <= count {
+		for idx := 0; idx < barCount; idx++ {
+			indexes = append(indexes, idx)
+		}
+		return indexes
+	}
+
+	for i := 0; i < count; i++ {
+		indexes = append(indexes, int(math.Round(float64(i)*float64(barCount-1)/float64(count-1))))
+	}
+	return indexes
+}
+
+// timeLabelLayout returns time format of x-axis labels
+func timeLabelLayout(period ChartPeriod, interval ChartInterval) string {
+	switch interval {
+	case ChartInteval1Min, ChartInteval5Min, ChartInteval30Min, ChartInteval1Hour:
+		if period == ChartPeriod1Day {
+			return "15:04"
+		}
+		return "01/02 15:04"
+	case ChartInteval1Day, ChartInteval5Day:
+		switch period {
+		case ChartPeriod1Day, ChartPeriod5Day, ChartPeriod1Month, ChartPeriod3Month, ChartPeriod6Month:
+			return "01/02"
+		}
+		return "2006-01"
+	default:
+		return "2006-01"
+	}
+}
+
+// formatVolume returns a short text of a volume
+func formatVolume(volume int64) string {
+	v := float64(volume)
+	switch {
+	case v >= 1e9:
+		return fmt.Sprintf("%.1fB", v/1e9)
+	case v >= 1e6:
+		return fmt.Sprintf("%.1fM", v/1e6)
+	case v >= 1e3:
+		return fmt.Sprintf("%.1fK", v/1e3)
+	default:
+		return fmt.Sprintf("%d", volume)
+	}
+}
@@ -0,0 +1,262 @@
+package finance_svc
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"image/png"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	cache "github.com/patrickmn/go-cache"
+)
+
+// makeTestOHLCV returns count bars from start, prices go up and down around 100
+func makeTestOHLCV(symbol string, interval ChartInterval, start time.Time, step time.Duration, count int) *OHLCVData {
+	data := &OHLCVData{
+		Symbol:   symbol,
+		Currency: "USD",
+		Interval: interval,
+		Bars:     []OHLCV{},
+	}
+
+	lastClose := 100.0
+	for idx := 0; idx < count; idx++ {
+		closePrice := 100 + 10*math.Sin(float64(idx)/3)
+		data.Bars = append(data.Bars, OHLCV{
+			Time:     start.Add(step * time.Duration(idx)),
+			Open:     lastClose,
+			High:     math.Max(lastClose, closePrice) + 1,
+			Low:      math.Min(lastClose, closePrice) - 1,
+			Close:    closePrice,
+			AdjClose: closePrice,
+			Volume:   int64(1000 + idx*10),
+		})
+		lastClose = closePrice
+	}
+	return data
+}
+
+// newTestGoChartRenderer creates a renderer reading bars and events stored in its history service
+func newTestGoChartRenderer(t *testing.T, histories []*OHLCVData, events []StockEvent) *GoChartRenderer {
+	timeService, err := InitTimeSVC()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	historyService := &HistorySVC{
+		Histories: cache.New(cache.NoExpiration, cache.NoExpiration),
+		KeyLocks:  map[string]*sync.Mutex{},
+	}
+
+	for _, data := range histories {
+		historyService.Histories.Set(historyService.makeHistoryKey(data.Symbol, data.Interval), &stockHistory{
+			Symbol:     data.Symbol,
+			Currency:   data.Currency,
+			Interval:   data.Interval,
+			Period:     ChartPeriodMax,
+			UpdateTime: time.Now(),
+			AdjustTime: time.Now(),
+			Bars:       data.Bars,
+		}, cache.NoExpiration)
+
+		historyService.Histories.Set(historyService.makeEventsKey(data.Symbol), &stockEvents{
+			Symbol:     data.Symbol,
+			UpdateTime: time.Now(),
+			Events:     events,
+		}, cache.NoExpiration)
+	}
+
+	return NewGoChartRenderer(timeService, historyService)
+}
+
+// checkChartImage checks the image format and its size in pixels
+func checkChartImage(t *testing.T, name string, path string, format ChartFormat, width int, height int) {
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	defer f.Close()
+
+	if format == ChartFormatPNG {
+		img, err := png.Decode(f)
+		if err != nil {
+			t.Fatalf("%s: could not decode png - %v", name, err)
+		}
+
+		if img.Bounds().Dx() != width || img.Bounds().Dy() != height {
+			t.Fatalf("%s: expected %dx%d image, got %dx%d", name, width, height, img.Bounds().Dx(), img.Bounds().Dy())
+		}
+		return
+	}
+
+	// the whole document must be well-formed
+	decoder := xml.NewDecoder(f)
+	var root *xml.StartElement
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("%s: could not parse svg - %v", name, err)
+		}
+
+		if element, ok := token.(xml.StartElement); ok && root == nil {
+			root = &element
+		}
+	}
+
+	if root == nil || root.Name.Local != "svg" {
+		t.Fatalf("%s: expected an svg element, got %v", name, root)
+	}
+
+	size := map[string]string{}
+	for _, attr := range root.Attr {
+		size[attr.Name.Local] = attr.Value
+	}
+
+	if size["width"] != strconv.Itoa(width) || size["height"] != strconv.Itoa(height) {
+		t.Fatalf("%s: expected %dx%d image, got %sx%s", name, width, height, size["width"], size["height"])
+	}
+}
+
+func TestGoChartRendererRender(t *testing.T) {
+	dailyData := makeTestOHLCV("AAPL", ChartInteval1Day, time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC), 24*time.Hour, 60)
+	singleData := makeTestOHLCV("MSFT", ChartInteval1Day, time.Date(2020, 3, 5, 0, 0, 0, 0, time.UTC), 24*time.Hour, 1)
+
+	events := []StockEvent{
+		{Type: StockEventEarnings, Time: time.Date(2020, 1, 28, 21, 0, 0, 0, time.UTC)},
+		{Type: StockEventDividend, Time: time.Date(2020, 2, 7, 0, 0, 0, 0, time.UTC), Amount: 0.77},
+		{Type: StockEventSplit, Time: time.Date(2020, 3, 5, 0, 0, 0, 0, time.UTC), Numerator: 4, Denominator: 1},
+		{Type: "spinoff", Time: time.Date(2020, 2, 14, 0, 0, 0, 0, time.UTC)},
+	}
+
+	renderer := newTestGoChartRenderer(t, []*OHLCVData{dailyData, singleData}, events)
+
+	styles := []ChartStyle{ChartStyleLine, ChartStyleCandlestick, ChartStyleOHLC, ChartStyleArea}
+	panes := []struct {
+		name       string
+		volume     bool
+		indicators ChartIndicators
+		events     bool
+	}{
+		{"price", false, ChartIndicators{}, false},
+		{"volume", true, ChartIndicators{}, false},
+		{"overlays", false, ChartIndicators{SMA: []int{5, 20}, EMA: []int{10}, Bollinger: 20, VWAP: true}, true},
+		{"all panes", true, ChartIndicators{SMA: []int{5}, RSI: 14, MACD: true}, true},
+	}
+	formats := []ChartFormat{ChartFormatPNG, ChartFormatSVG}
+
+	dir := t.TempDir()
+	for _, data := range []*OHLCVData{dailyData, singleData} {
+		for _, style := range styles {
+			for _, pane := range panes {
+				for _, format := range formats {
+					name := fmt.Sprintf("%s %s %s %s", data.Symbol, style, pane.name, format)
+					chartData := &StockChartData{
+						StockSymbol: data.Symbol,
+						Period:      ChartPeriod3Month,
+						Interval:    ChartInteval1Day,
+						Options: ChartOptions{
+							Style:      style,
+							Volume:     pane.volume,
+							Indicators: pane.indicators,
+							Events:     pane.events,
+							Format:     format,
+							Width:      400,
+							Height:     300,
+						},
+					}
+
+					path := filepath.Join(dir, "chart."+string(format))
+					err := renderer.Render(context.Background(), chartData, path)
+					if err != nil {
+						t.Fatalf("%s: %v", name, err)
+					}
+
+					checkChartImage(t, name, path, format, 400, 300)
+				}
+			}
+		}
+	}
+}
+
+func TestGoChartRendererRenderOptions(t *testing.T) {
+	// 5 min bars from 4:00 to 20:00 in New York on Fri, Mar 6, 2020
+	intradayData := makeTestOHLCV("TSLA", ChartInteval5Min, time.Date(2020, 3, 6, 9, 0, 0, 0, time.UTC), 5*time.Minute, 16*12)
+	dailyData := makeTestOHLCV("AAPL", ChartInteval1Day, time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC), 24*time.Hour, 60)
+	compareData := makeTestOHLCV("^GSPC", ChartInteval1Day, time.Date(2020, 1, 9, 0, 0, 0, 0, time.UTC), 48*time.Hour, 25)
+
+	renderer := newTestGoChartRenderer(t, []*OHLCVData{intradayData, dailyData, compareData}, nil)
+
+	tests := []struct {
+		name     string
+		symbol   string
+		period   ChartPeriod
+		interval ChartInterval
+		options  ChartOptions
+		width    int
+		height   int
+	}{
+		{"default size", "AAPL", ChartPeriod3Month, ChartInteval1Day, ChartOptions{}, defaultChartWidth, defaultChartHeight},
+		// a higher dpi enlarges drawings, not the image
+		{"dpi", "AAPL", ChartPeriod3Month, ChartInteval1Day, ChartOptions{Style: ChartStyleCandlestick, Volume: true, DPI: 200}, defaultChartWidth, defaultChartHeight},
+		{"dark theme", "AAPL", ChartPeriod3Month, ChartInteval1Day, ChartOptions{Theme: ChartThemeDark, UpColor: "00ff00", DownColor: "ff0000"}, defaultChartWidth, defaultChartHeight},
+		// extended hours are shaded on intraday charts
+		{"sessions", "TSLA", ChartPeriod1Day, ChartInteval5Min, ChartOptions{Style: ChartStyleCandlestick, Volume: true, Indicators: ChartIndicators{VWAP: true}, Width: 800, Height: 600}, 800, 600},
+		{"compare", "AAPL", ChartPeriod3Month, ChartInteval1Day, ChartOptions{CompareSymbols: []string{"^GSPC"}, Width: 500, Height: 400}, 500, 400},
+		{"sparkline", "TSLA", ChartPeriod1Day, ChartInteval5Min, ChartOptions{Style: ChartStyleSparkline, Width: defaultSparklineWidth, Height: defaultSparklineHeight}, defaultSparklineWidth, defaultSparklineHeight},
+	}
+
+	dir := t.TempDir()
+	for _, test := range tests {
+		for _, format := range []ChartFormat{ChartFormatPNG, ChartFormatSVG} {
+			name := fmt.Sprintf("%s %s", test.name, format)
+
+			options := test.options
+			options.Format = format
+
+			chartData := &StockChartData{
+				StockSymbol: test.symbol,
+				Period:      test.period,
+				Interval:    test.interval,
+				Options:     options,
+			}
+
+			path := filepath.Join(dir, "chart."+string(format))
+			err := renderer.Render(context.Background(), chartData, path)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+
+			checkChartImage(t, name, path, format, test.width, test.height)
+		}
+	}
+}
+
+func TestGoChartRendererDrawEmpty(t *testing.T) {
+	renderer := &GoChartRenderer{}
+	theme := getChartTheme(ChartOptions{})
+	options := ChartOptions{Volume: true, Indicators: ChartIndicators{SMA: []int{5}, Bollinger: 20, RSI: 14, MACD: true}}
+
+	for _, style := range []ChartStyle{ChartStyleLine, ChartStyleCandlestick, ChartStyleOHLC, ChartStyleArea} {
+		for _, format := range []ChartFormat{ChartFormatPNG, ChartFormatSVG} {
+			options.Style = style
+			canvas := newChartCanvas(format, 400, 300, 1, theme.Background)
+			renderer.drawChart(canvas, &theme, &OHLCVData{Symbol: "AAPL", Interval: ChartInteval1Day}, nil, options)
+
+			err := canvas.Encode(&bytes.Buffer{})
+			if err != nil {
+				t.Fatalf("%s %s: %v", style, format, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package finance_svc
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// PythonChartRenderer draws charts by executing a python script
+type PythonChartRenderer struct {
+	ScriptPath string
+	// TimeService defines market sessions shaded on intraday charts, no shading if nil
+	TimeService *TimeSVC
+	// SparklineRenderer draws sparklines without starting a python process
+	SparklineRenderer *GoChartRenderer
+}
+
+// NewPythonChartRenderer creates a PythonChartRenderer
+func NewPythonChartRenderer(scriptPath string, timeService *TimeSVC, sparklineRenderer *GoChartRenderer) *PythonChartRenderer {
+	return &PythonChartRenderer{
+		ScriptPath:        scriptPath,
+		TimeService:       timeService,
+		SparklineRenderer: sparklineRenderer,
+	}
+}
+
+// GetType ...
+func (renderer *PythonChartRenderer) GetType() ChartRendererType {
+	return ChartRendererPython
+}
+
+// Render ...
+func (renderer *PythonChartRenderer) Render(ctx context.Context, chartData *StockChartData, filepath string) error {
+	logger := log.WithFields(log.Fields{
+		"package":  "PythonChartRenderer",
+		"function": "Render",
+	})
+
+	if chartData.Options.Style == ChartStyleSparkline {
+		if renderer.SparklineRenderer == nil {
+			return fmt.Errorf("could not render sparkline - %s, no sparkline renderer", chartData.StockSymbol)
+		}
+		return renderer.SparklineRenderer.Render(ctx, chartData, filepath)
+	}
+
+	width, height, dpi := chartData.Options.GetImageSize()
+	theme := chartData.Options.Theme
+	if len(theme) == 0 {
+		theme = ChartThemeLight
+	}
+
+	args := []string{
+		chartData.StockSymbol,
+		string(chartData.Period),
+		string(chartData.Interval),
+		filepath,
+		string(chartData.Options.Style),
+		strconv.FormatBool(chartData.Options.Volume),
+		chartData.Options.Indicators.String(),
+		strings.Join(chartData.Options.CompareSymbols, ","),
+		strconv.Itoa(width),
+		strconv.Itoa(height),
+		strconv.Itoa(dpi),
+		string(theme),
+		chartData.Options.UpColor,
+		chartData.Options.DownColor,
+	}
+
+	// extended hours are not shaded on comparison charts
+	sessionBoundaries := ""
+	if len(chartData.Options.CompareSymbols) == 0 && hasExtendedSessions(renderer.TimeService, chartData.StockSymbol, chartData.Interval) {
+		sessionBoundaries = getSessionBoundaries(renderer.TimeService)
+	}
+	args = append(args, sessionBoundaries, strconv.FormatBool(chartData.Options.Events))
+
+	_, err := renderer.executeScript(ctx, renderer.ScriptPath, args)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+	return nil
+}
+
+func (renderer *PythonChartRenderer) executeScript(ctx context.Context, bin string, args []string) ([]byte, error) {
+	logger := log.WithFields(log.Fields{
+		"package":  "PythonChartRenderer",
+		"function": "executeScript",
+	})
+
+	logger.Infof("Executing exec (%s) with arguments (%v)", bin, args)
+	command := exec.CommandContext(ctx, bin, args...)
+	output, err := command.CombinedOutput()
+	if err != nil && ctx.Err() != nil {
+		// the process is killed by cancellation
+		logger.Errorf("exec stopped: %v\nCommand: %s\nArguments: %s\n", ctx.Err(), bin, args)
+		return nil, fmt.Errorf("exec stopped: %w", ctx.Err())
+	}
+
+	if err != nil {
+		logger.Errorf("exec failed: %v\nCommand: %s\nArguments: %s\nOutput: %s\n", err, bin, args, string(output))
+		return nil, fmt.Errorf("exec failed: %v\nCommand: %s\nArguments: %s\nOutput: %s", err, bin, args, string(output))
+	}
+	return output, err
+}
@@ -0,0 +1,63 @@
+package finance_svc
+
+import "strings"
+
+// hasExtendedSessions returns true if an intraday chart of the symbol shows pre-market and
+// after-market bars, which TimeSVC defines for the US market only
+func hasExtendedSessions(timeService *TimeSVC, symbol string, interval ChartInterval) bool {
+	if timeService == nil || !isIntradayInterval(interval) {
+		return false
+	}
+	return timeService.GetMarketSession(symbol) == timeService.DefaultMarketSession
+}
+
+// getSessionBoundaries returns pre-market start, market start, market end and after-market end
+// in new york time, e.g., 07:00,09:30,16:00,17:00
+func getSessionBoundaries(timeService *TimeSVC) string {
+	boundaries := []string{
+		timeService.PreMarketStartTime.Format("15:04"),
+		timeService.MarketStartTime.Format("15:04"),
+		timeService.AfterMarketStartTime.Format("15:04"),
+		timeService.AfterMarketEndTime.Format("15:04"),
+	}
+	return strings.Join(boundaries, ",")
+}
+
+// getBarSessions returns the market session of each bar
+func (renderer *GoChartRenderer) getBarSessions(data *OHLCVData) []MarketType {
+	sessions := make([]MarketType, len(data.Bars))
+	for idx, bar := range data.Bars {
+		sessions[idx] = renderer.TimeService.GetMarketType(bar.Time)
+	}
+	return sessions
+}
+
+// drawSessions shades bars outside market hours and marks the open and close
+func (renderer *GoChartRenderer) drawSessions(canvas chartCanvas, theme *chartTheme, pane *chartPane, sessions []MarketType) {
+	if len(sessions) == 0 {
+		return
+	}
+
+	// left edge of the bar at idx
+	edge := func(idx int) float64 {
+		return pane.Left + pane.Width*float64(idx)/float64(len(sessions))
+	}
+
+	start := 0
+	for idx := 1; idx <= len(sessions); idx++ {
+		if idx < len(sessions) && sessions[idx] == sessions[start] {
+			continue
+		}
+
+		if sessions[start] != DayMarket {
+			canvas.FillRect(edge(start), pane.Top, edge(idx)-edge(start), pane.Height, theme.Session)
+		}
+
+		// open or close between two runs
+		if idx < len(sessions) && (sessions[start] == DayMarket || sessions[idx] == DayMarket) {
+			x := edge(idx)
+			canvas.StrokeLine([]chartPoint{{X: x, Y: pane.Top}, {X: x, Y: pane.Bottom()}}, 1, theme.SessionMark)
+		}
+		start = idx
+	}
+}
@@ -0,0 +1,154 @@
+package finance_svc
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	chartRenewalQueueSize = 1024
+)
+
+// ChartRenewalTask is a chart waiting for or under renewal
+type ChartRenewalTask struct {
+	Key         string
+	Chart       StockChartData
+	EnqueueTime time.Time
+	// StartTime is zero while the task is waiting in the queue
+	StartTime time.Time
+}
+
+// ChartRenewalScheduler renders queued charts with a bounded number of workers.
+// A chart is queued at most once until its render finishes, so slow renewals
+// do not pile up across ticks.
+type ChartRenewalScheduler struct {
+	Workers    int
+	RenderFunc func(ctx context.Context, chartData *StockChartData) error
+
+	Queue       chan string
+	Pending     map[string]*ChartRenewalTask
+	PendingLock sync.Mutex
+	// Context is cancelled when the scheduler stops
+	Context context.Context
+	Cancel  context.CancelFunc
+}
+
+// NewChartRenewalScheduler creates a ChartRenewalScheduler and starts its workers
+func NewChartRenewalScheduler(workers int, renderFunc func(ctx context.Context, chartData *StockChartData) error) *ChartRenewalScheduler {
+	if workers <= 0 {
+		workers = 1
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	scheduler := &ChartRenewalScheduler{
+		Workers:    workers,
+		RenderFunc: renderFunc,
+		Queue:      make(chan string, chartRenewalQueueSize),
+		Pending:    map[string]*ChartRenewalTask{},
+		Context:    ctx,
+		Cancel:     cancel,
+	}
+
+	for i := 0; i < workers; i++ {
+		go scheduler.runWorker()
+	}
+
+	return scheduler
+}
+
+// Stop stops workers and cancels renders in progress
+func (scheduler *ChartRenewalScheduler) Stop() {
+	scheduler.Cancel()
+}
+
+// Enqueue queues a chart for renewal, returns false if the chart is already pending
+func (scheduler *ChartRenewalScheduler) Enqueue(key string, chartData *StockChartData) bool {
+	logger := log.WithFields(log.Fields{
+		"package":  "ChartRenewalScheduler",
+		"function": "Enqueue",
+	})
+
+	scheduler.PendingLock.Lock()
+	defer scheduler.PendingLock.Unlock()
+
+	if _, ok := scheduler.Pending[key]; ok {
+		return false
+	}
+
+	select {
+	case scheduler.Queue <- key:
+		scheduler.Pending[key] = &ChartRenewalTask{
+			Key:         key,
+			Chart:       *chartData,
+			EnqueueTime: time.Now(),
+		}
+		return true
+	default:
+		logger.Warnf("Renewal queue is full, dropping chart %s", key)
+		return false
+	}
+}
+
+// GetPendingTasks returns charts waiting for or under renewal, oldest first
+func (scheduler *ChartRenewalScheduler) GetPendingTasks() []ChartRenewalTask {
+	scheduler.PendingLock.Lock()
+	defer scheduler.PendingLock.Unlock()
+
+	tasks := make([]ChartRenewalTask, 0, len(scheduler.Pending))
+	for _, task := range scheduler.Pending {
+		tasks = append(tasks, *task)
+	}
+
+	sort.Slice(tasks, func(i int, j int) bool {
+		return tasks[i].EnqueueTime.Before(tasks[j].EnqueueTime)
+	})
+	return tasks
+}
+
+func (scheduler *ChartRenewalScheduler) runWorker() {
+	for {
+		select {
+		case <-scheduler.Context.Done():
+			return
+		case key := <-scheduler.Queue:
+			scheduler.runTask(key)
+		}
+	}
+}
+
+func (scheduler *ChartRenewalScheduler) runTask(key string) {
+	scheduler.PendingLock.Lock()
+	task, ok := scheduler.Pending[key]
+	if !ok {
+		scheduler.PendingLock.Unlock()
+		return
+	}
+	task.StartTime = time.Now()
+	chartData := task.Chart
+	scheduler.PendingLock.Unlock()
+
+	// the render deadline is applied by the render itself
+	err := scheduler.RenderFunc(scheduler.Context, &chartData)
+	scheduler.finishTask(key, err)
+}
+
+func (scheduler *ChartRenewalScheduler) finishTask(key string, err error) {
+	logger := log.WithFields(log.Fields{
+		"package":  "ChartRenewalScheduler",
+		"function": "finishTask",
+	})
+
+	if err != nil {
+		logger.Error(fmt.Errorf("could not renew chart %s - %v", key, err))
+	}
+
+	scheduler.PendingLock.Lock()
+	delete(scheduler.Pending, key)
+	scheduler.PendingLock.Unlock()
+}
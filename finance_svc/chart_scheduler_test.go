@@ -0,0 +1,111 @@
+package finance_svc
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestChartRenewalScheduler creates a scheduler with a worker rendering by render
+func newTestChartRenewalScheduler(render func(chartData *StockChartData) error) *ChartRenewalScheduler {
+	return NewChartRenewalScheduler(1, func(ctx context.Context, chartData *StockChartData) error {
+		return render(chartData)
+	})
+}
+
+func TestChartRenewalSchedulerDedup(t *testing.T) {
+	tests := []struct {
+		name            string
+		keys            []string
+		expectedQueued  []bool
+		expectedRenders map[string]int
+	}{
+		{"single", []string{"AAPL"}, []bool{true}, map[string]int{"AAPL": 1}},
+		{"pending twice", []string{"AAPL", "AAPL"}, []bool{true, false}, map[string]int{"AAPL": 1}},
+		{"others queued", []string{"AAPL", "MSFT", "AAPL", "MSFT", "TSLA"}, []bool{true, true, false, false, true}, map[string]int{"AAPL": 1, "MSFT": 1, "TSLA": 1}},
+	}
+
+	for _, test := range tests {
+		release := make(chan struct{})
+		renders := map[string]int{}
+		rendersLock := sync.Mutex{}
+
+		scheduler := newTestChartRenewalScheduler(func(chartData *StockChartData) error {
+			<-release
+
+			rendersLock.Lock()
+			defer rendersLock.Unlock()
+			renders[chartData.StockSymbol]++
+			return nil
+		})
+
+		for idx, key := range test.keys {
+			queued := scheduler.Enqueue(key, &StockChartData{StockSymbol: key})
+			if queued != test.expectedQueued[idx] {
+				t.Fatalf("%s: expected queued %v for %s at %d, got %v", test.name, test.expectedQueued[idx], key, idx, queued)
+			}
+		}
+
+		close(release)
+		waitForChartRenewals(t, scheduler)
+		scheduler.Stop()
+
+		rendersLock.Lock()
+		for key, count := range test.expectedRenders {
+			if renders[key] != count {
+				t.Fatalf("%s: expected %d renders of %s, got %d", test.name, count, key, renders[key])
+			}
+		}
+		rendersLock.Unlock()
+	}
+}
+
+func TestChartRenewalSchedulerRequeue(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	scheduler := newTestChartRenewalScheduler(func(chartData *StockChartData) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	})
+	defer scheduler.Stop()
+
+	if !scheduler.Enqueue("AAPL", &StockChartData{StockSymbol: "AAPL"}) {
+		t.Fatal("expected the chart queued")
+	}
+
+	// a chart under renewal is still pending
+	<-started
+	if scheduler.Enqueue("AAPL", &StockChartData{StockSymbol: "AAPL"}) {
+		t.Fatal("expected the chart under renewal not queued again")
+	}
+
+	tasks := scheduler.GetPendingTasks()
+	if len(tasks) != 1 || tasks[0].StartTime.IsZero() {
+		t.Fatalf("expected a task under renewal, got %+v", tasks)
+	}
+
+	release <- struct{}{}
+	waitForChartRenewals(t, scheduler)
+
+	// charts are queued again once renewed
+	if !scheduler.Enqueue("AAPL", &StockChartData{StockSymbol: "AAPL"}) {
+		t.Fatal("expected the renewed chart queued again")
+	}
+	<-started
+	release <- struct{}{}
+	waitForChartRenewals(t, scheduler)
+}
+
+// waitForChartRenewals waits until no chart is pending
+func waitForChartRenewals(t *testing.T, scheduler *ChartRenewalScheduler) {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if len(scheduler.GetPendingTasks()) == 0 {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("charts are not renewed")
+}
@@ -0,0 +1,174 @@
+package finance_svc
+
+import (
+	"context"
+	"image/color"
+	"math"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultSparklineWidth  = 120
+	defaultSparklineHeight = 32
+	sparklineMinSize       = 16
+	sparklineMaxSize       = 600
+	sparklinePadding       = 2
+)
+
+// ParseSparklineSize converts a string to a sparkline width or height in pixels, 0 if empty
+func ParseSparklineSize(size string) (int, error) {
+	if len(size) == 0 {
+		return 0, nil
+	}
+
+	value, err := strconv.Atoi(size)
+	if err != nil || value < sparklineMinSize || value > sparklineMaxSize {
+		return 0, newInvalidChartParamError("sparkline size must be between %d and %d pixels - %s", sparklineMinSize, sparklineMaxSize, size)
+	}
+	return value, nil
+}
+
+// validateSparklineOptions checks if a sparkline can be drawn with the options
+func validateSparklineOptions(options ChartOptions) error {
+	if options.Volume || !options.Indicators.IsEmpty() || len(options.CompareSymbols) > 0 || options.Events {
+		return newInvalidChartParamError("sparklines do not support volume, indicators, comparison or events")
+	}
+
+	for _, size := range []int{options.Width, options.Height} {
+		if size != 0 {
+			if _, err := ParseSparklineSize(strconv.Itoa(size)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// GetSparklineImage returns a small trend line of the symbol colored by the day's direction.
+// Sparklines are always drawn by the go renderer.
+func (svc *ChartSVC) GetSparklineImage(ctx context.Context, symbol string, period ChartPeriod, interval ChartInterval, options ChartOptions) (*ChartImage, error) {
+	options.Style = ChartStyleSparkline
+	if options.Width <= 0 {
+		options.Width = defaultSparklineWidth
+	}
+	if options.Height <= 0 {
+		options.Height = defaultSparklineHeight
+	}
+
+	return svc.GetChartImage(ctx, symbol, period, interval, options)
+}
+
+// drawSparkline draws a close line over the whole canvas without axes or labels, and a
+// baseline at the reference price
+func (renderer *GoChartRenderer) drawSparkline(canvas chartCanvas, theme *chartTheme, data *OHLCVData, reference float64) {
+	if len(data.Bars) == 0 {
+		return
+	}
+
+	width, height := canvas.Size()
+
+	minValue, maxValue := renderer.getPriceRange(data, ChartStyleLine)
+	minValue = math.Min(minValue, reference)
+	maxValue = math.Max(maxValue, reference)
+
+	pane := &chartPane{
+		Left:     sparklinePadding,
+		Top:      sparklinePadding,
+		Width:    width - sparklinePadding*2,
+		Height:   height - sparklinePadding*2,
+		BarCount: len(data.Bars),
+		MinValue: minValue,
+		MaxValue: maxValue,
+	}
+
+	lineColor := renderer.getSparklineColor(theme, data, reference)
+
+	baselineY := pane.Y(reference)
+	canvas.StrokeLine([]chartPoint{{X: pane.Left, Y: baselineY}, {X: pane.Left + pane.Width, Y: baselineY}}, 1, theme.Grid)
+
+	points := renderer.getClosePoints(pane, data)
+	if len(points) == 1 {
+		// a single bar is drawn as a dot
+		canvas.FillRect(points[0].X-1, points[0].Y-1, 2, 2, lineColor)
+		return
+	}
+	canvas.StrokeLine(points, 1.5, lineColor)
+}
+
+// getSparklineReference returns the close of the session before the day of the last bar.
+// Intraday bars of a single day are extended back to earlier sessions, and the first open
+// is returned if no earlier session is found.
+func (renderer *GoChartRenderer) getSparklineReference(ctx context.Context, data *OHLCVData) float64 {
+	logger := log.WithFields(log.Fields{
+		"package":  "GoChartRenderer",
+		"function": "getSparklineReference",
+	})
+
+	if len(data.Bars) == 0 {
+		return 0
+	}
+
+	lastTime := data.Bars[len(data.Bars)-1].Time
+	if reference, ok := renderer.getPreviousClose(data, lastTime); ok {
+		return reference
+	}
+
+	if isIntradayInterval(data.Interval) {
+		// five days reach the previous session over weekends and holidays
+		longerData, err := renderer.getOHLCV(ctx, data.Symbol, ChartPeriod5Day, data.Interval)
+		if err != nil {
+			logger.Warn(err)
+		} else if reference, ok := renderer.getPreviousClose(longerData, lastTime); ok {
+			return reference
+		}
+	}
+	return data.Bars[0].Open
+}
+
+// getPreviousClose returns the last close of the market session before the day of t,
+// pre-market and after-market bars are not closes of a session
+func (renderer *GoChartRenderer) getPreviousClose(data *OHLCVData, t time.Time) (float64, bool) {
+	extended := hasExtendedSessions(renderer.TimeService, data.Symbol, data.Interval)
+	for idx := len(data.Bars) - 1; idx >= 0; idx-- {
+		bar := data.Bars[idx]
+		if !bar.Time.Before(t) || isSameDay(bar.Time, t) {
+			continue
+		}
+
+		if extended && renderer.TimeService.GetMarketType(bar.Time) != DayMarket {
+			continue
+		}
+		return bar.AdjClose, true
+	}
+	return 0, false
+}
+
+// getSparklineColor returns the up color if the last price of the market session is not below
+// the reference, as the price change of a quote. After-market bars do not change the color.
+func (renderer *GoChartRenderer) getSparklineColor(theme *chartTheme, data *OHLCVData, reference float64) color.Color {
+	last := data.Bars[len(data.Bars)-1]
+	current := last.AdjClose
+
+	if hasExtendedSessions(renderer.TimeService, data.Symbol, data.Interval) {
+		for idx := len(data.Bars) - 1; idx >= 0 && isSameDay(data.Bars[idx].Time, last.Time); idx-- {
+			if renderer.TimeService.GetMarketType(data.Bars[idx].Time) == DayMarket {
+				current = data.Bars[idx].AdjClose
+				break
+			}
+		}
+	}
+
+	if current < reference {
+		return theme.Down
+	}
+	return theme.Up
+}
+
+func isSameDay(t1 time.Time, t2 time.Time) bool {
+	year1, month1, day1 := t1.Date()
+	year2, month2, day2 := t2.In(t1.Location()).Date()
+	return year1 == year2 && month1 == month2 && day1 == day2
+}
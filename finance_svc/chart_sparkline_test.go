@@ -0,0 +1,119 @@
+package finance_svc
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestGetSparklineReference(t *testing.T) {
+	timeService, err := InitTimeSVC()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Thu, Mar 5 and Fri, Mar 6, 2020
+	newyork := func(date int, hour int, min int) time.Time {
+		return time.Date(2020, 3, date, hour, min, 0, 0, timeService.NewYorkLocation)
+	}
+	seoulLocation, err := time.LoadLocation("Asia/Seoul")
+	if err != nil {
+		t.Fatal(err)
+	}
+	seoul := func(date int, hour int, min int) time.Time {
+		return time.Date(2020, 3, date, hour, min, 0, 0, seoulLocation)
+	}
+	bar := func(barTime time.Time, open float64, close float64) OHLCV {
+		return OHLCV{Time: barTime, Open: open, High: close, Low: close, Close: close, AdjClose: close}
+	}
+
+	thursday := []OHLCV{bar(newyork(5, 7, 0), 99, 99), bar(newyork(5, 9, 30), 101, 101), bar(newyork(5, 15, 55), 101, 100), bar(newyork(5, 16, 30), 95, 90)}
+	friday := []OHLCV{bar(newyork(6, 7, 0), 95, 95), bar(newyork(6, 9, 30), 96, 97), bar(newyork(6, 15, 55), 97, 98)}
+
+	intradayData := &OHLCVData{Symbol: "AAPL", Interval: ChartInteval5Min, Bars: append(append([]OHLCV{}, thursday...), friday...)}
+	newData := &OHLCVData{Symbol: "NEW", Interval: ChartInteval5Min, Bars: friday}
+	renderer := newTestGoChartRenderer(t, []*OHLCVData{intradayData, newData}, nil)
+
+	tests := []struct {
+		name     string
+		data     *OHLCVData
+		expected float64
+	}{
+		// bars of the last day are extended back to the close of thursday, not its after-market price
+		{"previous close", &OHLCVData{Symbol: "AAPL", Interval: ChartInteval5Min, Bars: friday}, 100},
+		{"two days", intradayData, 100},
+		{"no earlier session", &OHLCVData{Symbol: "NEW", Interval: ChartInteval5Min, Bars: friday}, 95},
+		{
+			"daily",
+			&OHLCVData{Symbol: "AAPL", Interval: ChartInteval1Day, Bars: []OHLCV{bar(newyork(4, 0, 0), 90, 91), bar(newyork(5, 0, 0), 91, 93), bar(newyork(6, 0, 0), 93, 92)}},
+			93,
+		},
+		// markets without extended hours close with their last bar
+		{
+			"KRX",
+			&OHLCVData{Symbol: "005930.KS", Interval: ChartInteval5Min, Bars: []OHLCV{bar(seoul(5, 15, 20), 60100, 60000), bar(seoul(6, 9, 0), 59000, 59500)}},
+			60000,
+		},
+	}
+
+	for _, test := range tests {
+		reference := renderer.getSparklineReference(context.Background(), test.data)
+		if reference != test.expected {
+			t.Fatalf("%s: expected reference %v, got %v", test.name, test.expected, reference)
+		}
+	}
+}
+
+func TestGetSparklineColor(t *testing.T) {
+	timeService, err := InitTimeSVC()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	renderer := &GoChartRenderer{TimeService: timeService}
+	theme := getChartTheme(ChartOptions{})
+
+	// Fri, Mar 6, 2020
+	newyork := func(hour int, min int) time.Time {
+		return time.Date(2020, 3, 6, hour, min, 0, 0, timeService.NewYorkLocation)
+	}
+	bars := func(closes map[time.Time]float64, times ...time.Time) []OHLCV {
+		result := []OHLCV{}
+		for _, barTime := range times {
+			result = append(result, OHLCV{Time: barTime, Close: closes[barTime], AdjClose: closes[barTime]})
+		}
+		return result
+	}
+
+	preMarket, marketOpen, marketClose, afterMarket := newyork(7, 0), newyork(9, 30), newyork(15, 55), newyork(16, 30)
+
+	tests := []struct {
+		name     string
+		symbol   string
+		bars     []OHLCV
+		expected string
+	}{
+		{"up", "AAPL", bars(map[time.Time]float64{preMarket: 99, marketOpen: 101, marketClose: 102}, preMarket, marketOpen, marketClose), "up"},
+		{"down", "AAPL", bars(map[time.Time]float64{preMarket: 101, marketOpen: 99, marketClose: 98}, preMarket, marketOpen, marketClose), "down"},
+		// the color follows the session marketClose, as the price change of the quote
+		{"up, after-market down", "AAPL", bars(map[time.Time]float64{marketOpen: 101, marketClose: 102, afterMarket: 97}, marketOpen, marketClose, afterMarket), "up"},
+		{"down, after-market up", "AAPL", bars(map[time.Time]float64{marketOpen: 99, marketClose: 98, afterMarket: 103}, marketOpen, marketClose, afterMarket), "down"},
+		{"pre-market only", "AAPL", bars(map[time.Time]float64{preMarket: 99}, preMarket), "down"},
+		{"unchanged", "AAPL", bars(map[time.Time]float64{marketClose: 100}, marketClose), "up"},
+		{"no extended hours", "BTC-USD", bars(map[time.Time]float64{marketClose: 102, afterMarket: 97}, marketClose, afterMarket), "down"},
+	}
+
+	for _, test := range tests {
+		data := &OHLCVData{Symbol: test.symbol, Interval: ChartInteval5Min, Bars: test.bars}
+
+		expectedColor := theme.Up
+		if test.expected == "down" {
+			expectedColor = theme.Down
+		}
+
+		lineColor := renderer.getSparklineColor(&theme, data, 100)
+		if lineColor != expectedColor {
+			t.Fatalf("%s: expected %s color %v, got %v", test.name, test.expected, expectedColor, lineColor)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package finance_svc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+// ChartRenderStats is render statistics of a tracked chart
+type ChartRenderStats struct {
+	RenderCount int
+	// LastDuration is how long the last render took, failed or not
+	LastDuration  time.Duration
+	ErrorCount    int
+	LastError     string
+	LastErrorTime time.Time
+	// FileSize is the size of the chart file after the last successful render
+	FileSize int64
+}
+
+// TrackedChart is a chart kept renewed by ChartSVC
+type TrackedChart struct {
+	Key   string
+	Chart StockChartData
+	// Expiration is when the chart stops being renewed unless requested again
+	Expiration time.Time
+	// Pending is true if the chart is waiting for or under renewal
+	Pending bool
+}
+
+// updateChartRenderStats records a render of a tracked chart without extending its expiration.
+// Renders cancelled because nobody waits for them are not counted.
+func (svc *ChartSVC) updateChartRenderStats(filename string, duration time.Duration, renderErr error) {
+	if renderErr != nil && errors.Is(renderErr, context.Canceled) {
+		return
+	}
+
+	svc.ChartsLock.Lock()
+	defer svc.ChartsLock.Unlock()
+
+	cached, expiration, ok := svc.Charts.GetWithExpiration(filename)
+	if !ok {
+		return
+	}
+
+	// entries are shared with readers, so replace instead of modifying
+	chartData := *cached.(*StockChartData)
+	chartData.Stats.RenderCount++
+	chartData.Stats.LastDuration = duration
+
+	if renderErr != nil {
+		chartData.FailureCount++
+		chartData.LastFailureTime = time.Now()
+		chartData.Stats.ErrorCount++
+		chartData.Stats.LastError = renderErr.Error()
+		chartData.Stats.LastErrorTime = chartData.LastFailureTime
+	} else {
+		chartData.FailureCount = 0
+		chartData.LastRenderTime = time.Now()
+		if stat, err := os.Stat(chartData.LocalFilePath); err == nil {
+			chartData.Stats.FileSize = stat.Size()
+		}
+	}
+
+	svc.Charts.Set(filename, &chartData, time.Until(expiration))
+	svc.RegistryDirty = true
+}
+
+// GetTrackedCharts returns all tracked charts with their render statistics, sorted by key
+func (svc *ChartSVC) GetTrackedCharts() []TrackedChart {
+	pending := map[string]bool{}
+	for _, task := range svc.GetPendingRenewals() {
+		pending[task.Key] = true
+	}
+
+	charts := []TrackedChart{}
+	for key, item := range svc.Charts.Items() {
+		charts = append(charts, TrackedChart{
+			Key:        key,
+			Chart:      *item.Object.(*StockChartData),
+			Expiration: time.Unix(0, item.Expiration),
+			Pending:    pending[key],
+		})
+	}
+
+	sort.Slice(charts, func(i int, j int) bool {
+		return charts[i].Key < charts[j].Key
+	})
+	return charts
+}
+
+// ForceRenewChart queues a tracked chart for renewal regardless of its staleness
+func (svc *ChartSVC) ForceRenewChart(key string) error {
+	cached, ok := svc.Charts.Get(key)
+	if !ok {
+		return fmt.Errorf("chart is not tracked - %s", key)
+	}
+
+	if !svc.RenewalScheduler.Enqueue(key, cached.(*StockChartData)) {
+		return fmt.Errorf("chart is already pending or the renewal queue is full - %s", key)
+	}
+	return nil
+}
+
+// EvictChart stops tracking a chart and removes its file
+func (svc *ChartSVC) EvictChart(key string) error {
+	svc.ChartsLock.Lock()
+	cached, ok := svc.Charts.Get(key)
+	if ok {
+		svc.Charts.Delete(key)
+		svc.RegistryDirty = true
+	}
+	svc.ChartsLock.Unlock()
+
+	if !ok {
+		return fmt.Errorf("chart is not tracked - %s", key)
+	}
+
+	if !svc.removeChartFile(cached.(*StockChartData).LocalFilePath) {
+		return fmt.Errorf("could not remove chart file - %s", key)
+	}
+	return nil
+}
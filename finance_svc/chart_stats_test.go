@@ -0,0 +1,167 @@
+package finance_svc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	cache "github.com/patrickmn/go-cache"
+)
+
+// newStatsTestChartSVC creates a service tracking charts of the symbols, their files are in dir
+func newStatsTestChartSVC(t *testing.T, dir string, symbols ...string) *ChartSVC {
+	svc := newRefreshTestChartSVC(t)
+	svc.Charts = cache.New(cache.NoExpiration, cache.NoExpiration)
+
+	for _, symbol := range symbols {
+		key := svc.makeChartFileName(symbol, ChartPeriod1Month, ChartInteval1Day, ChartOptions{})
+		svc.Charts.Set(key, &StockChartData{
+			StockSymbol:   symbol,
+			Period:        ChartPeriod1Month,
+			Interval:      ChartInteval1Day,
+			LocalFilePath: filepath.Join(dir, key),
+		}, time.Hour)
+	}
+	return svc
+}
+
+func TestUpdateChartRenderStats(t *testing.T) {
+	dir := t.TempDir()
+	svc := newStatsTestChartSVC(t, dir, "AAPL")
+
+	key := svc.makeChartFileName("AAPL", ChartPeriod1Month, ChartInteval1Day, ChartOptions{})
+	_, expiration, _ := svc.Charts.GetWithExpiration(key)
+
+	getStats := func() ChartRenderStats {
+		cached, ok := svc.Charts.Get(key)
+		if !ok {
+			t.Fatal("chart is not tracked")
+		}
+		return cached.(*StockChartData).Stats
+	}
+
+	err := ioutil.WriteFile(filepath.Join(dir, key), make([]byte, 1234), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	svc.updateChartRenderStats(key, 2*time.Second, nil)
+	stats := getStats()
+	if stats.RenderCount != 1 || stats.LastDuration != 2*time.Second || stats.FileSize != 1234 || stats.ErrorCount != 0 {
+		t.Fatalf("expected a render of 1234 bytes, got %+v", stats)
+	}
+
+	// failed renders keep the size of the last file
+	svc.updateChartRenderStats(key, 3*time.Second, errors.New("no data"))
+	stats = getStats()
+	if stats.RenderCount != 2 || stats.LastDuration != 3*time.Second || stats.FileSize != 1234 || stats.ErrorCount != 1 || stats.LastError != "no data" || stats.LastErrorTime.IsZero() {
+		t.Fatalf("expected a failed render, got %+v", stats)
+	}
+
+	// renders nobody waits for are not counted
+	svc.updateChartRenderStats(key, time.Second, fmt.Errorf("could not get history - %w", context.Canceled))
+	if getStats().RenderCount != 2 || getStats().ErrorCount != 1 {
+		t.Fatalf("expected a cancelled render not to be counted, got %+v", getStats())
+	}
+
+	// statistics do not extend the expiration
+	_, updatedExpiration, _ := svc.Charts.GetWithExpiration(key)
+	if updatedExpiration.After(expiration.Add(time.Second)) {
+		t.Fatalf("expected expiration %v, got %v", expiration, updatedExpiration)
+	}
+
+	// untracked charts are not tracked by renders
+	svc.updateChartRenderStats("MSFT_1mo_1d.png", time.Second, nil)
+	if _, ok := svc.Charts.Get("MSFT_1mo_1d.png"); ok {
+		t.Fatal("untracked chart is tracked by its render")
+	}
+}
+
+func TestGetTrackedCharts(t *testing.T) {
+	svc := newStatsTestChartSVC(t, t.TempDir(), "TSLA", "AAPL", "^GSPC")
+
+	release := make(chan struct{})
+	svc.RenewalScheduler = newTestChartRenewalScheduler(func(chartData *StockChartData) error {
+		<-release
+		return nil
+	})
+	defer svc.RenewalScheduler.Stop()
+	defer close(release)
+
+	if err := svc.ForceRenewChart("TSLA_1mo_1d.png"); err != nil {
+		t.Fatal(err)
+	}
+
+	// a chart pending renewal is not queued again
+	if err := svc.ForceRenewChart("TSLA_1mo_1d.png"); err == nil {
+		t.Fatal("expected an error of the pending chart")
+	}
+
+	if err := svc.ForceRenewChart("MSFT_1mo_1d.png"); err == nil {
+		t.Fatal("expected an error of the untracked chart")
+	}
+
+	charts := svc.GetTrackedCharts()
+
+	expected := []struct {
+		key     string
+		pending bool
+	}{
+		{"AAPL_1mo_1d.png", false},
+		{"TSLA_1mo_1d.png", true},
+		{"idx_GSPC_1mo_1d.png", false},
+	}
+
+	if len(charts) != len(expected) {
+		t.Fatalf("expected %d charts, got %d", len(expected), len(charts))
+	}
+
+	for idx, chart := range charts {
+		if chart.Key != expected[idx].key || chart.Pending != expected[idx].pending {
+			t.Fatalf("chart %d: expected %s pending %v, got %s pending %v", idx, expected[idx].key, expected[idx].pending, chart.Key, chart.Pending)
+		}
+
+		if time.Until(chart.Expiration) <= 0 || time.Until(chart.Expiration) > time.Hour {
+			t.Fatalf("%s: expected expiration in an hour, got %v", chart.Key, chart.Expiration)
+		}
+	}
+}
+
+func TestEvictChart(t *testing.T) {
+	dir := t.TempDir()
+	svc := newStatsTestChartSVC(t, dir, "AAPL", "MSFT")
+
+	// a chart without a file is evicted as well
+	path := filepath.Join(dir, "AAPL_1mo_1d.png")
+	err := ioutil.WriteFile(path, []byte("png"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"AAPL_1mo_1d.png", "MSFT_1mo_1d.png"} {
+		if err := svc.EvictChart(key); err != nil {
+			t.Fatalf("%s: %v", key, err)
+		}
+
+		if _, ok := svc.Charts.Get(key); ok {
+			t.Fatalf("%s: expected the chart not to be tracked", key)
+		}
+	}
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected the chart file to be removed, got %v", err)
+	}
+
+	if !svc.RegistryDirty {
+		t.Fatal("expected the registry to be saved")
+	}
+
+	if err := svc.EvictChart("AAPL_1mo_1d.png"); err == nil {
+		t.Fatal("expected an error of the untracked chart")
+	}
+}
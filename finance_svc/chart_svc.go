@@ -1,11 +1,12 @@
 package finance_svc
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"os"
-	"os/exec"
 	"strings"
+	"sync"
 	"time"
 
 	cache "github.com/patrickmn/go-cache"
@@ -16,13 +17,29 @@ const (
 	stockChartBin     = "exec/stock_chart.py"
 	stockChartFileDir = "charts"
 
-	stockMonitoringExpTime = 48 * time.Hour   // 2 days
-	stockMonitoringTickMin = 15 * time.Minute // 15 min
-	stockMonitoringTickDay = 30 * time.Minute // 30 min
+	stockChartTempFilePrefix = "tmp_"
+	// stockSymbolIndexPrefix replaces the caret of index symbols in file names
+	stockSymbolIndexPrefix = "idx_"
+	// stockChartCompareSeparator joins comparison symbols in file names
+	stockChartCompareSeparator = "~"
+
+	stockMonitoringExpTime = 48 * time.Hour // 2 days
+
+	defaultChartRenewalWorkers  = 2
+	defaultChartRenderTimeout   = 2 * time.Minute   // 2 min
+	defaultChartDiskBudget      = 512 * 1024 * 1024 // 512MB
+	defaultChartCleanupTick     = 10 * time.Minute  // 10 min
+	defaultChartIntradayRefresh = 5 * time.Minute   // 5 min
+
+	defaultChartWidth  = 640
+	defaultChartHeight = 480
+	defaultChartDPI    = 100
 )
 
 type ChartPeriod string
 type ChartInterval string
+type ChartStyle string
+type ChartFormat string
 
 const (
 	ChartPeriodMax    ChartPeriod = "max"
@@ -45,37 +62,210 @@ const (
 	ChartInteval1Week  ChartInterval = "1wk"
 	ChartInteval1Month ChartInterval = "1mo"
 	ChartInteval3Month ChartInterval = "3mo"
+
+	ChartStyleLine        ChartStyle = "line"
+	ChartStyleCandlestick ChartStyle = "candle"
+	ChartStyleOHLC        ChartStyle = "ohlc"
+	ChartStyleArea        ChartStyle = "area"
+	// ChartStyleSparkline is a label-free trend line, requested through GetSparklineImage
+	ChartStyleSparkline ChartStyle = "spark"
+
+	ChartFormatPNG ChartFormat = "png"
+	ChartFormatSVG ChartFormat = "svg"
 )
 
+// ChartOptions describes how a chart is drawn
+type ChartOptions struct {
+	Style      ChartStyle
+	Volume     bool
+	Format     ChartFormat
+	Indicators ChartIndicators
+	// CompareSymbols are drawn with the chart symbol as percent changes
+	CompareSymbols []string
+	// Events marks earnings dates, ex-dividend dates and splits
+	Events bool
+	// Width and Height are image size in pixels, 0 for the default size
+	Width  int
+	Height int
+	// DPI scales texts and lines, 0 for the default DPI
+	DPI   int
+	Theme ChartTheme
+	// UpColor and DownColor are hex colors of up and down moves, empty for the theme colors
+	UpColor   string
+	DownColor string
+}
+
+// DefaultChartOptions returns options of a plain price line chart
+func DefaultChartOptions() ChartOptions {
+	return ChartOptions{
+		Style:  ChartStyleLine,
+		Volume: false,
+		Format: ChartFormatPNG,
+		Theme:  ChartThemeLight,
+	}
+}
+
+// GetImageSize returns width, height and DPI of the chart image, filling defaults
+func (options ChartOptions) GetImageSize() (int, int, int) {
+	width := options.Width
+	if width <= 0 {
+		width = defaultChartWidth
+	}
+
+	height := options.Height
+	if height <= 0 {
+		height = defaultChartHeight
+	}
+
+	dpi := options.DPI
+	if dpi <= 0 {
+		dpi = defaultChartDPI
+	}
+	return width, height, dpi
+}
+
+// ParseChartFormat converts a string to ChartFormat
+func ParseChartFormat(format string) (ChartFormat, error) {
+	switch ChartFormat(strings.ToLower(format)) {
+	case "":
+		return ChartFormatPNG, nil
+	case ChartFormatPNG, ChartFormatSVG:
+		return ChartFormat(strings.ToLower(format)), nil
+	default:
+		return "", newInvalidChartParamError("unknown chart format - %s", format)
+	}
+}
+
+// GetContentType returns MIME type of the chart format
+func (format ChartFormat) GetContentType() string {
+	switch format {
+	case ChartFormatSVG:
+		return "image/svg+xml"
+	default:
+		return "image/png"
+	}
+}
+
 type StockChartData struct {
 	StockSymbol   string
 	Period        ChartPeriod
 	Interval      ChartInterval
+	Options       ChartOptions
 	LocalFilePath string
+	// LastRenderTime is when the chart file was rendered last
+	LastRenderTime time.Time
+	// FailureCount is the number of renders failed in a row since the last successful render
+	FailureCount int
+	// LastFailureTime is when a render failed last
+	LastFailureTime time.Time
+	Stats           ChartRenderStats
+}
+
+// ParseChartStyle converts a string to ChartStyle
+func ParseChartStyle(style string) (ChartStyle, error) {
+	switch ChartStyle(style) {
+	case "":
+		return ChartStyleLine, nil
+	case ChartStyleLine, ChartStyleCandlestick, ChartStyleOHLC, ChartStyleArea:
+		return ChartStyle(style), nil
+	default:
+		return "", newInvalidChartParamError("unknown chart style - %s", style)
+	}
+}
+
+// ChartSVCConfig ...
+type ChartSVCConfig struct {
+	// RenewalWorkers is the number of charts renewed concurrently
+	RenewalWorkers int
+	// RenderTimeout is the max time of a chart render
+	RenderTimeout time.Duration
+	// DiskBudget is the max total size of chart files in bytes, 0 for no limit
+	DiskBudget int64
+	// CleanupInterval is how often chart files are cleaned up
+	CleanupInterval time.Duration
+	// IntradayRefresh is how often intraday charts are renewed while their market is open
+	IntradayRefresh time.Duration
+}
+
+// DefaultChartSVCConfig returns a default ChartSVCConfig
+func DefaultChartSVCConfig() *ChartSVCConfig {
+	return &ChartSVCConfig{
+		RenewalWorkers:  defaultChartRenewalWorkers,
+		RenderTimeout:   defaultChartRenderTimeout,
+		DiskBudget:      defaultChartDiskBudget,
+		CleanupInterval: defaultChartCleanupTick,
+		IntradayRefresh: defaultChartIntradayRefresh,
+	}
 }
 
 // ChartSVC ...
 type ChartSVC struct {
+	Config      *ChartSVCConfig
 	TimeService *TimeSVC
+	Renderer    ChartRenderer
+	// RenderGroup coalesces concurrent renders of the same chart
+	RenderGroup *chartRenderGroup
+	// Context is cancelled when the service closes
+	Context context.Context
+	Cancel  context.CancelFunc
+	// RenewalScheduler renews tracked charts in background
+	RenewalScheduler *ChartRenewalScheduler
 	// Charts to be monitored
-	Charts              *cache.Cache
-	MonitoringTickerMin *time.Ticker
-	MonitoringTickerDay *time.Ticker
-	MonitoringDone      chan bool
+	Charts *cache.Cache
+	// ChartsLock serializes updates of chart cache entries
+	ChartsLock     sync.Mutex
+	RegistryDirty  bool
+	RefreshTicker  *time.Ticker
+	RegistryTicker *time.Ticker
+	CleanupTicker  *time.Ticker
+	MonitoringDone chan bool
 }
 
-func InitChartSVC(timeService *TimeSVC) (*ChartSVC, error) {
+func InitChartSVC(timeService *TimeSVC, renderer ChartRenderer, config *ChartSVCConfig) (*ChartSVC, error) {
+	logger := log.WithFields(log.Fields{
+		"package":  "ChartSVC",
+		"function": "InitChartSVC",
+	})
+
+	if config == nil {
+		config = DefaultChartSVCConfig()
+	}
+
+	cleanupInterval := config.CleanupInterval
+	if cleanupInterval <= 0 {
+		cleanupInterval = defaultChartCleanupTick
+	}
+
 	chartCache := cache.New(stockMonitoringExpTime, stockMonitoringExpTime)
-	tickerMin := time.NewTicker(stockMonitoringTickMin)
-	tickerDay := time.NewTicker(stockMonitoringTickDay)
+	tickerRefresh := time.NewTicker(stockChartRefreshTick)
+	tickerRegistry := time.NewTicker(stockChartRegistrySaveTick)
+	tickerCleanup := time.NewTicker(cleanupInterval)
 	done := make(chan bool)
+	ctx, cancel := context.WithCancel(context.Background())
 
 	chartSvc := &ChartSVC{
-		TimeService:         timeService,
-		Charts:              chartCache,
-		MonitoringTickerMin: tickerMin,
-		MonitoringTickerDay: tickerDay,
-		MonitoringDone:      done,
+		Config:         config,
+		TimeService:    timeService,
+		Renderer:       renderer,
+		RenderGroup:    newChartRenderGroup(ctx, config.RenderTimeout),
+		Context:        ctx,
+		Cancel:         cancel,
+		Charts:         chartCache,
+		RefreshTicker:  tickerRefresh,
+		RegistryTicker: tickerRegistry,
+		CleanupTicker:  tickerCleanup,
+		MonitoringDone: done,
+	}
+
+	chartSvc.RenewalScheduler = NewChartRenewalScheduler(config.RenewalWorkers, chartSvc.renewChart)
+
+	// restore charts tracked before restart
+	loadedCharts, err := chartSvc.loadChartRegistry()
+	if err != nil {
+		logger.Error(err)
+	} else if len(loadedCharts) > 0 {
+		logger.Infof("Restored %d charts from registry", len(loadedCharts))
+		chartSvc.renewStaleCharts()
 	}
 
 	go func() {
@@ -83,15 +273,20 @@ func InitChartSVC(timeService *TimeSVC) (*ChartSVC, error) {
 			select {
 			case <-done:
 				return
-			case <-tickerMin.C:
+			case <-tickerRefresh.C:
 				// tick
-				if timeService.GetMarketType(time.Now()) != Overnight {
-					go chartSvc.renewChartsMinutes()
+				chartSvc.renewStaleCharts()
+			case <-tickerRegistry.C:
+				// tick
+				err := chartSvc.saveChartRegistryIfDirty()
+				if err != nil {
+					logger.Error(err)
 				}
-			case <-tickerDay.C:
+			case <-tickerCleanup.C:
 				// tick
-				if timeService.GetMarketType(time.Now()) != Overnight {
-					chartSvc.renewChartsDays()
+				err := chartSvc.cleanupCharts()
+				if err != nil {
+					logger.Error(err)
 				}
 			}
 		}
@@ -102,36 +297,115 @@ func InitChartSVC(timeService *TimeSVC) (*ChartSVC, error) {
 
 // Close ...
 func (svc *ChartSVC) Close() error {
-	svc.Charts.Flush()
+	logger := log.WithFields(log.Fields{
+		"package":  "ChartSVC",
+		"function": "Close",
+	})
 
-	svc.MonitoringTickerMin.Stop()
-	svc.MonitoringTickerDay.Stop()
+	svc.RefreshTicker.Stop()
+	svc.RegistryTicker.Stop()
+	svc.CleanupTicker.Stop()
 	svc.MonitoringDone <- true
+	svc.RenewalScheduler.Stop()
+	// cancel renders in progress
+	svc.Cancel()
+
+	err := svc.saveChartRegistry()
+	if err != nil {
+		logger.Error(err)
+	}
+
+	svc.Charts.Flush()
 	return nil
 }
 
-// GetChartData ...
-func (svc *ChartSVC) GetChartData(symbol string, period ChartPeriod, interval ChartInterval) ([]byte, error) {
+// ChartImage is a rendered chart with its cache validators
+type ChartImage struct {
+	Data []byte
+	// ModTime is when the chart file was written
+	ModTime time.Time
+	// MaxAge is how long clients may cache the chart before it may be renewed
+	MaxAge time.Duration
+}
+
+// GetChartData returns a chart image, rendering it if not cached.
+// Rendering stops when ctx is cancelled or the render deadline passes.
+func (svc *ChartSVC) GetChartData(ctx context.Context, symbol string, period ChartPeriod, interval ChartInterval, options ChartOptions) ([]byte, error) {
+	image, err := svc.GetChartImage(ctx, symbol, period, interval, options)
+	if err != nil {
+		return nil, err
+	}
+	return image.Data, nil
+}
+
+// GetChartImage returns a chart image with its modification time and max age, rendering it if not cached
+func (svc *ChartSVC) GetChartImage(ctx context.Context, symbol string, period ChartPeriod, interval ChartInterval, options ChartOptions) (*ChartImage, error) {
 	logger := log.WithFields(log.Fields{
 		"package":  "ChartSVC",
-		"function": "GetChartData",
+		"function": "GetChartImage",
 	})
 
-	err := svc.RequestChart(symbol, period, interval)
+	symbol, err := ParseStockSymbol(symbol)
 	if err != nil {
 		logger.Error(err)
 		return nil, err
 	}
 
-	if chartCache, ok := svc.getChartCache(symbol, period, interval); ok {
-		return ioutil.ReadFile(chartCache.LocalFilePath)
-	} else {
-		return nil, fmt.Errorf("could not get chart cache - %s, %s, %s", symbol, period, interval)
+	err = svc.RequestChart(ctx, symbol, period, interval, options)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	chartCache, ok := svc.getChartCache(symbol, period, interval, options)
+	if !ok {
+		return nil, fmt.Errorf("could not get chart cache - %s, %s, %s, %s", symbol, period, interval, options.Style)
+	}
+
+	file, err := os.Open(chartCache.LocalFilePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ioutil.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ChartImage{
+		Data:    data,
+		ModTime: stat.ModTime(),
+		MaxAge:  svc.getChartMaxAge(chartCache, time.Now()),
+	}, nil
+}
+
+// GetCompareChartData returns a chart comparing percent changes of symbols
+func (svc *ChartSVC) GetCompareChartData(ctx context.Context, symbols []string, period ChartPeriod, interval ChartInterval, options ChartOptions) ([]byte, error) {
+	image, err := svc.GetCompareChartImage(ctx, symbols, period, interval, options)
+	if err != nil {
+		return nil, err
 	}
+	return image.Data, nil
+}
+
+// GetCompareChartImage returns a chart comparing percent changes of symbols with its modification time and max age
+func (svc *ChartSVC) GetCompareChartImage(ctx context.Context, symbols []string, period ChartPeriod, interval ChartInterval, options ChartOptions) (*ChartImage, error) {
+	if len(symbols) < 2 {
+		return nil, newInvalidChartParamError("comparison needs at least 2 symbols")
+	}
+
+	options.CompareSymbols = symbols[1:]
+	return svc.GetChartImage(ctx, symbols[0], period, interval, options)
 }
 
 // RequestChart ...
-func (svc *ChartSVC) RequestChart(symbol string, period ChartPeriod, interval ChartInterval) error {
+func (svc *ChartSVC) RequestChart(ctx context.Context, symbol string, period ChartPeriod, interval ChartInterval, options ChartOptions) error {
 	logger := log.WithFields(log.Fields{
 		"package":  "ChartSVC",
 		"function": "RequestChart",
@@ -139,16 +413,23 @@ func (svc *ChartSVC) RequestChart(symbol string, period ChartPeriod, interval Ch
 
 	logger.Infof("Request chart %s", symbol)
 
-	if _, ok := svc.getChartCache(symbol, period, interval); !ok {
-		return svc.makeChart(symbol, period, interval, true)
+	// cache keys and file names use the normalized symbol, e.g., " aapl" to AAPL
+	symbol, err := ValidateChartRequest(symbol, period, interval, options)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	if _, ok := svc.getChartCache(symbol, period, interval, options); !ok {
+		return svc.makeChart(ctx, symbol, period, interval, options, true)
 	} else {
-		svc.renewChartCache(symbol, period, interval)
+		svc.renewChartCache(symbol, period, interval, options)
 		return nil
 	}
 }
 
-func (svc *ChartSVC) getChartCache(symbol string, period ChartPeriod, interval ChartInterval) (*StockChartData, bool) {
-	filename := svc.makeChartFileName(symbol, period, interval)
+func (svc *ChartSVC) getChartCache(symbol string, period ChartPeriod, interval ChartInterval, options ChartOptions) (*StockChartData, bool) {
+	filename := svc.makeChartFileName(symbol, period, interval, options)
 	data, ok := svc.Charts.Get(filename)
 	if ok {
 		return data.(*StockChartData), ok
@@ -157,79 +438,89 @@ func (svc *ChartSVC) getChartCache(symbol string, period ChartPeriod, interval C
 	}
 }
 
-func (svc *ChartSVC) renewChartCache(symbol string, period ChartPeriod, interval ChartInterval) {
-	filename := svc.makeChartFileName(symbol, period, interval)
+func (svc *ChartSVC) renewChartCache(symbol string, period ChartPeriod, interval ChartInterval, options ChartOptions) {
+	filename := svc.makeChartFileName(symbol, period, interval, options)
 	filepath := fmt.Sprintf("%s/%s", stockChartFileDir, filename)
 
 	chartData := StockChartData{
 		StockSymbol:   symbol,
 		Period:        period,
 		Interval:      interval,
+		Options:       options,
 		LocalFilePath: filepath,
 	}
 
+	svc.ChartsLock.Lock()
+	defer svc.ChartsLock.Unlock()
+
+	if cached, ok := svc.Charts.Get(filename); ok {
+		chartData.LastRenderTime = cached.(*StockChartData).LastRenderTime
+		chartData.FailureCount = cached.(*StockChartData).FailureCount
+		chartData.LastFailureTime = cached.(*StockChartData).LastFailureTime
+		chartData.Stats = cached.(*StockChartData).Stats
+	}
+
 	// renew cache
 	svc.Charts.SetDefault(filename, &chartData)
+	svc.RegistryDirty = true
 }
 
-func (svc *ChartSVC) renewCharts() {
-	logger := log.WithFields(log.Fields{
-		"package":  "ChartSVC",
-		"function": "renewCharts",
-	})
+// GetPendingRenewals returns charts waiting for or under renewal
+func (svc *ChartSVC) GetPendingRenewals() []ChartRenewalTask {
+	return svc.RenewalScheduler.GetPendingTasks()
+}
 
-	for _, item := range svc.Charts.Items() {
-		chartData := item.Object.(*StockChartData)
-		err := svc.makeChart(chartData.StockSymbol, chartData.Period, chartData.Interval, false)
-		if err != nil {
-			logger.Error(err)
-		}
-	}
+// renewChart re-renders a tracked chart, called by RenewalScheduler
+func (svc *ChartSVC) renewChart(ctx context.Context, chartData *StockChartData) error {
+	return svc.makeChart(ctx, chartData.StockSymbol, chartData.Period, chartData.Interval, chartData.Options, false)
 }
 
-func (svc *ChartSVC) renewChartsMinutes() {
+// makeChart renders a chart, concurrent calls for the same chart share a single render
+func (svc *ChartSVC) makeChart(ctx context.Context, symbol string, period ChartPeriod, interval ChartInterval, options ChartOptions, updateCache bool) error {
 	logger := log.WithFields(log.Fields{
 		"package":  "ChartSVC",
-		"function": "renewChartsMinutes",
+		"function": "makeChart",
 	})
 
-	for _, item := range svc.Charts.Items() {
-		chartData := item.Object.(*StockChartData)
-		if svc.isShortInterval(chartData.Interval) {
-			err := svc.makeChart(chartData.StockSymbol, chartData.Period, chartData.Interval, false)
-			if err != nil {
-				logger.Error(err)
-			}
+	filename := svc.makeChartFileName(symbol, period, interval, options)
+
+	// the render function only runs for the call starting the render
+	cacheRenewed := false
+	err := svc.RenderGroup.Do(ctx, filename, func(renderCtx context.Context) error {
+		startTime := time.Now()
+		renderErr := svc.renderChart(renderCtx, symbol, period, interval, options)
+		if renderErr == nil && updateCache {
+			// track the chart before recording its first render
+			svc.renewChartCache(symbol, period, interval, options)
+			cacheRenewed = true
 		}
-	}
-}
 
-func (svc *ChartSVC) renewChartsDays() {
-	logger := log.WithFields(log.Fields{
-		"package":  "ChartSVC",
-		"function": "renewChartsDays",
+		svc.updateChartRenderStats(filename, time.Since(startTime), renderErr)
+		return renderErr
 	})
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
 
-	for _, item := range svc.Charts.Items() {
-		chartData := item.Object.(*StockChartData)
-		if svc.isLongInterval(chartData.Interval) {
-			err := svc.makeChart(chartData.StockSymbol, chartData.Period, chartData.Interval, false)
-			if err != nil {
-				logger.Error(err)
-			}
-		}
+	if updateCache && !cacheRenewed {
+		// joined a render started by another call, e.g., a renewal
+		svc.renewChartCache(symbol, period, interval, options)
 	}
+	return nil
 }
 
-func (svc *ChartSVC) makeChart(symbol string, period ChartPeriod, interval ChartInterval, updateCache bool) error {
+// renderChart renders a chart to a temp file and renames it to the chart file,
+// so readers never see a partially written chart
+func (svc *ChartSVC) renderChart(ctx context.Context, symbol string, period ChartPeriod, interval ChartInterval, options ChartOptions) error {
 	logger := log.WithFields(log.Fields{
 		"package":  "ChartSVC",
-		"function": "makeChart",
+		"function": "renderChart",
 	})
 
 	logger.Infof("Make chart %s", symbol)
 
-	filename := svc.makeChartFileName(symbol, period, interval)
+	filename := svc.makeChartFileName(symbol, period, interval, options)
 	filepath := fmt.Sprintf("%s/%s", stockChartFileDir, filename)
 
 	err := os.MkdirAll(stockChartFileDir, 0766)
@@ -238,57 +529,123 @@ func (svc *ChartSVC) makeChart(symbol string, period ChartPeriod, interval Chart
 		return err
 	}
 
-	args := []string{
-		symbol,
-		string(period),
-		string(interval),
-		filepath,
+	// keep the extension as renderers may choose the image format by it
+	tempFile, err := ioutil.TempFile(stockChartFileDir, stockChartTempFilePrefix+"*_"+filename)
+	if err != nil {
+		logger.Error(err)
+		return err
 	}
 
-	_, err = svc.executeScript(stockChartBin, args)
+	tempFilePath := tempFile.Name()
+	tempFile.Close()
+
+	chartData := StockChartData{
+		StockSymbol:   symbol,
+		Period:        period,
+		Interval:      interval,
+		Options:       options,
+		LocalFilePath: filepath,
+	}
+
+	err = svc.Renderer.Render(ctx, &chartData, tempFilePath)
+	if err != nil {
+		// the temp file may have a partially written chart, the last chart is kept
+		os.Remove(tempFilePath)
+		logger.Error(err)
+		return err
+	}
+
+	err = os.Chmod(tempFilePath, 0644)
 	if err != nil {
+		os.Remove(tempFilePath)
 		logger.Error(err)
 		return err
 	}
 
-	if updateCache {
-		// renew chart cache
-		svc.renewChartCache(symbol, period, interval)
+	err = os.Rename(tempFilePath, filepath)
+	if err != nil {
+		os.Remove(tempFilePath)
+		logger.Error(err)
+		return err
 	}
 	return nil
 }
 
-func (svc *ChartSVC) isShortInterval(interval ChartInterval) bool {
-	if interval == ChartInteval1Min || interval == ChartInteval5Min {
+// isIntradayInterval returns true if bars of the interval are shorter than a day
+func isIntradayInterval(interval ChartInterval) bool {
+	switch interval {
+	case ChartInteval1Min, ChartInteval5Min, ChartInteval30Min, ChartInteval1Hour:
 		return true
+	default:
+		return false
 	}
-	return false
 }
 
-func (svc *ChartSVC) isLongInterval(interval ChartInterval) bool {
-	if interval == ChartInteval1Min || interval == ChartInteval5Min {
-		return false
+func (svc *ChartSVC) makeChartFileName(symbol string, period ChartPeriod, interval ChartInterval, options ChartOptions) string {
+	safeSymbol := makeSafeSymbol(symbol)
+	format := options.Format
+	if len(format) == 0 {
+		format = ChartFormatPNG
 	}
-	return true
+	return fmt.Sprintf("%s_%s_%s%s.%s", safeSymbol, period, interval, svc.makeChartOptionsSuffix(options), format)
 }
 
-func (svc *ChartSVC) makeChartFileName(symbol string, period ChartPeriod, interval ChartInterval) string {
-	safeSymbol := strings.TrimPrefix(symbol, "^")
-	return fmt.Sprintf("%s_%s_%s.png", safeSymbol, period, interval)
+// makeSafeSymbol returns the symbol usable in file names, the caret of indexes is escaped
+// so ^XYZ and XYZ do not share a file, e.g., ^GSPC to idx_GSPC
+func makeSafeSymbol(symbol string) string {
+	if strings.HasPrefix(symbol, "^") {
+		return stockSymbolIndexPrefix + strings.TrimPrefix(symbol, "^")
+	}
+	return symbol
 }
 
-func (svc *ChartSVC) executeScript(bin string, args []string) ([]byte, error) {
-	logger := log.WithFields(log.Fields{
-		"package":  "ChartSVC",
-		"function": "executeScript",
-	})
+// makeChartOptionsSuffix returns a file name suffix for non-default options
+func (svc *ChartSVC) makeChartOptionsSuffix(options ChartOptions) string {
+	suffix := ""
+	if len(options.Style) > 0 && options.Style != ChartStyleLine {
+		suffix += fmt.Sprintf("_%s", options.Style)
+	}
 
-	logger.Infof("Executing exec (%s) with arguments (%v)", bin, args)
-	command := exec.Command(bin, args...)
-	output, err := command.CombinedOutput()
-	if err != nil {
-		logger.Errorf("exec failed: %v\nCommand: %s\nArguments: %s\nOutput: %s\n", err, bin, args, string(output))
-		return nil, fmt.Errorf("exec failed: %v\nCommand: %s\nArguments: %s\nOutput: %s", err, bin, args, string(output))
+	if options.Volume {
+		suffix += "_vol"
+	}
+
+	if options.Events {
+		suffix += "_ev"
+	}
+
+	if !options.Indicators.IsEmpty() {
+		suffix += fmt.Sprintf("_%s", options.Indicators)
+	}
+
+	if len(options.CompareSymbols) > 0 {
+		safeSymbols := make([]string, 0, len(options.CompareSymbols))
+		for _, symbol := range options.CompareSymbols {
+			safeSymbols = append(safeSymbols, makeSafeSymbol(symbol))
+		}
+		// symbols may contain '-', e.g., BTC-USD, so they are joined with a character symbols never have
+		suffix += fmt.Sprintf("_vs_%s", strings.Join(safeSymbols, stockChartCompareSeparator))
+	}
+
+	width, height, dpi := options.GetImageSize()
+	if width != defaultChartWidth || height != defaultChartHeight {
+		suffix += fmt.Sprintf("_%dx%d", width, height)
+	}
+
+	if dpi != defaultChartDPI {
+		suffix += fmt.Sprintf("_dpi%d", dpi)
+	}
+
+	if len(options.Theme) > 0 && options.Theme != ChartThemeLight {
+		suffix += fmt.Sprintf("_%s", options.Theme)
+	}
+
+	if len(options.UpColor) > 0 {
+		suffix += fmt.Sprintf("_up%s", options.UpColor)
+	}
+
+	if len(options.DownColor) > 0 {
+		suffix += fmt.Sprintf("_down%s", options.DownColor)
 	}
-	return output, err
+	return suffix
 }
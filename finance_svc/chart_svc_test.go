@@ -0,0 +1,119 @@
+package finance_svc
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMakeChartFileName(t *testing.T) {
+	svc := &ChartSVC{}
+
+	tests := []struct {
+		symbol   string
+		options  ChartOptions
+		expected string
+	}{
+		{"AAPL", ChartOptions{}, "AAPL_1y_1d.png"},
+		{"AAPL", ChartOptions{Format: ChartFormatSVG}, "AAPL_1y_1d.svg"},
+		// indexes must not share files with tickers of the same name
+		{"^GSPC", ChartOptions{}, "idx_GSPC_1y_1d.png"},
+		{"GSPC", ChartOptions{}, "GSPC_1y_1d.png"},
+		// comparison symbols may contain '-', so they are not joined with it
+		{"AAPL", ChartOptions{CompareSymbols: []string{"BTC-USD"}}, "AAPL_1y_1d_vs_BTC-USD.png"},
+		{"AAPL", ChartOptions{CompareSymbols: []string{"BTC", "USD"}}, "AAPL_1y_1d_vs_BTC~USD.png"},
+		{"AAPL", ChartOptions{CompareSymbols: []string{"^GSPC", "MSFT"}}, "AAPL_1y_1d_vs_idx_GSPC~MSFT.png"},
+		{"AAPL", ChartOptions{CompareSymbols: []string{"GSPC", "MSFT"}}, "AAPL_1y_1d_vs_GSPC~MSFT.png"},
+	}
+
+	for _, test := range tests {
+		filename := svc.makeChartFileName(test.symbol, ChartPeriod1Year, ChartInteval1Day, test.options)
+		if filename != test.expected {
+			t.Fatalf("%s: expected %s, got %s", test.symbol, test.expected, filename)
+		}
+	}
+}
+
+// stubChartRenderer writes data to the chart file and fails with err
+type stubChartRenderer struct {
+	data []byte
+	err  error
+}
+
+// GetType ...
+func (renderer *stubChartRenderer) GetType() ChartRendererType {
+	return ChartRendererType("stub")
+}
+
+// Render ...
+func (renderer *stubChartRenderer) Render(ctx context.Context, chartData *StockChartData, filepath string) error {
+	err := ioutil.WriteFile(filepath, renderer.data, 0644)
+	if err != nil {
+		return err
+	}
+	return renderer.err
+}
+
+func TestRenderChart(t *testing.T) {
+	workDir, err := ioutil.TempDir("", "chart_svc_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(workDir)
+
+	// charts are stored relative to the working dir
+	prevDir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.Chdir(workDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(prevDir)
+
+	tests := []struct {
+		name     string
+		renderer *stubChartRenderer
+		expected string
+	}{
+		{"rendered", &stubChartRenderer{data: []byte("chart")}, "chart"},
+		// a truncated chart does not replace the last one
+		{"failed", &stubChartRenderer{data: []byte("cha"), err: errors.New("short write")}, "chart"},
+		{"rendered again", &stubChartRenderer{data: []byte("new chart")}, "new chart"},
+	}
+
+	svc := &ChartSVC{}
+	path := filepath.Join(stockChartFileDir, svc.makeChartFileName("AAPL", ChartPeriod1Year, ChartInteval1Day, ChartOptions{}))
+	for _, test := range tests {
+		svc.Renderer = test.renderer
+
+		err := svc.renderChart(context.Background(), "AAPL", ChartPeriod1Year, ChartInteval1Day, ChartOptions{})
+		if (err == nil) != (test.renderer.err == nil) {
+			t.Fatalf("%s: expected error %v, got %v", test.name, test.renderer.err, err)
+		}
+
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		if string(data) != test.expected {
+			t.Fatalf("%s: expected chart %q, got %q", test.name, test.expected, string(data))
+		}
+
+		// temp files are removed whether the render fails or not
+		entries, err := ioutil.ReadDir(stockChartFileDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(entries) != 1 {
+			t.Fatalf("%s: expected the chart file only, got %d files", test.name, len(entries))
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package finance_svc
+
+import (
+	"fmt"
+	"image/color"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type ChartTheme string
+
+const (
+	ChartThemeLight ChartTheme = "light"
+	ChartThemeDark  ChartTheme = "dark"
+)
+
+var (
+	// e.g., 26a69a or #26a69a
+	chartColorRegexp = regexp.MustCompile(`^#?[0-9a-fA-F]{6}$`)
+)
+
+// chartTheme is a set of colors a chart is drawn with
+type chartTheme struct {
+	Background    color.Color
+	Axis          color.Color
+	Grid          color.Color
+	Text          color.Color
+	Line          color.Color
+	Legend        color.Color
+	Area          color.Color
+	Up            color.Color
+	Down          color.Color
+	BollingerFill color.Color
+	// Session shades extended hours, SessionMark marks the open and close
+	Session     color.Color
+	SessionMark color.Color
+}
+
+var (
+	goChartLightTheme = chartTheme{
+		Background:    color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+		Axis:          color.RGBA{R: 0x33, G: 0x33, B: 0x33, A: 0xff},
+		Grid:          color.RGBA{R: 0xe0, G: 0xe0, B: 0xe0, A: 0xff},
+		Text:          color.RGBA{R: 0x33, G: 0x33, B: 0x33, A: 0xff},
+		Line:          color.RGBA{R: 0x1f, G: 0x77, B: 0xb4, A: 0xff},
+		Legend:        color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xc0},
+		Area:          color.NRGBA{R: 0x1f, G: 0x77, B: 0xb4, A: 0x40},
+		Up:            color.RGBA{R: 0x26, G: 0xa6, B: 0x9a, A: 0xff},
+		Down:          color.RGBA{R: 0xef, G: 0x53, B: 0x50, A: 0xff},
+		BollingerFill: color.NRGBA{R: 0x7f, G: 0x7f, B: 0x7f, A: 0x20},
+		Session:       color.NRGBA{R: 0x7f, G: 0x7f, B: 0x7f, A: 0x1c},
+		SessionMark:   color.RGBA{R: 0xa0, G: 0xa0, B: 0xa0, A: 0xff},
+	}
+
+	goChartDarkTheme = chartTheme{
+		Background:    color.RGBA{R: 0x13, G: 0x17, B: 0x22, A: 0xff},
+		Axis:          color.RGBA{R: 0xb2, G: 0xb5, B: 0xbe, A: 0xff},
+		Grid:          color.RGBA{R: 0x2a, G: 0x2e, B: 0x39, A: 0xff},
+		Text:          color.RGBA{R: 0xd1, G: 0xd4, B: 0xdc, A: 0xff},
+		Line:          color.RGBA{R: 0x42, G: 0xa5, B: 0xf5, A: 0xff},
+		Legend:        color.NRGBA{R: 0x13, G: 0x17, B: 0x22, A: 0xc0},
+		Area:          color.NRGBA{R: 0x42, G: 0xa5, B: 0xf5, A: 0x40},
+		Up:            color.RGBA{R: 0x26, G: 0xa6, B: 0x9a, A: 0xff},
+		Down:          color.RGBA{R: 0xef, G: 0x53, B: 0x50, A: 0xff},
+		BollingerFill: color.NRGBA{R: 0xb2, G: 0xb5, B: 0xbe, A: 0x20},
+		Session:       color.NRGBA{R: 0xb2, G: 0xb5, B: 0xbe, A: 0x14},
+		SessionMark:   color.RGBA{R: 0x5d, G: 0x60, B: 0x6b, A: 0xff},
+	}
+)
+
+// ParseChartTheme converts a string to ChartTheme
+func ParseChartTheme(theme string) (ChartTheme, error) {
+	switch ChartTheme(strings.ToLower(theme)) {
+	case "":
+		return ChartThemeLight, nil
+	case ChartThemeLight, ChartThemeDark:
+		return ChartTheme(strings.ToLower(theme)), nil
+	default:
+		return "", newInvalidChartParamError("unknown chart theme - %s", theme)
+	}
+}
+
+// ParseChartColor validates a hex color, e.g., 26a69a, and returns it in lower case without '#'
+func ParseChartColor(hexColor string) (string, error) {
+	if len(hexColor) == 0 {
+		return "", nil
+	}
+
+	if !chartColorRegexp.MatchString(hexColor) {
+		return "", newInvalidChartParamError("invalid chart color - %s, must be a hex color like 26a69a", hexColor)
+	}
+	return strings.ToLower(strings.TrimPrefix(hexColor, "#")), nil
+}
+
+// getChartTheme returns colors for the chart options
+func getChartTheme(options ChartOptions) chartTheme {
+	theme := goChartLightTheme
+	if options.Theme == ChartThemeDark {
+		theme = goChartDarkTheme
+	}
+
+	if c, err := parseHexColor(options.UpColor); err == nil {
+		theme.Up = c
+	}
+
+	if c, err := parseHexColor(options.DownColor); err == nil {
+		theme.Down = c
+	}
+	return theme
+}
+
+func parseHexColor(hexColor string) (color.Color, error) {
+	value, err := strconv.ParseUint(strings.TrimPrefix(hexColor, "#"), 16, 32)
+	if err != nil || len(strings.TrimPrefix(hexColor, "#")) != 6 {
+		return nil, fmt.Errorf("invalid hex color - %s", hexColor)
+	}
+
+	return color.RGBA{
+		R: uint8(value >> 16),
+		G: uint8(value >> 8),
+		B: uint8(value),
+		A: 0xff,
+	}, nil
+}
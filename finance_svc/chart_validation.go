@@ -0,0 +1,290 @@
+package finance_svc
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	stockSymbolMaxLength = 16
+	compareMaxSymbols    = 6
+
+	chartMinSize = 100
+	chartMaxSize = 2000
+	chartMinDPI  = 50
+	chartMaxDPI  = 300
+)
+
+var (
+	// e.g., SOXL, ^GSPC, BTC-USD, DX-Y.NYB, ES=F, 005930.KS
+	stockSymbolRegexp = regexp.MustCompile(`^\^?[A-Z0-9][A-Z0-9.\-=]*$`)
+
+	// approximate length of periods in days
+	chartPeriodDays = map[ChartPeriod]float64{
+		ChartPeriod1Day:   1,
+		ChartPeriod5Day:   5,
+		ChartPeriod1Month: 31,
+		ChartPeriod3Month: 92,
+		ChartPeriod6Month: 183,
+		ChartPeriod1Year:  366,
+		ChartPeriod2Year:  731,
+		ChartPeriod5Year:  1827,
+		ChartPeriod10Year: 3653,
+		ChartPeriodMax:    100000,
+	}
+
+	// length of intervals in days
+	chartIntervalDays = map[ChartInterval]float64{
+		ChartInteval1Min:   1.0 / (24 * 60),
+		ChartInteval5Min:   5.0 / (24 * 60),
+		ChartInteval30Min:  30.0 / (24 * 60),
+		ChartInteval1Hour:  1.0 / 24,
+		ChartInteval1Day:   1,
+		ChartInteval5Day:   5,
+		ChartInteval1Week:  7,
+		ChartInteval1Month: 30,
+		ChartInteval3Month: 91,
+	}
+
+	// intraday bars are only available for recent days
+	chartIntervalMaxPeriod = map[ChartInterval]ChartPeriod{
+		ChartInteval1Min:  ChartPeriod5Day,
+		ChartInteval5Min:  ChartPeriod1Month,
+		ChartInteval30Min: ChartPeriod1Month,
+		ChartInteval1Hour: ChartPeriod2Year,
+	}
+)
+
+// InvalidChartParamError is an error of an invalid chart request parameter
+type InvalidChartParamError struct {
+	Message string
+}
+
+// Error ...
+func (err *InvalidChartParamError) Error() string {
+	return err.Message
+}
+
+func newInvalidChartParamError(format string, args ...interface{}) error {
+	return &InvalidChartParamError{
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// IsInvalidChartParamError returns true if the error is caused by invalid chart parameters
+func IsInvalidChartParamError(err error) bool {
+	var paramErr *InvalidChartParamError
+	return errors.As(err, &paramErr)
+}
+
+// ParseStockSymbol validates a stock symbol and returns it in upper case
+func ParseStockSymbol(symbol string) (string, error) {
+	upperSymbol := strings.ToUpper(strings.TrimSpace(symbol))
+	if len(upperSymbol) == 0 {
+		return "", newInvalidChartParamError("empty stock symbol")
+	}
+
+	if len(upperSymbol) > stockSymbolMaxLength {
+		return "", newInvalidChartParamError("stock symbol is too long - %s", symbol)
+	}
+
+	if !stockSymbolRegexp.MatchString(upperSymbol) {
+		return "", newInvalidChartParamError("invalid stock symbol - %s", symbol)
+	}
+	return upperSymbol, nil
+}
+
+// ParseChartPeriod converts a string to ChartPeriod
+func ParseChartPeriod(period string) (ChartPeriod, error) {
+	chartPeriod := ChartPeriod(strings.ToLower(period))
+	if _, ok := chartPeriodDays[chartPeriod]; !ok {
+		return "", newInvalidChartParamError("unknown chart period - %s, must be one of %s", period, strings.Join(getChartPeriods(), ", "))
+	}
+	return chartPeriod, nil
+}
+
+// ParseChartInterval converts a string to ChartInterval
+func ParseChartInterval(interval string) (ChartInterval, error) {
+	chartInterval := ChartInterval(strings.ToLower(interval))
+	if _, ok := chartIntervalDays[chartInterval]; !ok {
+		return "", newInvalidChartParamError("unknown chart interval - %s, must be one of %s", interval, strings.Join(getChartIntervals(), ", "))
+	}
+	return chartInterval, nil
+}
+
+// ValidateChartPeriodInterval checks if bars of the interval can be drawn over the period
+func ValidateChartPeriodInterval(period ChartPeriod, interval ChartInterval) error {
+	periodDays, ok := chartPeriodDays[period]
+	if !ok {
+		return newInvalidChartParamError("unknown chart period - %s", period)
+	}
+
+	intervalDays, ok := chartIntervalDays[interval]
+	if !ok {
+		return newInvalidChartParamError("unknown chart interval - %s", interval)
+	}
+
+	if intervalDays >= periodDays {
+		return newInvalidChartParamError("chart interval %s must be shorter than chart period %s", interval, period)
+	}
+
+	if maxPeriod, ok := chartIntervalMaxPeriod[interval]; ok && periodDays > chartPeriodDays[maxPeriod] {
+		return newInvalidChartParamError("chart interval %s is only available for periods up to %s, got %s", interval, maxPeriod, period)
+	}
+	return nil
+}
+
+// ValidateChartRequest checks all parameters of a chart request and returns the normalized symbol
+func ValidateChartRequest(symbol string, period ChartPeriod, interval ChartInterval, options ChartOptions) (string, error) {
+	symbol, err := ParseStockSymbol(symbol)
+	if err != nil {
+		return "", err
+	}
+
+	if err := ValidateChartPeriodInterval(period, interval); err != nil {
+		return "", err
+	}
+
+	if options.Style == ChartStyleSparkline {
+		if err := validateSparklineOptions(options); err != nil {
+			return "", err
+		}
+	} else if _, err := ParseChartStyle(string(options.Style)); err != nil {
+		return "", err
+	}
+
+	if _, err := ParseChartFormat(string(options.Format)); err != nil {
+		return "", err
+	}
+
+	if len(options.CompareSymbols) > 0 {
+		if err := validateCompareOptions(symbol, options); err != nil {
+			return "", err
+		}
+	}
+
+	if err := validateImageOptions(options); err != nil {
+		return "", err
+	}
+	return symbol, nil
+}
+
+// ParseChartSize converts a string to an image width or height in pixels, 0 if empty
+func ParseChartSize(size string) (int, error) {
+	if len(size) == 0 {
+		return 0, nil
+	}
+
+	value, err := strconv.Atoi(size)
+	if err != nil || value < chartMinSize || value > chartMaxSize {
+		return 0, newInvalidChartParamError("chart size must be between %d and %d pixels - %s", chartMinSize, chartMaxSize, size)
+	}
+	return value, nil
+}
+
+// ParseChartDPI converts a string to an image DPI, 0 if empty
+func ParseChartDPI(dpi string) (int, error) {
+	if len(dpi) == 0 {
+		return 0, nil
+	}
+
+	value, err := strconv.Atoi(dpi)
+	if err != nil || value < chartMinDPI || value > chartMaxDPI {
+		return 0, newInvalidChartParamError("chart dpi must be between %d and %d - %s", chartMinDPI, chartMaxDPI, dpi)
+	}
+	return value, nil
+}
+
+// validateImageOptions checks size, DPI and colors of a chart image
+func validateImageOptions(options ChartOptions) error {
+	for _, size := range []int{options.Width, options.Height} {
+		// sparklines are checked by validateSparklineOptions
+		if size != 0 && options.Style != ChartStyleSparkline {
+			if _, err := ParseChartSize(strconv.Itoa(size)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if options.DPI != 0 {
+		if _, err := ParseChartDPI(strconv.Itoa(options.DPI)); err != nil {
+			return err
+		}
+	}
+
+	if _, err := ParseChartTheme(string(options.Theme)); err != nil {
+		return err
+	}
+
+	for _, hexColor := range []string{options.UpColor, options.DownColor} {
+		if _, err := ParseChartColor(hexColor); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseCompareSymbols converts comma separated symbols, e.g., "SOXL,SOXX", to a list
+func ParseCompareSymbols(symbols string) ([]string, error) {
+	result := []string{}
+	seen := map[string]bool{}
+	for _, field := range strings.Split(symbols, ",") {
+		if len(strings.TrimSpace(field)) == 0 {
+			continue
+		}
+
+		symbol, err := ParseStockSymbol(field)
+		if err != nil {
+			return nil, err
+		}
+
+		if !seen[symbol] {
+			seen[symbol] = true
+			result = append(result, symbol)
+		}
+	}
+
+	if len(result) < 2 || len(result) > compareMaxSymbols {
+		return nil, newInvalidChartParamError("comparison needs 2 to %d different symbols - %s", compareMaxSymbols, symbols)
+	}
+	return result, nil
+}
+
+// validateCompareOptions checks if a comparison chart can be drawn with the options
+func validateCompareOptions(symbol string, options ChartOptions) error {
+	if len(options.CompareSymbols)+1 > compareMaxSymbols {
+		return newInvalidChartParamError("comparison takes up to %d symbols", compareMaxSymbols)
+	}
+
+	for _, compareSymbol := range options.CompareSymbols {
+		if _, err := ParseStockSymbol(compareSymbol); err != nil {
+			return err
+		}
+
+		if compareSymbol == symbol {
+			return newInvalidChartParamError("duplicated symbol in comparison - %s", symbol)
+		}
+	}
+
+	if (len(options.Style) > 0 && options.Style != ChartStyleLine) || options.Volume || !options.Indicators.IsEmpty() || options.Events {
+		return newInvalidChartParamError("comparison charts do not support styles, volume, indicators or events")
+	}
+	return nil
+}
+
+func getChartPeriods() []string {
+	return []string{
+		string(ChartPeriod1Day), string(ChartPeriod5Day), string(ChartPeriod1Month), string(ChartPeriod3Month), string(ChartPeriod6Month),
+		string(ChartPeriod1Year), string(ChartPeriod2Year), string(ChartPeriod5Year), string(ChartPeriod10Year), string(ChartPeriodMax),
+	}
+}
+
+func getChartIntervals() []string {
+	return []string{
+		string(ChartInteval1Min), string(ChartInteval5Min), string(ChartInteval30Min), string(ChartInteval1Hour),
+		string(ChartInteval1Day), string(ChartInteval5Day), string(ChartInteval1Week), string(ChartInteval1Month), string(ChartInteval3Month),
+	}
+}
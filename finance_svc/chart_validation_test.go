@@ -0,0 +1,200 @@
+package finance_svc
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseStockSymbol(t *testing.T) {
+	tests := []struct {
+		symbol   string
+		expected string
+		valid    bool
+	}{
+		{"AAPL", "AAPL", true},
+		{"aapl", "AAPL", true},
+		{" soxl ", "SOXL", true},
+		// indices, currencies, futures and exchange suffixes
+		{"^GSPC", "^GSPC", true},
+		{"^ixic", "^IXIC", true},
+		{"BTC-USD", "BTC-USD", true},
+		{"DX-Y.NYB", "DX-Y.NYB", true},
+		{"ES=F", "ES=F", true},
+		{"KRW=X", "KRW=X", true},
+		{"005930.KS", "005930.KS", true},
+		{"ABCDEFGHIJKLMNOP", "ABCDEFGHIJKLMNOP", true},
+		{"ABCDEFGHIJKLMNOPQ", "", false},
+		{"", "", false},
+		{"  ", "", false},
+		// the caret only starts index symbols
+		{"^", "", false},
+		{"^^GSPC", "", false},
+		{"GS^PC", "", false},
+		{"-USD", "", false},
+		{"=F", "", false},
+		{".KS", "", false},
+		// symbols must not escape the chart directory
+		{"../AAPL", "", false},
+		{"..", "", false},
+		{"AAPL/../MSFT", "", false},
+		{"..%2FAAPL", "", false},
+		{"AAPL%2F..", "", false},
+		{"AAPL\\..", "", false},
+		{"AAPL MSFT", "", false},
+		{"AAPL,MSFT", "", false},
+		{"AAPL\x00", "", false},
+	}
+
+	for _, test := range tests {
+		symbol, err := ParseStockSymbol(test.symbol)
+		if (err == nil) != test.valid {
+			t.Fatalf("%q: expected valid %v, got error %v", test.symbol, test.valid, err)
+		}
+
+		if err != nil && !IsInvalidChartParamError(err) {
+			t.Fatalf("%q: expected an invalid parameter error, got %v", test.symbol, err)
+		}
+
+		if symbol != test.expected {
+			t.Fatalf("%q: expected %q, got %q", test.symbol, test.expected, symbol)
+		}
+	}
+}
+
+func TestValidateChartPeriodInterval(t *testing.T) {
+	// all rejected periods of each interval, other pairs are accepted
+	rejected := map[ChartInterval][]ChartPeriod{
+		// intraday bars are only available for recent days
+		ChartInteval1Min:  {ChartPeriod1Month, ChartPeriod3Month, ChartPeriod6Month, ChartPeriod1Year, ChartPeriod2Year, ChartPeriod5Year, ChartPeriod10Year, ChartPeriodMax},
+		ChartInteval5Min:  {ChartPeriod3Month, ChartPeriod6Month, ChartPeriod1Year, ChartPeriod2Year, ChartPeriod5Year, ChartPeriod10Year, ChartPeriodMax},
+		ChartInteval30Min: {ChartPeriod3Month, ChartPeriod6Month, ChartPeriod1Year, ChartPeriod2Year, ChartPeriod5Year, ChartPeriod10Year, ChartPeriodMax},
+		ChartInteval1Hour: {ChartPeriod5Year, ChartPeriod10Year, ChartPeriodMax},
+		// intervals must be shorter than periods
+		ChartInteval1Day:   {ChartPeriod1Day},
+		ChartInteval5Day:   {ChartPeriod1Day, ChartPeriod5Day},
+		ChartInteval1Week:  {ChartPeriod1Day, ChartPeriod5Day},
+		ChartInteval1Month: {ChartPeriod1Day, ChartPeriod5Day},
+		ChartInteval3Month: {ChartPeriod1Day, ChartPeriod5Day, ChartPeriod1Month},
+	}
+
+	for _, interval := range getChartIntervals() {
+		rejectedPeriods := map[ChartPeriod]bool{}
+		for _, period := range rejected[ChartInterval(interval)] {
+			rejectedPeriods[period] = true
+		}
+
+		for _, period := range getChartPeriods() {
+			err := ValidateChartPeriodInterval(ChartPeriod(period), ChartInterval(interval))
+			if (err == nil) == rejectedPeriods[ChartPeriod(period)] {
+				t.Fatalf("%s over %s: expected rejected %v, got error %v", interval, period, rejectedPeriods[ChartPeriod(period)], err)
+			}
+
+			if err != nil && !IsInvalidChartParamError(err) {
+				t.Fatalf("%s over %s: expected an invalid parameter error, got %v", interval, period, err)
+			}
+		}
+	}
+
+	// unknown values
+	for _, test := range []struct {
+		period   ChartPeriod
+		interval ChartInterval
+	}{
+		{"7d", ChartInteval1Day},
+		{"", ChartInteval1Day},
+		{ChartPeriod1Year, "2d"},
+		{ChartPeriod1Year, ""},
+	} {
+		err := ValidateChartPeriodInterval(test.period, test.interval)
+		if err == nil || !IsInvalidChartParamError(err) {
+			t.Fatalf("%q over %q: expected an invalid parameter error, got %v", test.interval, test.period, err)
+		}
+	}
+}
+
+func TestParseCompareSymbols(t *testing.T) {
+	tests := []struct {
+		name     string
+		symbols  string
+		expected []string
+		valid    bool
+	}{
+		{"two", "SOXL,SOXX", []string{"SOXL", "SOXX"}, true},
+		{"lower-case and spaces", " tqqq , qqq ", []string{"TQQQ", "QQQ"}, true},
+		{"index", "SPY,^GSPC", []string{"SPY", "^GSPC"}, true},
+		{"empty fields", "SOXL,,SOXX,", []string{"SOXL", "SOXX"}, true},
+		{"six", "A,B,C,D,E,F", []string{"A", "B", "C", "D", "E", "F"}, true},
+		// the same symbol is drawn once
+		{"duplicated", "SOXL,SOXX,soxl", []string{"SOXL", "SOXX"}, true},
+		{"duplicated only", "SOXL,soxl", nil, false},
+		{"one", "SOXL", nil, false},
+		{"empty", "", nil, false},
+		{"seven", "A,B,C,D,E,F,G", nil, false},
+		{"seven with a duplicate", "A,B,C,D,E,F,a", []string{"A", "B", "C", "D", "E", "F"}, true},
+		{"invalid symbol", "SOXL,../SOXX", nil, false},
+	}
+
+	for _, test := range tests {
+		symbols, err := ParseCompareSymbols(test.symbols)
+		if (err == nil) != test.valid {
+			t.Fatalf("%s: expected valid %v, got error %v", test.name, test.valid, err)
+		}
+
+		if err != nil && !IsInvalidChartParamError(err) {
+			t.Fatalf("%s: expected an invalid parameter error, got %v", test.name, err)
+		}
+
+		if strings.Join(symbols, ",") != strings.Join(test.expected, ",") {
+			t.Fatalf("%s: expected %v, got %v", test.name, test.expected, symbols)
+		}
+	}
+}
+
+func TestValidateCompareOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		symbol  string
+		options ChartOptions
+		valid   bool
+	}{
+		{"valid", "SOXL", ChartOptions{CompareSymbols: []string{"SOXX"}}, true},
+		{"line", "SOXL", ChartOptions{Style: ChartStyleLine, CompareSymbols: []string{"SOXX", "^SOX"}}, true},
+		{"duplicated", "SOXL", ChartOptions{CompareSymbols: []string{"SOXX", "SOXL"}}, false},
+		{"too many", "A", ChartOptions{CompareSymbols: []string{"B", "C", "D", "E", "F", "G"}}, false},
+		{"invalid symbol", "SOXL", ChartOptions{CompareSymbols: []string{"../SOXX"}}, false},
+		{"style", "SOXL", ChartOptions{Style: ChartStyleCandlestick, CompareSymbols: []string{"SOXX"}}, false},
+		{"volume", "SOXL", ChartOptions{Volume: true, CompareSymbols: []string{"SOXX"}}, false},
+		{"indicators", "SOXL", ChartOptions{Indicators: ChartIndicators{RSI: 14}, CompareSymbols: []string{"SOXX"}}, false},
+		{"events", "SOXL", ChartOptions{Events: true, CompareSymbols: []string{"SOXX"}}, false},
+	}
+
+	for _, test := range tests {
+		err := validateCompareOptions(test.symbol, test.options)
+		if (err == nil) != test.valid {
+			t.Fatalf("%s: expected valid %v, got error %v", test.name, test.valid, err)
+		}
+	}
+}
+
+func TestValidateChartRequest(t *testing.T) {
+	svc := &ChartSVC{}
+	expected := svc.makeChartFileName("AAPL", ChartPeriod1Year, ChartInteval1Day, ChartOptions{})
+
+	// different spellings of a symbol share one chart file
+	for _, spelling := range []string{"AAPL", "aapl", " AAPL", "Aapl "} {
+		symbol, err := ValidateChartRequest(spelling, ChartPeriod1Year, ChartInteval1Day, ChartOptions{})
+		if err != nil {
+			t.Fatalf("%q: %v", spelling, err)
+		}
+
+		filename := svc.makeChartFileName(symbol, ChartPeriod1Year, ChartInteval1Day, ChartOptions{})
+		if filename != expected {
+			t.Fatalf("%q: expected %q, got %q", spelling, expected, filename)
+		}
+	}
+
+	symbol, err := ValidateChartRequest("../AAPL", ChartPeriod1Year, ChartInteval1Day, ChartOptions{})
+	if err == nil || len(symbol) > 0 {
+		t.Fatalf("%q: expected an error, got %q", "../AAPL", symbol)
+	}
+}
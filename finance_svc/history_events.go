@@ -0,0 +1,186 @@
+package finance_svc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	cache "github.com/patrickmn/go-cache"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	stockEventsRefreshTime = 24 * time.Hour // 1 day
+)
+
+// StockEventType is a kind of corporate event
+type StockEventType string
+
+const (
+	StockEventEarnings StockEventType = "earnings"
+	StockEventDividend StockEventType = "dividend"
+	StockEventSplit    StockEventType = "split"
+)
+
+// StockEvent is an earnings date, an ex-dividend date or a split of a stock
+type StockEvent struct {
+	Type StockEventType `json:"type"`
+	Time time.Time      `json:"time"`
+	// Amount is the dividend per share
+	Amount float64 `json:"amount,omitempty"`
+	// Numerator and Denominator are the split ratio, e.g., 4 and 1 for a 4:1 split
+	Numerator   float64 `json:"numerator,omitempty"`
+	Denominator float64 `json:"denominator,omitempty"`
+}
+
+// Label returns a short text drawn on the event marker, e.g., E, D 0.22, S 4:1,
+// empty for unknown types
+func (event StockEvent) Label() string {
+	switch event.Type {
+	case StockEventEarnings:
+		return "E"
+	case StockEventDividend:
+		return fmt.Sprintf("D %s", strconv.FormatFloat(event.Amount, 'f', -1, 64))
+	case StockEventSplit:
+		return fmt.Sprintf("S %s:%s", strconv.FormatFloat(event.Numerator, 'f', -1, 64), strconv.FormatFloat(event.Denominator, 'f', -1, 64))
+	default:
+		return ""
+	}
+}
+
+// stockEvents is corporate events of a symbol saved locally
+type stockEvents struct {
+	Symbol string `json:"symbol"`
+	// UpdateTime is when events were downloaded last
+	UpdateTime time.Time    `json:"update_time"`
+	Events     []StockEvent `json:"events"`
+}
+
+func sortStockEvents(events []StockEvent) {
+	sort.Slice(events, func(i int, j int) bool {
+		return events[i].Time.Before(events[j].Time)
+	})
+}
+
+// mergeEarningsEvents returns fetched events with stored earnings on other days.
+// Only the latest earnings date is downloaded, so earlier ones are kept from stored events.
+func mergeEarningsEvents(storedEvents []StockEvent, fetchedEvents []StockEvent) []StockEvent {
+	earningsDays := map[string]bool{}
+	for _, event := range fetchedEvents {
+		if event.Type == StockEventEarnings {
+			earningsDays[event.Time.Format("2006-01-02")] = true
+		}
+	}
+
+	events := append([]StockEvent{}, fetchedEvents...)
+	for _, event := range storedEvents {
+		if event.Type == StockEventEarnings && !earningsDays[event.Time.Format("2006-01-02")] {
+			events = append(events, event)
+		}
+	}
+
+	sortStockEvents(events)
+	return events
+}
+
+// GetEvents returns earnings dates, ex-dividend dates and splits of the symbol, downloading them once a day.
+// Stored events are returned if downloading fails.
+func (svc *HistorySVC) GetEvents(ctx context.Context, symbol string) ([]StockEvent, error) {
+	logger := log.WithFields(log.Fields{
+		"package":  "HistorySVC",
+		"function": "GetEvents",
+	})
+
+	key := svc.makeEventsKey(symbol)
+
+	keyLock := svc.getKeyLock(key)
+	keyLock.Lock()
+	defer keyLock.Unlock()
+
+	events, err := svc.loadEvents(key)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	if events == nil || time.Since(events.UpdateTime) >= stockEventsRefreshTime {
+		fetchedEvents, err := fetchStockEvents(ctx, symbol)
+		if err != nil {
+			if events == nil {
+				logger.Error(err)
+				return nil, err
+			}
+
+			// events rarely change in a day, stale ones are better than none
+			logger.Warnf("could not refresh events of %s, serving events of %s - %s", symbol, events.UpdateTime, err)
+			return events.Events, nil
+		}
+
+		if events != nil {
+			fetchedEvents = mergeEarningsEvents(events.Events, fetchedEvents)
+		}
+
+		events = &stockEvents{
+			Symbol:     symbol,
+			UpdateTime: time.Now(),
+			Events:     fetchedEvents,
+		}
+
+		err = svc.saveEvents(key, events)
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+	}
+
+	return events.Events, nil
+}
+
+func (svc *HistorySVC) makeEventsKey(symbol string) string {
+	return fmt.Sprintf("%s_events", makeSafeSymbol(symbol))
+}
+
+// loadEvents returns stored events, nil if not stored yet
+func (svc *HistorySVC) loadEvents(key string) (*stockEvents, error) {
+	if cachedEvents, ok := svc.Histories.Get(key); ok {
+		return cachedEvents.(*stockEvents), nil
+	}
+
+	data, err := ioutil.ReadFile(svc.makeHistoryFilePath(key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	events := &stockEvents{}
+	err = json.Unmarshal(data, events)
+	if err != nil {
+		return nil, err
+	}
+
+	svc.Histories.Set(key, events, cache.DefaultExpiration)
+	return events, nil
+}
+
+// saveEvents writes events to their file and the cache
+func (svc *HistorySVC) saveEvents(key string, events *stockEvents) error {
+	data, err := json.Marshal(events)
+	if err != nil {
+		return err
+	}
+
+	err = svc.writeHistoryFile(key, data)
+	if err != nil {
+		return err
+	}
+
+	svc.Histories.Set(key, events, cache.DefaultExpiration)
+	return nil
+}
@@ -0,0 +1,170 @@
+package finance_svc
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	cache "github.com/patrickmn/go-cache"
+)
+
+func TestGetEventsStale(t *testing.T) {
+	svc := &HistorySVC{
+		Histories: cache.New(cache.NoExpiration, cache.NoExpiration),
+		KeyLocks:  map[string]*sync.Mutex{},
+	}
+
+	storedEvents := []StockEvent{
+		{Type: StockEventDividend, Time: time.Date(2020, 2, 7, 0, 0, 0, 0, time.UTC), Amount: 0.77},
+	}
+
+	// downloads fail with a cancelled context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name       string
+		stored     bool
+		updateTime time.Time
+		valid      bool
+	}{
+		{"fresh", true, time.Now(), true},
+		// stale events are served when refreshing them fails
+		{"stale", true, time.Now().Add(-2 * stockEventsRefreshTime), true},
+		{"not stored", false, time.Time{}, false},
+	}
+
+	for _, test := range tests {
+		svc.Histories.Flush()
+		if test.stored {
+			svc.Histories.Set(svc.makeEventsKey("MSFT"), &stockEvents{
+				Symbol:     "MSFT",
+				UpdateTime: test.updateTime,
+				Events:     storedEvents,
+			}, cache.NoExpiration)
+		}
+
+		events, err := svc.GetEvents(ctx, "MSFT")
+		if (err == nil) != test.valid {
+			t.Fatalf("%s: expected valid %v, got error %v", test.name, test.valid, err)
+		}
+
+		if test.valid && (len(events) != 1 || events[0] != storedEvents[0]) {
+			t.Fatalf("%s: expected stored events, got %+v", test.name, events)
+		}
+	}
+}
+
+func TestStockEventLabel(t *testing.T) {
+	tests := []struct {
+		event    StockEvent
+		expected string
+	}{
+		{StockEvent{Type: StockEventEarnings}, "E"},
+		{StockEvent{Type: StockEventDividend, Amount: 0.22}, "D 0.22"},
+		{StockEvent{Type: StockEventSplit, Numerator: 4, Denominator: 1}, "S 4:1"},
+		// unknown types are not drawn as another type
+		{StockEvent{Type: "spinoff", Numerator: 1, Denominator: 1}, ""},
+	}
+
+	for _, test := range tests {
+		label := test.event.Label()
+		if label != test.expected {
+			t.Fatalf("expected %q, got %q", test.expected, label)
+		}
+	}
+}
+
+func TestMergeEarningsEvents(t *testing.T) {
+	day := func(month time.Month, date int) time.Time {
+		return time.Date(2020, month, date, 20, 30, 0, 0, time.UTC)
+	}
+
+	storedEvents := []StockEvent{
+		{Type: StockEventEarnings, Time: day(1, 28)},
+		{Type: StockEventDividend, Time: day(2, 7), Amount: 0.77},
+		{Type: StockEventEarnings, Time: day(4, 30)},
+	}
+
+	tests := []struct {
+		name     string
+		fetched  []StockEvent
+		expected []StockEvent
+	}{
+		// earlier earnings are kept, dividends and splits are replaced by fetched ones
+		{
+			"next earnings",
+			[]StockEvent{{Type: StockEventDividend, Time: day(2, 7), Amount: 0.77}, {Type: StockEventEarnings, Time: day(7, 30)}},
+			[]StockEvent{storedEvents[0], storedEvents[1], storedEvents[2], {Type: StockEventEarnings, Time: day(7, 30)}},
+		},
+		// the same earnings at another time of the day is not duplicated
+		{
+			"same earnings",
+			[]StockEvent{{Type: StockEventEarnings, Time: day(4, 30).Add(time.Hour)}},
+			[]StockEvent{storedEvents[0], {Type: StockEventEarnings, Time: day(4, 30).Add(time.Hour)}},
+		},
+		{
+			"no earnings",
+			[]StockEvent{},
+			[]StockEvent{storedEvents[0], storedEvents[2]},
+		},
+	}
+
+	for _, test := range tests {
+		events := mergeEarningsEvents(storedEvents, test.fetched)
+		if len(events) != len(test.expected) {
+			t.Fatalf("%s: expected %+v, got %+v", test.name, test.expected, events)
+		}
+
+		for idx := range events {
+			if events[idx] != test.expected[idx] {
+				t.Fatalf("%s: expected %+v at %d, got %+v", test.name, test.expected[idx], idx, events[idx])
+			}
+		}
+	}
+}
+
+func TestParseYahooEarningsEvents(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected []StockEvent
+		valid    bool
+	}{
+		{
+			"announced",
+			`{"quoteSummary":{"result":[{"calendarEvents":{"earnings":{"earningsDate":[{"raw":1588278600,"fmt":"2020-04-30"}]}}}],"error":null}}`,
+			[]StockEvent{{Type: StockEventEarnings, Time: time.Unix(1588278600, 0).UTC()}},
+			true,
+		},
+		// estimated windows are not earnings dates yet
+		{
+			"estimated",
+			`{"quoteSummary":{"result":[{"calendarEvents":{"earnings":{"earningsDate":[{"raw":1595808000},{"raw":1596240000}]}}}],"error":null}}`,
+			[]StockEvent{},
+			true,
+		},
+		// e.g., funds
+		{"no earnings", `{"quoteSummary":{"result":[{"calendarEvents":{}}],"error":null}}`, []StockEvent{}, true},
+		{"error", `{"quoteSummary":{"result":null,"error":{"code":"Not Found","description":"Quote not found for ticker symbol: NOPE"}}}`, nil, false},
+		{"empty", `{"quoteSummary":{"result":[],"error":null}}`, nil, false},
+	}
+
+	for _, test := range tests {
+		events, err := parseYahooEarningsEvents("AAPL", []byte(test.body))
+		if (err == nil) != test.valid {
+			t.Fatalf("%s: expected valid %v, got error %v", test.name, test.valid, err)
+		}
+
+		if len(events) != len(test.expected) {
+			t.Fatalf("%s: expected %+v, got %+v", test.name, test.expected, events)
+		}
+
+		for idx := range events {
+			if events[idx] != test.expected[idx] {
+				t.Fatalf("%s: expected %+v at %d, got %+v", test.name, test.expected[idx], idx, events[idx])
+			}
+		}
+	}
+}
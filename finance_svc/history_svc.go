@@ -0,0 +1,444 @@
+package finance_svc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	cache "github.com/patrickmn/go-cache"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	stockHistoryFileDir         = "history"
+	stockHistoryTempFilePrefix  = "tmp_"
+	stockHistoryCacheExpTime    = 1 * time.Hour    // 1 hour
+	stockHistoryRefreshIntraday = 1 * time.Minute  // 1 min
+	stockHistoryRefreshDaily    = 10 * time.Minute // 10 min
+	// stockHistoryOverlapDays is how many days before the last bar are downloaded again with
+	// daily bars, to find adjusted closes changed by dividends and splits
+	stockHistoryOverlapDays = 5
+	// stockHistoryAdjCloseTolerance is the relative difference of adjusted closes ignored
+	stockHistoryAdjCloseTolerance = 1e-4
+)
+
+var (
+	// periods in ascending order of length
+	historyPeriods = []ChartPeriod{
+		ChartPeriod1Day, ChartPeriod5Day, ChartPeriod1Month, ChartPeriod3Month, ChartPeriod6Month,
+		ChartPeriod1Year, ChartPeriod2Year, ChartPeriod5Year, ChartPeriod10Year, ChartPeriodMax,
+	}
+)
+
+// stockHistory is price bars of a symbol and an interval saved locally
+type stockHistory struct {
+	Symbol   string        `json:"symbol"`
+	Currency string        `json:"currency"`
+	Interval ChartInterval `json:"interval"`
+	// Period is the longest period downloaded in full
+	Period ChartPeriod `json:"period"`
+	// UpdateTime is when bars were downloaded last
+	UpdateTime time.Time `json:"update_time"`
+	// AdjustTime is when the whole period was downloaded last, adjusted closes are as of then
+	AdjustTime time.Time `json:"adjust_time"`
+	Bars       []OHLCV   `json:"bars"`
+}
+
+// HistorySVC keeps price bars of stocks in local files and refreshes them incrementally
+type HistorySVC struct {
+	// Histories caches loaded histories
+	Histories *cache.Cache
+	// KeyLocks serializes refreshes of the same history
+	KeyLocks     map[string]*sync.Mutex
+	KeyLocksLock sync.Mutex
+}
+
+// InitHistorySVC ...
+func InitHistorySVC() (*HistorySVC, error) {
+	err := os.MkdirAll(stockHistoryFileDir, 0766)
+	if err != nil {
+		return nil, err
+	}
+
+	historySvc := &HistorySVC{
+		Histories: cache.New(stockHistoryCacheExpTime, stockHistoryCacheExpTime),
+		KeyLocks:  map[string]*sync.Mutex{},
+	}
+
+	return historySvc, nil
+}
+
+// Close ...
+func (svc *HistorySVC) Close() error {
+	svc.Histories.Flush()
+	return nil
+}
+
+// GetHistory returns price bars of the symbol over the period, downloading only bars not stored yet.
+// Stored bars are returned if refreshing them fails.
+func (svc *HistorySVC) GetHistory(ctx context.Context, symbol string, period ChartPeriod, interval ChartInterval) (*OHLCVData, error) {
+	logger := log.WithFields(log.Fields{
+		"package":  "HistorySVC",
+		"function": "GetHistory",
+	})
+
+	err := ValidateChartPeriodInterval(period, interval)
+	if err != nil {
+		return nil, err
+	}
+
+	key := svc.makeHistoryKey(symbol, interval)
+
+	keyLock := svc.getKeyLock(key)
+	keyLock.Lock()
+	defer keyLock.Unlock()
+
+	history, err := svc.loadHistory(key)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	if history == nil || !svc.coversPeriod(history, period) {
+		history, err = svc.downloadFullHistory(ctx, key, symbol, history, period, interval)
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+	} else if time.Since(history.UpdateTime) >= svc.getRefreshPeriod(interval) {
+		refreshedHistory, err := svc.refreshHistory(ctx, key, symbol, history, period, interval)
+		if err != nil {
+			// stored bars are better than none, they are refreshed again by the next request
+			logger.Warnf("could not refresh history of %s, serving bars of %s - %s", symbol, history.UpdateTime, err)
+		} else {
+			history = refreshedHistory
+		}
+	}
+
+	return &OHLCVData{
+		Symbol:   symbol,
+		Currency: history.Currency,
+		Period:   period,
+		Interval: interval,
+		Bars:     svc.selectPeriod(history.Bars, period),
+	}, nil
+}
+
+func (svc *HistorySVC) makeHistoryKey(symbol string, interval ChartInterval) string {
+	return fmt.Sprintf("%s_%s", makeSafeSymbol(symbol), interval)
+}
+
+func (svc *HistorySVC) makeHistoryFilePath(key string) string {
+	return filepath.Join(stockHistoryFileDir, key+".json")
+}
+
+func (svc *HistorySVC) getKeyLock(key string) *sync.Mutex {
+	svc.KeyLocksLock.Lock()
+	defer svc.KeyLocksLock.Unlock()
+
+	keyLock, ok := svc.KeyLocks[key]
+	if !ok {
+		keyLock = &sync.Mutex{}
+		svc.KeyLocks[key] = keyLock
+	}
+	return keyLock
+}
+
+func (svc *HistorySVC) getRefreshPeriod(interval ChartInterval) time.Duration {
+	if isIntradayInterval(interval) {
+		return stockHistoryRefreshIntraday
+	}
+	return stockHistoryRefreshDaily
+}
+
+// coversPeriod returns true if the whole period has been downloaded
+func (svc *HistorySVC) coversPeriod(history *stockHistory, period ChartPeriod) bool {
+	if len(history.Bars) == 0 {
+		return false
+	}
+	return chartPeriodDays[period] <= chartPeriodDays[history.Period]
+}
+
+// downloadFullHistory downloads the whole period, or the stored period if longer, and saves it
+func (svc *HistorySVC) downloadFullHistory(ctx context.Context, key string, symbol string, history *stockHistory, period ChartPeriod, interval ChartInterval) (*stockHistory, error) {
+	fullPeriod := period
+	if history != nil && chartPeriodDays[history.Period] > chartPeriodDays[period] {
+		fullPeriod = history.Period
+	}
+
+	data, err := fetchOHLCV(ctx, symbol, fullPeriod, interval)
+	if err != nil {
+		return nil, err
+	}
+
+	history = svc.mergeHistory(history, data, fullPeriod)
+	history.AdjustTime = history.UpdateTime
+
+	err = svc.saveHistory(key, history)
+	if err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// refreshHistory downloads bars after the last stored bar and saves them,
+// or the whole period if adjusted closes of past bars changed
+func (svc *HistorySVC) refreshHistory(ctx context.Context, key string, symbol string, history *stockHistory, period ChartPeriod, interval ChartInterval) (*stockHistory, error) {
+	data, err := fetchOHLCV(ctx, symbol, svc.getGapPeriod(history, interval), interval)
+	if err != nil {
+		return nil, err
+	}
+
+	if svc.isAdjustmentChanged(history, data) || svc.hasNewAdjustmentEvents(ctx, symbol, history) {
+		// adjusted closes of all past bars changed, download the whole period again
+		return svc.downloadFullHistory(ctx, key, symbol, history, period, interval)
+	}
+
+	history = svc.mergeHistory(history, data, history.Period)
+	err = svc.saveHistory(key, history)
+	if err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// isAdjustmentChanged returns true if adjusted closes of downloaded bars differ from stored ones.
+// The last stored bar is not compared, it may have been stored before its day closed.
+func (svc *HistorySVC) isAdjustmentChanged(history *stockHistory, data *OHLCVData) bool {
+	if isIntradayInterval(history.Interval) || len(history.Bars) < 2 {
+		return false
+	}
+
+	storedBars := map[string]OHLCV{}
+	for _, bar := range history.Bars[:len(history.Bars)-1] {
+		storedBars[svc.makeBarKey(bar, history.Interval)] = bar
+	}
+
+	for _, bar := range data.Bars {
+		storedBar, ok := storedBars[svc.makeBarKey(bar, history.Interval)]
+		if !ok || storedBar.AdjClose == 0 {
+			continue
+		}
+
+		if math.Abs(bar.AdjClose-storedBar.AdjClose)/math.Abs(storedBar.AdjClose) > stockHistoryAdjCloseTolerance {
+			return true
+		}
+	}
+	return false
+}
+
+// hasNewAdjustmentEvents returns true if a dividend or a split happened after adjusted closes of
+// daily bars were downloaded
+func (svc *HistorySVC) hasNewAdjustmentEvents(ctx context.Context, symbol string, history *stockHistory) bool {
+	logger := log.WithFields(log.Fields{
+		"package":  "HistorySVC",
+		"function": "hasNewAdjustmentEvents",
+	})
+
+	if isIntradayInterval(history.Interval) {
+		return false
+	}
+
+	events, err := svc.GetEvents(ctx, symbol)
+	if err != nil {
+		// adjusted closes are still compared with downloaded bars
+		logger.Warn(err)
+		return false
+	}
+
+	now := time.Now()
+	for _, event := range events {
+		if event.Type != StockEventDividend && event.Type != StockEventSplit {
+			continue
+		}
+
+		if event.Time.After(history.AdjustTime) && !event.Time.After(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// getGapPeriod returns the shortest period covering bars after the last stored bar,
+// and an overlap before it with daily bars
+func (svc *HistorySVC) getGapPeriod(history *stockHistory, interval ChartInterval) ChartPeriod {
+	lastBar := history.Bars[len(history.Bars)-1]
+	gapDays := time.Since(lastBar.Time).Hours()/24 + 1
+	if !isIntradayInterval(interval) {
+		gapDays += stockHistoryOverlapDays
+	}
+
+	maxPeriod, limited := chartIntervalMaxPeriod[interval]
+	for _, period := range historyPeriods {
+		if ValidateChartPeriodInterval(period, interval) != nil {
+			continue
+		}
+
+		if chartPeriodDays[period] >= gapDays || (limited && period == maxPeriod) {
+			return period
+		}
+	}
+	return ChartPeriodMax
+}
+
+// mergeHistory adds downloaded bars to the history, bars at the same time or on the same day
+// for daily and longer intervals are replaced
+func (svc *HistorySVC) mergeHistory(history *stockHistory, data *OHLCVData, period ChartPeriod) *stockHistory {
+	merged := &stockHistory{
+		Symbol:     data.Symbol,
+		Currency:   data.Currency,
+		Interval:   data.Interval,
+		Period:     period,
+		UpdateTime: time.Now(),
+		Bars:       []OHLCV{},
+	}
+
+	bars := map[string]OHLCV{}
+	if history != nil {
+		merged.AdjustTime = history.AdjustTime
+		if chartPeriodDays[history.Period] > chartPeriodDays[period] {
+			merged.Period = history.Period
+		}
+
+		for _, bar := range history.Bars {
+			bars[svc.makeBarKey(bar, data.Interval)] = bar
+		}
+	}
+
+	for _, bar := range data.Bars {
+		bars[svc.makeBarKey(bar, data.Interval)] = bar
+	}
+
+	for _, bar := range bars {
+		merged.Bars = append(merged.Bars, bar)
+	}
+
+	sort.Slice(merged.Bars, func(i int, j int) bool {
+		return merged.Bars[i].Time.Before(merged.Bars[j].Time)
+	})
+
+	// intraday bars are only available for recent days, drop older ones
+	if maxPeriod, ok := chartIntervalMaxPeriod[data.Interval]; ok {
+		merged.Bars = svc.selectPeriod(merged.Bars, maxPeriod)
+		if chartPeriodDays[merged.Period] > chartPeriodDays[maxPeriod] {
+			merged.Period = maxPeriod
+		}
+	}
+
+	return merged
+}
+
+// makeBarKey returns the key of a bar in merging. The in-progress daily bar is stamped with the
+// last trade time, not the session, so daily and longer bars are keyed by exchange-local date.
+func (svc *HistorySVC) makeBarKey(bar OHLCV, interval ChartInterval) string {
+	if isIntradayInterval(interval) {
+		return strconv.FormatInt(bar.Time.Unix(), 10)
+	}
+	// bar times are in the exchange time zone
+	return bar.Time.Format("2006-01-02")
+}
+
+// selectPeriod returns bars within the period, 1d and 5d count trading days
+func (svc *HistorySVC) selectPeriod(bars []OHLCV, period ChartPeriod) []OHLCV {
+	if len(bars) == 0 || period == ChartPeriodMax {
+		return bars
+	}
+
+	if period == ChartPeriod1Day || period == ChartPeriod5Day {
+		days := int(chartPeriodDays[period])
+		lastDay := ""
+		for idx := len(bars) - 1; idx >= 0; idx-- {
+			day := bars[idx].Time.Format("2006-01-02")
+			if day != lastDay {
+				if days == 0 {
+					return bars[idx+1:]
+				}
+				days--
+				lastDay = day
+			}
+		}
+		return bars
+	}
+
+	periodDuration := time.Duration(chartPeriodDays[period] * float64(24*time.Hour))
+	startTime := bars[len(bars)-1].Time.Add(-periodDuration)
+	start := sort.Search(len(bars), func(i int) bool {
+		return bars[i].Time.After(startTime)
+	})
+	return bars[start:]
+}
+
+// loadHistory returns a stored history, nil if not stored yet
+func (svc *HistorySVC) loadHistory(key string) (*stockHistory, error) {
+	if cachedHistory, ok := svc.Histories.Get(key); ok {
+		return cachedHistory.(*stockHistory), nil
+	}
+
+	data, err := ioutil.ReadFile(svc.makeHistoryFilePath(key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	history := &stockHistory{}
+	err = json.Unmarshal(data, history)
+	if err != nil {
+		return nil, err
+	}
+
+	svc.Histories.Set(key, history, cache.DefaultExpiration)
+	return history, nil
+}
+
+// saveHistory writes a history to its file and the cache
+func (svc *HistorySVC) saveHistory(key string, history *stockHistory) error {
+	data, err := json.Marshal(history)
+	if err != nil {
+		return err
+	}
+
+	err = svc.writeHistoryFile(key, data)
+	if err != nil {
+		return err
+	}
+
+	svc.Histories.Set(key, history, cache.DefaultExpiration)
+	return nil
+}
+
+// writeHistoryFile replaces the file of the key atomically
+func (svc *HistorySVC) writeHistoryFile(key string, data []byte) error {
+	err := os.MkdirAll(stockHistoryFileDir, 0766)
+	if err != nil {
+		return err
+	}
+
+	tempFile, err := ioutil.TempFile(stockHistoryFileDir, stockHistoryTempFilePrefix+"*_"+key+".json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tempFile.Name())
+
+	_, err = tempFile.Write(data)
+	if err != nil {
+		tempFile.Close()
+		return err
+	}
+
+	err = tempFile.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tempFile.Name(), svc.makeHistoryFilePath(key))
+}
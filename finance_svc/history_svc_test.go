@@ -0,0 +1,317 @@
+package finance_svc
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	cache "github.com/patrickmn/go-cache"
+)
+
+// makeTestBars returns bars at the times, closes are 1, 2, 3, ...
+func makeTestBars(times ...time.Time) []OHLCV {
+	bars := []OHLCV{}
+	for idx, barTime := range times {
+		bars = append(bars, OHLCV{
+			Time:     barTime,
+			Close:    float64(idx + 1),
+			AdjClose: float64(idx + 1),
+		})
+	}
+	return bars
+}
+
+func TestSelectPeriod(t *testing.T) {
+	svc := &HistorySVC{}
+
+	day := func(year int, month time.Month, date int, hour int) time.Time {
+		return time.Date(year, month, date, hour, 0, 0, 0, time.UTC)
+	}
+
+	// intraday bars of three trading days
+	intradayBars := makeTestBars(
+		day(2020, 3, 5, 14), day(2020, 3, 5, 15),
+		day(2020, 3, 6, 14), day(2020, 3, 6, 15),
+		day(2020, 3, 9, 14), day(2020, 3, 9, 15),
+	)
+
+	// daily bars over two months
+	dailyBars := makeTestBars(
+		day(2020, 1, 2, 0), day(2020, 1, 31, 0), day(2020, 2, 3, 0), day(2020, 2, 28, 0), day(2020, 3, 2, 0),
+	)
+
+	tests := []struct {
+		name     string
+		bars     []OHLCV
+		period   ChartPeriod
+		expected []float64
+	}{
+		{"empty", []OHLCV{}, ChartPeriod1Month, []float64{}},
+		{"max", dailyBars, ChartPeriodMax, []float64{1, 2, 3, 4, 5}},
+		{"last trading day", intradayBars, ChartPeriod1Day, []float64{5, 6}},
+		{"five trading days of three", intradayBars, ChartPeriod5Day, []float64{1, 2, 3, 4, 5, 6}},
+		// 31 days before Mar 2 is Jan 31, which is not after the start
+		{"one month", dailyBars, ChartPeriod1Month, []float64{3, 4, 5}},
+		{"longer than bars", dailyBars, ChartPeriod1Year, []float64{1, 2, 3, 4, 5}},
+	}
+
+	for _, test := range tests {
+		selected := svc.selectPeriod(test.bars, test.period)
+		if len(selected) != len(test.expected) {
+			t.Fatalf("%s: expected %d bars, got %d - %v", test.name, len(test.expected), len(selected), selected)
+		}
+
+		for idx := range selected {
+			if selected[idx].Close != test.expected[idx] {
+				t.Fatalf("%s: expected close %v at %d, got %v", test.name, test.expected[idx], idx, selected[idx].Close)
+			}
+		}
+	}
+}
+
+func TestGetGapPeriod(t *testing.T) {
+	svc := &HistorySVC{}
+
+	tests := []struct {
+		name     string
+		interval ChartInterval
+		gap      time.Duration
+		expected ChartPeriod
+	}{
+		// daily bars overlap stored ones by stockHistoryOverlapDays to catch adjustments
+		{"daily, a day behind", ChartInteval1Day, 24 * time.Hour, ChartPeriod1Month},
+		{"daily, six weeks behind", ChartInteval1Day, 42 * 24 * time.Hour, ChartPeriod3Month},
+		{"daily, years behind", ChartInteval1Day, 20 * 366 * 24 * time.Hour, ChartPeriodMax},
+		{"weekly, a day behind", ChartInteval1Week, 24 * time.Hour, ChartPeriod1Month},
+		// intraday bars do not overlap, but the current day is always fetched
+		{"minutes, an hour behind", ChartInteval1Min, time.Hour, ChartPeriod5Day},
+		{"minutes, weeks behind", ChartInteval1Min, 20 * 24 * time.Hour, ChartPeriod5Day},
+		{"5 minutes, a week behind", ChartInteval5Min, 7 * 24 * time.Hour, ChartPeriod1Month},
+		{"hourly, half a year behind", ChartInteval1Hour, 150 * 24 * time.Hour, ChartPeriod6Month},
+		{"hourly, years behind", ChartInteval1Hour, 5 * 366 * 24 * time.Hour, ChartPeriod2Year},
+	}
+
+	for _, test := range tests {
+		history := &stockHistory{
+			Interval: test.interval,
+			Bars:     makeTestBars(time.Now().Add(-test.gap)),
+		}
+
+		period := svc.getGapPeriod(history, test.interval)
+		if period != test.expected {
+			t.Fatalf("%s: expected period %s, got %s", test.name, test.expected, period)
+		}
+	}
+}
+
+func TestMergeHistory(t *testing.T) {
+	svc := &HistorySVC{}
+
+	newyork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	adjustTime := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	barAt := func(date int, hour int, min int, close float64) OHLCV {
+		return OHLCV{
+			Time:     time.Date(2020, 3, date, hour, min, 0, 0, newyork),
+			Close:    close,
+			AdjClose: close,
+		}
+	}
+	bar := func(date int, close float64) OHLCV {
+		return barAt(date, 9, 30, close)
+	}
+
+	tests := []struct {
+		name           string
+		history        *stockHistory
+		interval       ChartInterval
+		bars           []OHLCV
+		period         ChartPeriod
+		expectedCloses []float64
+		expectedPeriod ChartPeriod
+		expectedAdjust time.Time
+	}{
+		{
+			name:           "first download",
+			history:        nil,
+			interval:       ChartInteval1Day,
+			bars:           []OHLCV{bar(3, 3), bar(2, 2)},
+			period:         ChartPeriod1Year,
+			expectedCloses: []float64{2, 3},
+			expectedPeriod: ChartPeriod1Year,
+		},
+		{
+			// the gap download overlaps the last stored bar, which is replaced
+			name: "gap download",
+			history: &stockHistory{
+				Interval:   ChartInteval1Day,
+				Period:     ChartPeriod1Year,
+				AdjustTime: adjustTime,
+				Bars:       []OHLCV{bar(2, 2), bar(3, 3)},
+			},
+			interval:       ChartInteval1Day,
+			bars:           []OHLCV{bar(3, 30), bar(4, 4)},
+			period:         ChartPeriod1Month,
+			expectedCloses: []float64{2, 30, 4},
+			expectedPeriod: ChartPeriod1Year,
+			expectedAdjust: adjustTime,
+		},
+		{
+			// the in-progress daily bar is stamped with the last trade time, the final one with the session.
+			// 19:00 in New York is the next day in UTC, but the same session
+			name: "in-progress daily bar",
+			history: &stockHistory{
+				Interval: ChartInteval1Day,
+				Period:   ChartPeriod1Year,
+				Bars:     []OHLCV{bar(2, 2), barAt(3, 11, 42, 3.1), barAt(3, 19, 0, 3.2)},
+			},
+			interval:       ChartInteval1Day,
+			bars:           []OHLCV{bar(2, 2), bar(3, 3)},
+			period:         ChartPeriod5Day,
+			expectedCloses: []float64{2, 3},
+			expectedPeriod: ChartPeriod1Year,
+		},
+		{
+			name: "intraday bars of a day",
+			history: &stockHistory{
+				Interval: ChartInteval5Min,
+				Period:   ChartPeriod1Month,
+				Bars:     []OHLCV{barAt(3, 9, 30, 1), barAt(3, 9, 35, 2)},
+			},
+			interval:       ChartInteval5Min,
+			bars:           []OHLCV{barAt(3, 9, 35, 20), barAt(3, 9, 40, 3)},
+			period:         ChartPeriod1Day,
+			expectedCloses: []float64{1, 20, 3},
+			expectedPeriod: ChartPeriod1Month,
+		},
+		{
+			name: "longer download",
+			history: &stockHistory{
+				Interval: ChartInteval1Day,
+				Period:   ChartPeriod1Month,
+				Bars:     []OHLCV{bar(3, 3)},
+			},
+			interval:       ChartInteval1Day,
+			bars:           []OHLCV{bar(1, 1), bar(3, 3)},
+			period:         ChartPeriod5Year,
+			expectedCloses: []float64{1, 3},
+			expectedPeriod: ChartPeriod5Year,
+		},
+		{
+			// 1 minute bars are only available for 5 days
+			name: "intraday bars expire",
+			history: &stockHistory{
+				Interval: ChartInteval1Min,
+				Period:   ChartPeriod5Day,
+				Bars:     []OHLCV{bar(1, 1), bar(2, 2), bar(3, 3), bar(4, 4), bar(5, 5)},
+			},
+			interval:       ChartInteval1Min,
+			bars:           []OHLCV{bar(6, 6), bar(7, 7)},
+			period:         ChartPeriod1Month,
+			expectedCloses: []float64{3, 4, 5, 6, 7},
+			expectedPeriod: ChartPeriod5Day,
+		},
+	}
+
+	for _, test := range tests {
+		data := &OHLCVData{
+			Symbol:   "AAPL",
+			Currency: "USD",
+			Period:   test.period,
+			Interval: test.interval,
+			Bars:     test.bars,
+		}
+
+		merged := svc.mergeHistory(test.history, data, test.period)
+		if merged.Period != test.expectedPeriod {
+			t.Fatalf("%s: expected period %s, got %s", test.name, test.expectedPeriod, merged.Period)
+		}
+
+		if !merged.AdjustTime.Equal(test.expectedAdjust) {
+			t.Fatalf("%s: expected adjust time %s, got %s", test.name, test.expectedAdjust, merged.AdjustTime)
+		}
+
+		if len(merged.Bars) != len(test.expectedCloses) {
+			t.Fatalf("%s: expected %d bars, got %d - %v", test.name, len(test.expectedCloses), len(merged.Bars), merged.Bars)
+		}
+
+		for idx := range merged.Bars {
+			if merged.Bars[idx].Close != test.expectedCloses[idx] {
+				t.Fatalf("%s: expected close %v at %d, got %v", test.name, test.expectedCloses[idx], idx, merged.Bars[idx].Close)
+			}
+		}
+	}
+}
+
+func TestGetHistoryStale(t *testing.T) {
+	svc := &HistorySVC{
+		Histories: cache.New(cache.NoExpiration, cache.NoExpiration),
+		KeyLocks:  map[string]*sync.Mutex{},
+	}
+
+	day := func(date int) time.Time {
+		return time.Date(2020, 3, date, 0, 0, 0, 0, time.UTC)
+	}
+	storedBars := makeTestBars(day(2), day(3), day(4), day(5), day(6))
+
+	// downloads fail with a cancelled context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name       string
+		stored     bool
+		period     ChartPeriod
+		updateTime time.Time
+		valid      bool
+	}{
+		{"fresh", true, ChartPeriod1Month, time.Now(), true},
+		// stored bars are served when refreshing them fails
+		{"stale", true, ChartPeriod1Month, time.Now().Add(-2 * stockHistoryRefreshDaily), true},
+		{"stale, shorter period", true, ChartPeriod5Day, time.Now().Add(-2 * stockHistoryRefreshDaily), true},
+		// bars of a longer period are not stored yet
+		{"longer period", true, ChartPeriod1Year, time.Now(), false},
+		{"not stored", false, ChartPeriod1Month, time.Time{}, false},
+	}
+
+	for _, test := range tests {
+		key := svc.makeHistoryKey("MSFT", ChartInteval1Day)
+
+		svc.Histories.Flush()
+		if test.stored {
+			svc.Histories.Set(key, &stockHistory{
+				Symbol:     "MSFT",
+				Currency:   "USD",
+				Interval:   ChartInteval1Day,
+				Period:     ChartPeriod1Month,
+				UpdateTime: test.updateTime,
+				AdjustTime: test.updateTime,
+				Bars:       storedBars,
+			}, cache.NoExpiration)
+		}
+
+		data, err := svc.GetHistory(ctx, "MSFT", test.period, ChartInteval1Day)
+		if (err == nil) != test.valid {
+			t.Fatalf("%s: expected valid %v, got error %v", test.name, test.valid, err)
+		}
+
+		if !test.valid {
+			continue
+		}
+
+		expectedBars := svc.selectPeriod(storedBars, test.period)
+		if len(data.Bars) != len(expectedBars) || data.Bars[0] != expectedBars[0] || data.Currency != "USD" {
+			t.Fatalf("%s: expected stored bars %v, got %+v", test.name, expectedBars, data)
+		}
+
+		// a failed refresh is tried again by the next request
+		cachedHistory, _ := svc.Histories.Get(key)
+		if !cachedHistory.(*stockHistory).UpdateTime.Equal(test.updateTime) {
+			t.Fatalf("%s: expected update time %s, got %s", test.name, test.updateTime, cachedHistory.(*stockHistory).UpdateTime)
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package finance_svc
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// MarketSessionType is how a market trades over a week
+type MarketSessionType int
+
+const (
+	// RegularSession trades on weekdays between open and close
+	RegularSession MarketSessionType = iota
+	// WeekdaySession trades around the clock from Sunday open to Friday close with a daily break, e.g., futures and forex
+	WeekdaySession
+	// AllDaySession trades around the clock every day, e.g., crypto currencies
+	AllDaySession
+)
+
+var (
+	// e.g., BTC-USD, ETH-KRW
+	cryptoSymbolRegexp = regexp.MustCompile(`^[A-Z0-9]+-(USD|USDT|USDC|EUR|KRW|BTC|ETH)$`)
+)
+
+// MarketSession is trading hours of a market
+type MarketSession struct {
+	Name     string
+	Type     MarketSessionType
+	Location *time.Location
+	// Open and Close are local times of day, a WeekdaySession opens after it closes on the same day
+	Open  time.Duration
+	Close time.Duration
+	// Holidays tells if the market is closed on a local date, nil if holidays are not known
+	Holidays func(year int, month time.Month, day int) bool
+}
+
+// marketSessionSuffix maps a symbol suffix to a market
+type marketSessionSuffix struct {
+	Suffix  string
+	Session *MarketSession
+}
+
+// initMarketSessions creates markets by symbol suffix, the default market and the crypto market
+func initMarketSessions() ([]marketSessionSuffix, *MarketSession, *MarketSession, error) {
+	locations := map[string]*time.Location{}
+	for _, name := range []string{"America/New_York", "Asia/Seoul", "Asia/Tokyo", "Asia/Hong_Kong", "Europe/London"} {
+		location, err := time.LoadLocation(name)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		locations[name] = location
+	}
+
+	hm := func(hour int, minute int) time.Duration {
+		return time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute
+	}
+
+	nyse := &MarketSession{Name: "NYSE", Type: RegularSession, Location: locations["America/New_York"], Open: hm(9, 30), Close: hm(16, 0), Holidays: isNYSEHoliday}
+	krx := &MarketSession{Name: "KRX", Type: RegularSession, Location: locations["Asia/Seoul"], Open: hm(9, 0), Close: hm(15, 30)}
+	tse := &MarketSession{Name: "TSE", Type: RegularSession, Location: locations["Asia/Tokyo"], Open: hm(9, 0), Close: hm(15, 30)}
+	hkex := &MarketSession{Name: "HKEX", Type: RegularSession, Location: locations["Asia/Hong_Kong"], Open: hm(9, 30), Close: hm(16, 0)}
+	lse := &MarketSession{Name: "LSE", Type: RegularSession, Location: locations["Europe/London"], Open: hm(8, 0), Close: hm(16, 30)}
+	globex := &MarketSession{Name: "GLOBEX", Type: WeekdaySession, Location: locations["America/New_York"], Open: hm(18, 0), Close: hm(17, 0)}
+	crypto := &MarketSession{Name: "CRYPTO", Type: AllDaySession, Location: time.UTC, Open: 0, Close: 0}
+
+	suffixes := []marketSessionSuffix{
+		{Suffix: ".KS", Session: krx},
+		{Suffix: ".KQ", Session: krx},
+		{Suffix: ".T", Session: tse},
+		{Suffix: ".HK", Session: hkex},
+		{Suffix: ".L", Session: lse},
+		{Suffix: "=F", Session: globex},
+		{Suffix: "=X", Session: globex},
+	}
+
+	return suffixes, nyse, crypto, nil
+}
+
+// GetMarketSession returns the session of the market the symbol trades in, NYSE by default
+func (svc *TimeSVC) GetMarketSession(symbol string) *MarketSession {
+	upperSymbol := strings.ToUpper(symbol)
+	if cryptoSymbolRegexp.MatchString(upperSymbol) {
+		return svc.CryptoMarketSession
+	}
+
+	for _, suffix := range svc.MarketSessionSuffixes {
+		if strings.HasSuffix(upperSymbol, suffix.Suffix) {
+			return suffix.Session
+		}
+	}
+	return svc.DefaultMarketSession
+}
+
+// IsOpen returns true if the market trades at t
+func (session *MarketSession) IsOpen(t time.Time) bool {
+	if session.Type == AllDaySession {
+		return true
+	}
+
+	local := t.In(session.Location)
+	weekday := local.Weekday()
+	hour, minute, second := local.Clock()
+	timeOfDay := time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute + time.Duration(second)*time.Second
+
+	switch session.Type {
+	case WeekdaySession:
+		switch weekday {
+		case time.Saturday:
+			return false
+		case time.Sunday:
+			return timeOfDay >= session.Open
+		case time.Friday:
+			return timeOfDay < session.Close
+		default:
+			return timeOfDay < session.Close || timeOfDay >= session.Open
+		}
+	default:
+		if !session.isTradingDate(local) {
+			return false
+		}
+		return timeOfDay >= session.Open && timeOfDay < session.Close
+	}
+}
+
+// IsTradingDay returns true if the market trades on the local date of t, a WeekdaySession
+// opening on Sunday evening trades for Monday
+func (session *MarketSession) IsTradingDay(t time.Time) bool {
+	if session.Type == AllDaySession {
+		return true
+	}
+
+	return session.isTradingDate(t.In(session.Location))
+}
+
+// isTradingDate returns true if the local date is neither a weekend nor a holiday of the market
+func (session *MarketSession) isTradingDate(local time.Time) bool {
+	weekday := local.Weekday()
+	if weekday == time.Saturday || weekday == time.Sunday {
+		return false
+	}
+
+	if session.Holidays != nil {
+		year, month, day := local.Date()
+		return !session.Holidays(year, month, day)
+	}
+	return true
+}
+
+// LastClose returns the latest close of the market at or before t,
+// the end of the UTC day for markets trading all day
+func (session *MarketSession) LastClose(t time.Time) time.Time {
+	local := t.In(session.Location)
+	if session.Type == AllDaySession {
+		return session.atTime(local, 0)
+	}
+
+	for days := 0; days < 8; days++ {
+		day := local.AddDate(0, 0, -days)
+		if !session.isTradingDate(day) {
+			continue
+		}
+
+		closeTime := session.atTime(day, session.Close)
+		if !closeTime.After(t) {
+			return closeTime
+		}
+	}
+	return session.atTime(local, 0)
+}
+
+// NextClose returns the earliest close of the market after t,
+// the end of the UTC day for markets trading all day
+func (session *MarketSession) NextClose(t time.Time) time.Time {
+	local := t.In(session.Location)
+	if session.Type == AllDaySession {
+		return session.atTime(local, 0).AddDate(0, 0, 1)
+	}
+
+	for days := 0; days < 8; days++ {
+		day := local.AddDate(0, 0, days)
+		if !session.isTradingDate(day) {
+			continue
+		}
+
+		closeTime := session.atTime(day, session.Close)
+		if closeTime.After(t) {
+			return closeTime
+		}
+	}
+	return session.atTime(local, 0).AddDate(0, 0, 1)
+}
+
+// atTime returns the local time of day on the date of t
+func (session *MarketSession) atTime(t time.Time, timeOfDay time.Duration) time.Time {
+	year, month, day := t.In(session.Location).Date()
+	hour := int(timeOfDay / time.Hour)
+	minute := int((timeOfDay % time.Hour) / time.Minute)
+	return time.Date(year, month, day, hour, minute, 0, 0, session.Location)
+}
+
+// isNYSEHoliday returns true if NYSE is closed for a holiday on the date in New York,
+// holidays on Saturday are observed on Friday and on Sunday on Monday
+func isNYSEHoliday(year int, month time.Month, day int) bool {
+	date := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+
+	// New Year's Day on Saturday falls on the last day of the previous year, which NYSE does not observe
+	holidays := []time.Time{
+		observedHoliday(time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)),
+		nthWeekday(year, time.January, time.Monday, 3),                // Martin Luther King Jr. Day
+		nthWeekday(year, time.February, time.Monday, 3),               // Washington's Birthday
+		easterSunday(year).AddDate(0, 0, -2),                          // Good Friday
+		nthWeekday(year, time.June, time.Monday, 1).AddDate(0, 0, -7), // Memorial Day, the last Monday of May
+		observedHoliday(time.Date(year, time.July, 4, 0, 0, 0, 0, time.UTC)),
+		nthWeekday(year, time.September, time.Monday, 1),  // Labor Day
+		nthWeekday(year, time.November, time.Thursday, 4), // Thanksgiving Day
+		observedHoliday(time.Date(year, time.December, 25, 0, 0, 0, 0, time.UTC)),
+	}
+
+	if year >= 2022 {
+		holidays = append(holidays, observedHoliday(time.Date(year, time.June, 19, 0, 0, 0, 0, time.UTC)))
+	}
+
+	for _, holiday := range holidays {
+		if holiday.Equal(date) {
+			return true
+		}
+	}
+	return false
+}
+
+// observedHoliday moves a holiday on a weekend to the nearest weekday
+func observedHoliday(holiday time.Time) time.Time {
+	switch holiday.Weekday() {
+	case time.Saturday:
+		return holiday.AddDate(0, 0, -1)
+	case time.Sunday:
+		return holiday.AddDate(0, 0, 1)
+	default:
+		return holiday
+	}
+}
+
+// nthWeekday returns the nth weekday of the month, e.g., the 4th Thursday of November
+func nthWeekday(year int, month time.Month, weekday time.Weekday, nth int) time.Time {
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	offset := (int(weekday) - int(first.Weekday()) + 7) % 7
+	return first.AddDate(0, 0, offset+(nth-1)*7)
+}
+
+// easterSunday returns Easter Sunday of the year in the Gregorian calendar
+func easterSunday(year int) time.Time {
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
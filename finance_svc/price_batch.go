@@ -0,0 +1,185 @@
+package finance_svc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	stockInfoFetchWorkers = 8
+)
+
+// StockInfoResult is a quote of a symbol in a batch, or the error getting it
+type StockInfoResult struct {
+	Symbol    string
+	StockInfo *StockInfo
+	Err       error
+}
+
+// GetStockInfos returns quotes of the symbols in the same order.
+// Providers supporting multi-symbol requests get all missing symbols at once, others are asked
+// concurrently. A symbol failing does not fail others, its error is in the result.
+// Requests to providers stop when ctx is cancelled.
+func (svc *PriceSVC) GetStockInfos(ctx context.Context, symbols []string) []StockInfoResult {
+	logger := log.WithFields(log.Fields{
+		"package":  "PriceSVC",
+		"function": "GetStockInfos",
+	})
+
+	stockCache, expiration := svc.getStockCache()
+
+	stockInfos := map[string]*StockInfo{}
+	missingSymbols := []string{}
+	for _, symbol := range symbols {
+		if _, ok := stockInfos[symbol]; ok {
+			continue
+		}
+
+		if cache, ok := stockCache.Get(symbol); ok {
+			stockInfos[symbol] = cache.(*StockInfo)
+		} else {
+			stockInfos[symbol] = nil
+			missingSymbols = append(missingSymbols, symbol)
+		}
+	}
+
+	fetchedInfos, fetchErrors := svc.getStockInfos(ctx, missingSymbols)
+	for symbol, stockInfo := range fetchedInfos {
+		stockInfos[symbol] = stockInfo
+		stockCache.Set(symbol, stockInfo, expiration)
+	}
+
+	results := make([]StockInfoResult, len(symbols))
+	for idx, symbol := range symbols {
+		results[idx] = StockInfoResult{
+			Symbol:    symbol,
+			StockInfo: stockInfos[symbol],
+		}
+
+		if stockInfos[symbol] == nil {
+			results[idx].Err = fetchErrors[symbol]
+			logger.Error(results[idx].Err)
+		}
+	}
+	return results
+}
+
+// getStockInfos asks providers in order for symbols not served yet, returns quotes and
+// errors by symbol
+func (svc *PriceSVC) getStockInfos(ctx context.Context, symbols []string) (map[string]*StockInfo, map[string]error) {
+	stockInfos := map[string]*StockInfo{}
+	providerErrors := map[string][]string{}
+
+	pendingSymbols := symbols
+	for _, provider := range svc.Providers {
+		if len(pendingSymbols) == 0 {
+			break
+		}
+
+		served, errs := svc.getQuotes(ctx, provider, pendingSymbols)
+
+		remainingSymbols := []string{}
+		for _, symbol := range pendingSymbols {
+			stockInfo, ok := served[symbol]
+			if ok && !isEmptyQuote(stockInfo) {
+				stockInfo.Provider = provider.GetType()
+				stockInfos[symbol] = stockInfo
+				continue
+			}
+
+			err := errs[symbol]
+			if ok || err == nil {
+				// a quote without price is not served, the next provider may have it
+				err = fmt.Errorf("could not get quote - %s, empty quote", symbol)
+			}
+
+			providerErrors[symbol] = append(providerErrors[symbol], fmt.Sprintf("%s: %v", provider.GetType(), err))
+			remainingSymbols = append(remainingSymbols, symbol)
+		}
+		pendingSymbols = remainingSymbols
+	}
+
+	errs := map[string]error{}
+	for _, symbol := range pendingSymbols {
+		errs[symbol] = fmt.Errorf("all quote providers failed - %s, %s", symbol, strings.Join(providerErrors[symbol], "; "))
+	}
+	return stockInfos, errs
+}
+
+// isEmptyQuote returns true if the quote has no price
+func isEmptyQuote(stockInfo *StockInfo) bool {
+	return stockInfo == nil || stockInfo.CurrentPrice == 0
+}
+
+// getQuotes gets quotes of the symbols from a provider, in a request if the provider supports it.
+// Symbols are asked one by one if the request fails as a whole.
+func (svc *PriceSVC) getQuotes(ctx context.Context, provider QuoteProvider, symbols []string) (map[string]*StockInfo, map[string]error) {
+	logger := log.WithFields(log.Fields{
+		"package":  "PriceSVC",
+		"function": "getQuotes",
+	})
+
+	batchProvider, ok := provider.(BatchQuoteProvider)
+	if !ok || len(symbols) < 2 {
+		return svc.getQuotesConcurrently(ctx, provider, symbols)
+	}
+
+	served, err := batchProvider.GetQuotes(ctx, symbols)
+	if err != nil {
+		logger.Warnf("quote provider %s failed for %d symbols, asking one by one - %v", provider.GetType(), len(symbols), err)
+		return svc.getQuotesConcurrently(ctx, provider, symbols)
+	}
+
+	errs := map[string]error{}
+	for _, symbol := range symbols {
+		if _, ok := served[symbol]; !ok {
+			errs[symbol] = fmt.Errorf("could not get quote - %s, not in result", symbol)
+		}
+	}
+	return served, errs
+}
+
+// getQuotesConcurrently gets quotes of the symbols one by one with bounded concurrency
+func (svc *PriceSVC) getQuotesConcurrently(ctx context.Context, provider QuoteProvider, symbols []string) (map[string]*StockInfo, map[string]error) {
+	logger := log.WithFields(log.Fields{
+		"package":  "PriceSVC",
+		"function": "getQuotesConcurrently",
+	})
+
+	served := map[string]*StockInfo{}
+	errs := map[string]error{}
+	lock := sync.Mutex{}
+
+	tasks := make(chan string)
+	wg := sync.WaitGroup{}
+	for i := 0; i < stockInfoFetchWorkers && i < len(symbols); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for symbol := range tasks {
+				stockInfo, err := provider.GetQuote(ctx, symbol)
+
+				lock.Lock()
+				if err != nil {
+					logger.Warnf("quote provider %s failed for %s - %v", provider.GetType(), symbol, err)
+					errs[symbol] = err
+				} else {
+					served[symbol] = stockInfo
+				}
+				lock.Unlock()
+			}
+		}()
+	}
+
+	for _, symbol := range symbols {
+		tasks <- symbol
+	}
+	close(tasks)
+	wg.Wait()
+
+	return served, errs
+}
@@ -0,0 +1,231 @@
+package finance_svc
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// stubBatchQuoteProvider serves quotes of symbols not in failedSymbols, in a request or one by one
+type stubBatchQuoteProvider struct {
+	providerType  QuoteProviderType
+	failedSymbols map[string]bool
+	// batchErr fails multi-symbol requests as a whole
+	batchErr error
+
+	lock          sync.Mutex
+	batchRequests [][]string
+	quoteRequests []string
+	running       int
+	maxRunning    int
+}
+
+// GetType ...
+func (provider *stubBatchQuoteProvider) GetType() QuoteProviderType {
+	return provider.providerType
+}
+
+// GetQuote ...
+func (provider *stubBatchQuoteProvider) GetQuote(ctx context.Context, symbol string) (*StockInfo, error) {
+	provider.lock.Lock()
+	provider.quoteRequests = append(provider.quoteRequests, symbol)
+	provider.running++
+	if provider.running > provider.maxRunning {
+		provider.maxRunning = provider.running
+	}
+	provider.lock.Unlock()
+
+	// hold the request so that others run at the same time
+	time.Sleep(10 * time.Millisecond)
+
+	provider.lock.Lock()
+	provider.running--
+	provider.lock.Unlock()
+
+	if provider.failedSymbols[symbol] {
+		return nil, fmt.Errorf("%s is not served", symbol)
+	}
+	return &StockInfo{Symbol: symbol, CurrentPrice: float64(len(symbol))}, nil
+}
+
+// GetQuotes ...
+func (provider *stubBatchQuoteProvider) GetQuotes(ctx context.Context, symbols []string) (map[string]*StockInfo, error) {
+	provider.lock.Lock()
+	provider.batchRequests = append(provider.batchRequests, symbols)
+	provider.lock.Unlock()
+
+	if provider.batchErr != nil {
+		return nil, provider.batchErr
+	}
+
+	served := map[string]*StockInfo{}
+	for _, symbol := range symbols {
+		if !provider.failedSymbols[symbol] {
+			served[symbol] = &StockInfo{Symbol: symbol, CurrentPrice: float64(len(symbol))}
+		}
+	}
+	return served, nil
+}
+
+// getQuoteRequests returns symbols asked one by one, sorted as the order is not deterministic
+func (provider *stubBatchQuoteProvider) getQuoteRequests() []string {
+	requests := append([]string{}, provider.quoteRequests...)
+	sort.Strings(requests)
+	return requests
+}
+
+func TestGetStockInfos(t *testing.T) {
+	timeService, err := InitTimeSVC()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	batchProvider := &stubBatchQuoteProvider{
+		providerType:  "batch",
+		failedSymbols: map[string]bool{"KRW=X": true, "NONE": true},
+	}
+	fallbackProvider := &stubBatchQuoteProvider{
+		providerType:  "fallback",
+		failedSymbols: map[string]bool{"NONE": true},
+	}
+
+	// the fallback provider does not support multi-symbol requests
+	priceService, err := InitPriceSVC(timeService, []QuoteProvider{batchProvider, &singleQuoteProvider{fallbackProvider}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stockCache, _ := priceService.getStockCache()
+	stockCache.SetDefault("QQQ", &StockInfo{Symbol: "QQQ", CurrentPrice: 300, Provider: "cache"})
+
+	symbols := []string{"NONE", "AAPL", "KRW=X", "QQQ", "AAPL", "SOXL"}
+	results := priceService.GetStockInfos(context.Background(), symbols)
+
+	// cached and duplicated symbols are not asked again
+	if fmt.Sprint(batchProvider.batchRequests) != fmt.Sprint([][]string{{"NONE", "AAPL", "KRW=X", "SOXL"}}) {
+		t.Fatalf("expected a request of missing symbols, got %v", batchProvider.batchRequests)
+	}
+
+	// symbols missing in the batch result are asked to the next provider
+	if fmt.Sprint(fallbackProvider.getQuoteRequests()) != fmt.Sprint([]string{"KRW=X", "NONE"}) {
+		t.Fatalf("expected failed symbols to be asked one by one, got %v", fallbackProvider.quoteRequests)
+	}
+
+	expected := []struct {
+		symbol   string
+		provider QuoteProviderType
+	}{
+		{"NONE", ""},
+		{"AAPL", "batch"},
+		{"KRW=X", "fallback"},
+		{"QQQ", "cache"},
+		{"AAPL", "batch"},
+		{"SOXL", "batch"},
+	}
+
+	if len(results) != len(expected) {
+		t.Fatalf("expected %d results, got %d", len(expected), len(results))
+	}
+
+	for idx, result := range results {
+		if result.Symbol != expected[idx].symbol {
+			t.Fatalf("result %d: expected %s, got %s", idx, expected[idx].symbol, result.Symbol)
+		}
+
+		if len(expected[idx].provider) == 0 {
+			if result.Err == nil || result.StockInfo != nil {
+				t.Fatalf("%s: expected an error, got %v", result.Symbol, result.StockInfo)
+			}
+
+			if !strings.Contains(result.Err.Error(), "batch") || !strings.Contains(result.Err.Error(), "fallback") {
+				t.Fatalf("%s: expected errors of all providers, got %v", result.Symbol, result.Err)
+			}
+			continue
+		}
+
+		if result.Err != nil {
+			t.Fatalf("%s: %v", result.Symbol, result.Err)
+		}
+
+		if result.StockInfo.Symbol != result.Symbol || result.StockInfo.Provider != expected[idx].provider {
+			t.Fatalf("%s: expected a quote from %s, got %v", result.Symbol, expected[idx].provider, result.StockInfo)
+		}
+	}
+
+	// served quotes are cached, errors are not
+	for _, symbol := range []string{"AAPL", "KRW=X", "QQQ", "SOXL"} {
+		if _, ok := stockCache.Get(symbol); !ok {
+			t.Fatalf("%s: expected the quote to be cached", symbol)
+		}
+	}
+	if _, ok := stockCache.Get("NONE"); ok {
+		t.Fatal("failed quote is cached")
+	}
+}
+
+func TestGetStockInfosBatchError(t *testing.T) {
+	timeService, err := InitTimeSVC()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	provider := &stubBatchQuoteProvider{
+		providerType:  "batch",
+		failedSymbols: map[string]bool{"NONE": true},
+		batchErr:      fmt.Errorf("too many symbols"),
+	}
+
+	priceService, err := InitPriceSVC(timeService, []QuoteProvider{provider})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	symbols := []string{"NONE"}
+	for idx := 0; idx < 3*stockInfoFetchWorkers; idx++ {
+		symbols = append(symbols, fmt.Sprintf("S%02d", idx))
+	}
+
+	results := priceService.GetStockInfos(context.Background(), symbols)
+
+	// a failed request falls back to symbols one by one, within the worker limit
+	if len(provider.batchRequests) != 1 || len(provider.quoteRequests) != len(symbols) {
+		t.Fatalf("expected a request and %d quote requests, got %d and %d", len(symbols), len(provider.batchRequests), len(provider.quoteRequests))
+	}
+
+	if provider.maxRunning > stockInfoFetchWorkers {
+		t.Fatalf("expected at most %d concurrent requests, got %d", stockInfoFetchWorkers, provider.maxRunning)
+	}
+
+	if provider.maxRunning < 2 {
+		t.Fatalf("expected concurrent requests, got %d", provider.maxRunning)
+	}
+
+	for idx, result := range results {
+		if result.Symbol != symbols[idx] {
+			t.Fatalf("result %d: expected %s, got %s", idx, symbols[idx], result.Symbol)
+		}
+
+		if (result.Err == nil) != (result.Symbol != "NONE") {
+			t.Fatalf("%s: unexpected error %v", result.Symbol, result.Err)
+		}
+	}
+}
+
+// singleQuoteProvider hides multi-symbol requests of a provider
+type singleQuoteProvider struct {
+	provider QuoteProvider
+}
+
+// GetType ...
+func (provider *singleQuoteProvider) GetType() QuoteProviderType {
+	return provider.provider.GetType()
+}
+
+// GetQuote ...
+func (provider *singleQuoteProvider) GetQuote(ctx context.Context, symbol string) (*StockInfo, error) {
+	return provider.provider.GetQuote(ctx, symbol)
+}
@@ -0,0 +1,129 @@
+package finance_svc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// minorCurrencyUnit is a currency unit that is a fraction of a major currency
+type minorCurrencyUnit struct {
+	Major    string
+	PerMajor float64
+}
+
+var (
+	// minor units yahoo reports prices in, e.g., GBp for stocks in London
+	minorCurrencyUnits = map[string]minorCurrencyUnit{
+		"GBp": {Major: "GBP", PerMajor: 100},
+		"GBX": {Major: "GBP", PerMajor: 100},
+		"ZAc": {Major: "ZAR", PerMajor: 100},
+		"ZAC": {Major: "ZAR", PerMajor: 100},
+		"ILA": {Major: "ILS", PerMajor: 100},
+	}
+)
+
+// GetExchangeRate returns the price of a unit of the from currency in the to currency, e.g.,
+// about 1300 from USD to KRW. Rates are quotes of FX pairs, cached like other quotes.
+// Minor units are converted via their major currency, e.g., GBp is 1/100 of GBP.
+func (svc *PriceSVC) GetExchangeRate(ctx context.Context, from string, to string) (float64, error) {
+	logger := log.WithFields(log.Fields{
+		"package":  "PriceSVC",
+		"function": "GetExchangeRate",
+	})
+
+	from, fromPerMajor := getMajorCurrency(from)
+	to, toPerMajor := getMajorCurrency(to)
+	if from == to {
+		return toPerMajor / fromPerMajor, nil
+	}
+
+	stockInfo, err := svc.GetStockInfo(ctx, getFXSymbol(from, to))
+	if err != nil {
+		logger.Error(err)
+		return 0, err
+	}
+
+	if stockInfo.CurrentPrice <= 0 {
+		return 0, fmt.Errorf("could not get exchange rate - %s to %s, no price", from, to)
+	}
+	return stockInfo.CurrentPrice * toPerMajor / fromPerMajor, nil
+}
+
+// getMajorCurrency returns the major currency of a currency code and how many units of the
+// code make a unit of the major currency, e.g., GBP and 100 for GBp
+func getMajorCurrency(currency string) (string, float64) {
+	// codes of minor units differ from major ones only in case, so they are compared as is
+	if minorUnit, ok := minorCurrencyUnits[currency]; ok {
+		return minorUnit.Major, minorUnit.PerMajor
+	}
+	return strings.ToUpper(currency), 1
+}
+
+// ConvertStockInfo returns a copy of the quote with prices in the currency.
+// Changes are converted at the current rate, so percents stay the same.
+// Index levels are points, not prices, so indexes are returned as is.
+func (svc *PriceSVC) ConvertStockInfo(ctx context.Context, stockInfo *StockInfo, currency string) (*StockInfo, error) {
+	if IsIndexQuote(stockInfo) {
+		return stockInfo, nil
+	}
+
+	stockInfo = ToMajorCurrency(stockInfo)
+
+	currency = strings.ToUpper(currency)
+	if strings.ToUpper(stockInfo.Currency) == currency {
+		return stockInfo, nil
+	}
+
+	if len(stockInfo.Currency) == 0 {
+		return nil, fmt.Errorf("could not convert quote - %s, currency is unknown", stockInfo.Symbol)
+	}
+
+	rate, err := svc.GetExchangeRate(ctx, stockInfo.Currency, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	return scaleStockInfoPrices(stockInfo, rate, currency), nil
+}
+
+// ToMajorCurrency returns a copy of the quote with prices in the major unit if they are in a
+// minor unit, e.g., pence (GBp) to pounds (GBP), the quote itself otherwise
+func ToMajorCurrency(stockInfo *StockInfo) *StockInfo {
+	currency, perMajor := getMajorCurrency(stockInfo.Currency)
+	if perMajor == 1 {
+		return stockInfo
+	}
+	return scaleStockInfoPrices(stockInfo, 1/perMajor, currency)
+}
+
+// IsIndexQuote returns true if the quote is of an index, e.g., ^GSPC, or DX-Y.NYB by its quote type.
+// Index levels are points, not prices in a currency.
+// Without a quote type from the provider, only symbols starting with ^ are known as indexes.
+func IsIndexQuote(stockInfo *StockInfo) bool {
+	return stockInfo.QuoteType == QuoteTypeIndex || strings.HasPrefix(stockInfo.Symbol, "^")
+}
+
+// scaleStockInfoPrices returns a copy of the quote with prices multiplied by the factor.
+// Cached quotes are shared, so they are never modified.
+func scaleStockInfoPrices(stockInfo *StockInfo, factor float64, currency string) *StockInfo {
+	scaled := *stockInfo
+	for _, price := range []*float64{
+		&scaled.CurrentPrice, &scaled.DayLow, &scaled.DayHigh, &scaled.PriceChange,
+		&scaled.PreMarketPrice, &scaled.PreMarketChange, &scaled.PostMarketPrice, &scaled.PostMarketChange,
+		&scaled.PreviousClose, &scaled.Open, &scaled.Bid, &scaled.Ask,
+		&scaled.FiftyTwoWeekHigh, &scaled.FiftyTwoWeekLow, &scaled.MarketCap,
+	} {
+		*price *= factor
+	}
+
+	scaled.Currency = currency
+	return &scaled
+}
+
+// getFXSymbol returns the yahoo symbol of an FX pair, e.g., USDKRW=X
+func getFXSymbol(from string, to string) string {
+	return fmt.Sprintf("%s%s=X", from, to)
+}
@@ -0,0 +1,238 @@
+package finance_svc
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+)
+
+const (
+	fxTestTolerance = 1e-9
+)
+
+// fakeQuoteProvider serves quotes of fixed prices without network
+type fakeQuoteProvider struct {
+	Prices map[string]float64
+}
+
+// GetType ...
+func (provider *fakeQuoteProvider) GetType() QuoteProviderType {
+	return QuoteProviderType("fake")
+}
+
+// GetQuote ...
+func (provider *fakeQuoteProvider) GetQuote(ctx context.Context, symbol string) (*StockInfo, error) {
+	price, ok := provider.Prices[symbol]
+	if !ok {
+		return nil, fmt.Errorf("unknown symbol - %s", symbol)
+	}
+
+	return &StockInfo{
+		Symbol:       symbol,
+		CurrentPrice: price,
+	}, nil
+}
+
+func newFXTestPriceSVC(t *testing.T) *PriceSVC {
+	timeService, err := InitTimeSVC()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	priceService, err := InitPriceSVC(timeService, []QuoteProvider{
+		&fakeQuoteProvider{
+			Prices: map[string]float64{
+				"USDKRW=X": 1300,
+				"GBPUSD=X": 1.25,
+				"GBPKRW=X": 1625,
+				"ZARUSD=X": 0,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return priceService
+}
+
+func TestGetExchangeRate(t *testing.T) {
+	priceService := newFXTestPriceSVC(t)
+
+	tests := []struct {
+		from     string
+		to       string
+		expected float64
+		valid    bool
+	}{
+		{"USD", "USD", 1, true},
+		{"usd", "USD", 1, true},
+		{"USD", "KRW", 1300, true},
+		{"usd", "krw", 1300, true},
+		// minor units are converted via their major currency
+		{"GBp", "GBP", 0.01, true},
+		{"GBP", "GBp", 100, true},
+		{"GBX", "GBp", 1, true},
+		{"GBp", "USD", 0.0125, true},
+		{"GBp", "KRW", 16.25, true},
+		{"ZAc", "USD", 0, false},
+		{"USD", "CHF", 0, false},
+	}
+
+	for _, test := range tests {
+		rate, err := priceService.GetExchangeRate(context.Background(), test.from, test.to)
+		if (err == nil) != test.valid {
+			t.Fatalf("%s to %s: expected valid %v, got error %v", test.from, test.to, test.valid, err)
+		}
+
+		if test.valid && math.Abs(rate-test.expected) > fxTestTolerance {
+			t.Fatalf("%s to %s: expected rate %v, got %v", test.from, test.to, test.expected, rate)
+		}
+	}
+}
+
+func TestConvertStockInfo(t *testing.T) {
+	priceService := newFXTestPriceSVC(t)
+
+	tests := []struct {
+		name             string
+		stockInfo        StockInfo
+		currency         string
+		expectedPrice    float64
+		expectedChange   float64
+		expectedCurrency string
+		valid            bool
+	}{
+		{
+			name:             "same currency",
+			stockInfo:        StockInfo{Symbol: "AAPL", CurrentPrice: 100, PriceChange: 1, Currency: "USD"},
+			currency:         "usd",
+			expectedPrice:    100,
+			expectedChange:   1,
+			expectedCurrency: "USD",
+			valid:            true,
+		},
+		{
+			name:             "dollars to won",
+			stockInfo:        StockInfo{Symbol: "AAPL", CurrentPrice: 100, PriceChange: -2, Currency: "USD"},
+			currency:         "KRW",
+			expectedPrice:    130000,
+			expectedChange:   -2600,
+			expectedCurrency: "KRW",
+			valid:            true,
+		},
+		{
+			name:             "pence to dollars",
+			stockInfo:        StockInfo{Symbol: "VOD.L", CurrentPrice: 7250, PriceChange: 50, Currency: "GBp"},
+			currency:         "USD",
+			expectedPrice:    90.625,
+			expectedChange:   0.625,
+			expectedCurrency: "USD",
+			valid:            true,
+		},
+		{
+			name:             "pence to pounds",
+			stockInfo:        StockInfo{Symbol: "VOD.L", CurrentPrice: 7250, PriceChange: 50, Currency: "GBp"},
+			currency:         "GBP",
+			expectedPrice:    72.5,
+			expectedChange:   0.5,
+			expectedCurrency: "GBP",
+			valid:            true,
+		},
+		{
+			// index levels are points
+			name:             "index",
+			stockInfo:        StockInfo{Symbol: "^GSPC", CurrentPrice: 3000, PriceChange: 10, Currency: "USD"},
+			currency:         "KRW",
+			expectedPrice:    3000,
+			expectedChange:   10,
+			expectedCurrency: "USD",
+			valid:            true,
+		},
+		{
+			name:             "index by quote type",
+			stockInfo:        StockInfo{Symbol: "DX-Y.NYB", CurrentPrice: 100, PriceChange: 1, Currency: "USD", QuoteType: QuoteTypeIndex},
+			currency:         "KRW",
+			expectedPrice:    100,
+			expectedChange:   1,
+			expectedCurrency: "USD",
+			valid:            true,
+		},
+		{
+			// without a quote type, only ^ symbols are known as indexes
+			name:             "index without quote type",
+			stockInfo:        StockInfo{Symbol: "DX-Y.NYB", CurrentPrice: 100, PriceChange: 1, Currency: "USD"},
+			currency:         "KRW",
+			expectedPrice:    130000,
+			expectedChange:   1300,
+			expectedCurrency: "KRW",
+			valid:            true,
+		},
+		{
+			name:      "unknown currency",
+			stockInfo: StockInfo{Symbol: "AAPL", CurrentPrice: 100},
+			currency:  "KRW",
+			valid:     false,
+		},
+		{
+			name:      "no rate",
+			stockInfo: StockInfo{Symbol: "NESN.SW", CurrentPrice: 100, Currency: "CHF"},
+			currency:  "USD",
+			valid:     false,
+		},
+	}
+
+	for _, test := range tests {
+		original := test.stockInfo
+		converted, err := priceService.ConvertStockInfo(context.Background(), &test.stockInfo, test.currency)
+		if (err == nil) != test.valid {
+			t.Fatalf("%s: expected valid %v, got error %v", test.name, test.valid, err)
+		}
+
+		// cached quotes are shared, so they must not be modified
+		if test.stockInfo != original {
+			t.Fatalf("%s: quote is modified - %+v", test.name, test.stockInfo)
+		}
+
+		if !test.valid {
+			continue
+		}
+
+		if math.Abs(converted.CurrentPrice-test.expectedPrice) > fxTestTolerance || math.Abs(converted.PriceChange-test.expectedChange) > fxTestTolerance {
+			t.Fatalf("%s: expected price %v and change %v, got %v and %v", test.name, test.expectedPrice, test.expectedChange, converted.CurrentPrice, converted.PriceChange)
+		}
+
+		if converted.Currency != test.expectedCurrency {
+			t.Fatalf("%s: expected currency %s, got %s", test.name, test.expectedCurrency, converted.Currency)
+		}
+	}
+}
+
+func TestToMajorCurrency(t *testing.T) {
+	tests := []struct {
+		currency         string
+		price            float64
+		expectedPrice    float64
+		expectedCurrency string
+	}{
+		{"USD", 100, 100, "USD"},
+		{"", 100, 100, ""},
+		{"GBp", 7250, 72.5, "GBP"},
+		{"GBX", 7250, 72.5, "GBP"},
+		{"ZAc", 5000, 50, "ZAR"},
+		{"ILA", 1000, 10, "ILS"},
+	}
+
+	for _, test := range tests {
+		stockInfo := &StockInfo{Symbol: "TEST", CurrentPrice: test.price, Currency: test.currency}
+		converted := ToMajorCurrency(stockInfo)
+
+		if math.Abs(converted.CurrentPrice-test.expectedPrice) > fxTestTolerance || converted.Currency != test.expectedCurrency {
+			t.Fatalf("%s: expected %v %s, got %v %s", test.currency, test.expectedPrice, test.expectedCurrency, converted.CurrentPrice, converted.Currency)
+		}
+
+		if stockInfo.CurrentPrice != test.price {
+			t.Fatalf("%s: quote is modified - %+v", test.currency, stockInfo)
+		}
+	}
+}
@@ -1,11 +1,11 @@
 package finance_svc
 
 import (
+	"context"
 	"time"
 
 	cache "github.com/patrickmn/go-cache"
 	log "github.com/sirupsen/logrus"
-	yahoo "github.com/tonymackay/go-yahoo-finance"
 )
 
 const (
@@ -21,6 +21,32 @@ type StockInfo struct {
 	Volume             int
 	PriceChange        float64
 	PriceChangePercent float64
+	// extended hours quotes, zero if not traded or not provided
+	PreMarketPrice          float64
+	PreMarketChange         float64
+	PreMarketChangePercent  float64
+	PostMarketPrice         float64
+	PostMarketChange        float64
+	PostMarketChangePercent float64
+	// PostMarketTime is when the post-market price was traded, zero if not provided
+	PostMarketTime time.Time
+	// fundamentals, zero or empty if not provided
+	PreviousClose    float64
+	Open             float64
+	Bid              float64
+	Ask              float64
+	FiftyTwoWeekHigh float64
+	FiftyTwoWeekLow  float64
+	MarketCap        float64
+	PERatio          float64
+	// DividendYield is a fraction, e.g., 0.005 for 0.5%
+	DividendYield float64
+	AverageVolume int
+	Currency      string
+	// QuoteType is the kind of the security, e.g., EQUITY, ETF or INDEX, empty if not provided
+	QuoteType string
+	// Provider is the quote provider served this info
+	Provider QuoteProviderType
 }
 
 // PriceSVC ...
@@ -28,9 +54,16 @@ type PriceSVC struct {
 	TimeService         *TimeSVC
 	StockCache          *cache.Cache
 	OvernightStockCache *cache.Cache
+	// Providers are tried in order until one serves a quote
+	Providers []QuoteProvider
 }
 
-func InitPriceSVC(timeService *TimeSVC) (*PriceSVC, error) {
+// InitPriceSVC ... yahoo then stooq are used if no provider is given
+func InitPriceSVC(timeService *TimeSVC, providers []QuoteProvider) (*PriceSVC, error) {
+	if len(providers) == 0 {
+		providers = []QuoteProvider{NewYahooQuoteProvider(), NewStooqQuoteProvider()}
+	}
+
 	overnightStockCache := cache.New(cache.NoExpiration, cache.NoExpiration)
 	stockCache := cache.New(stockInfoCacheTimeout, stockInfoCacheTimeout)
 
@@ -38,6 +71,7 @@ func InitPriceSVC(timeService *TimeSVC) (*PriceSVC, error) {
 		TimeService:         timeService,
 		OvernightStockCache: overnightStockCache,
 		StockCache:          stockCache,
+		Providers:           providers,
 	}
 
 	return priceSvc, nil
@@ -50,86 +84,46 @@ func (svc *PriceSVC) Close() error {
 	return nil
 }
 
-// GetStockInfo ...
-func (svc *PriceSVC) GetStockInfo(symbol string) (*StockInfo, error) {
+// GetStockInfo returns the quote of the symbol, requests to providers stop when ctx is cancelled
+func (svc *PriceSVC) GetStockInfo(ctx context.Context, symbol string) (*StockInfo, error) {
 	logger := log.WithFields(log.Fields{
 		"package":  "PriceSVC",
 		"function": "GetStockInfo",
 	})
 
-	if svc.TimeService.GetMarketType(time.Now()) == Overnight {
-		// clear stock cache for daytime
-		svc.StockCache.Flush()
-
-		if cache, ok := svc.OvernightStockCache.Get(symbol); ok {
-			return cache.(*StockInfo), nil
-		} else {
-			stockInfo, err := svc.getStockInfo(symbol)
-			if err != nil {
-				logger.Error(err)
-				return nil, err
-			}
-
-			// no expiration
-			svc.OvernightStockCache.Set(symbol, stockInfo, 12*time.Hour)
-			return stockInfo, nil
-		}
-	} else {
-		svc.OvernightStockCache.Flush()
-
-		if cache, ok := svc.StockCache.Get(symbol); ok {
-			return cache.(*StockInfo), nil
-		} else {
-			stockInfo, err := svc.getStockInfo(symbol)
-			if err != nil {
-				logger.Error(err)
-				return nil, err
-			}
-
-			svc.StockCache.SetDefault(symbol, stockInfo)
-			return stockInfo, nil
-		}
+	stockCache, expiration := svc.getStockCache()
+	if cache, ok := stockCache.Get(symbol); ok {
+		return cache.(*StockInfo), nil
 	}
-}
-
-func (svc *PriceSVC) getStockInfo(symbol string) (*StockInfo, error) {
-	logger := log.WithFields(log.Fields{
-		"package":  "PriceSVC",
-		"function": "getStockInfo",
-	})
 
-	quote, err := yahoo.Quote(symbol)
+	stockInfo, err := svc.getStockInfo(ctx, symbol)
 	if err != nil {
 		logger.Error(err)
 		return nil, err
 	}
 
-	stockInfo := &StockInfo{
-		Symbol:             symbol,
-		StockName:          symbol,
-		CurrentPrice:       0,
-		DayLow:             0,
-		DayHigh:            0,
-		Volume:             0,
-		PriceChange:        0,
-		PriceChangePercent: 0,
+	stockCache.Set(symbol, stockInfo, expiration)
+	return stockInfo, nil
+}
+
+// getStockCache returns the cache for the current market time and its expiration.
+// Quotes do not change overnight, so they are kept until the next day.
+func (svc *PriceSVC) getStockCache() (*cache.Cache, time.Duration) {
+	if svc.TimeService.GetMarketType(time.Now()) == Overnight {
+		// clear stock cache for daytime
+		svc.StockCache.Flush()
+		return svc.OvernightStockCache, 12 * time.Hour
 	}
 
-	if len(quote.QuoteSummary.Result) > 0 {
-		stockInfo.CurrentPrice = quote.QuoteSummary.Result[0].Price.RegularMarketPrice.Raw
-		if len(quote.QuoteSummary.Result[0].Price.LongName) > 0 {
-			stockInfo.StockName = quote.QuoteSummary.Result[0].Price.LongName
-		} else if len(quote.QuoteSummary.Result[0].Price.ShortName) > 0 {
-			stockInfo.StockName = quote.QuoteSummary.Result[0].Price.ShortName
-		} else {
-			stockInfo.StockName = symbol
-		}
-
-		stockInfo.DayLow = quote.QuoteSummary.Result[0].Price.RegularMarketDayLow.Raw
-		stockInfo.DayHigh = quote.QuoteSummary.Result[0].Price.RegularMarketDayHigh.Raw
-		stockInfo.Volume = quote.QuoteSummary.Result[0].Price.RegularMarketVolume.Raw
-		stockInfo.PriceChange = quote.QuoteSummary.Result[0].Price.RegularMarketChange.Raw
-		stockInfo.PriceChangePercent = quote.QuoteSummary.Result[0].Price.RegularMarketChangePercent.Raw
+	svc.OvernightStockCache.Flush()
+	return svc.StockCache, cache.DefaultExpiration
+}
+
+// getStockInfo asks providers in order until one serves the quote
+func (svc *PriceSVC) getStockInfo(ctx context.Context, symbol string) (*StockInfo, error) {
+	stockInfos, errs := svc.getStockInfos(ctx, []string{symbol})
+	if stockInfo, ok := stockInfos[symbol]; ok {
+		return stockInfo, nil
 	}
-	return stockInfo, nil
+	return nil, errs[symbol]
 }
@@ -0,0 +1,171 @@
+package finance_svc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingQuoteProvider serves no quote until ctx is cancelled
+type blockingQuoteProvider struct {
+}
+
+// GetType ...
+func (provider *blockingQuoteProvider) GetType() QuoteProviderType {
+	return QuoteProviderType("blocking")
+}
+
+// GetQuote ...
+func (provider *blockingQuoteProvider) GetQuote(ctx context.Context, symbol string) (*StockInfo, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestGetStockInfoCancel(t *testing.T) {
+	timeService, err := InitTimeSVC()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	priceService, err := InitPriceSVC(timeService, []QuoteProvider{&blockingQuoteProvider{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	done := make(chan error)
+	go func() {
+		_, err := priceService.GetStockInfo(ctx, "AAPL")
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("expected an error of the cancelled request")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("request is not cancelled with its context")
+	}
+
+	// errors are not cached
+	if _, ok := priceService.StockCache.Get("AAPL"); ok {
+		t.Fatal("failed quote is cached")
+	}
+	if _, ok := priceService.OvernightStockCache.Get("AAPL"); ok {
+		t.Fatal("failed quote is cached")
+	}
+
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		t.Fatalf("expected the deadline to pass, got %v", ctx.Err())
+	}
+}
+
+// stubQuoteProvider serves configured prices, symbols without a price fail with err
+type stubQuoteProvider struct {
+	providerType QuoteProviderType
+	prices       map[string]float64
+	err          error
+	// calls records providers asked in order, shared by providers of a test
+	calls *[]QuoteProviderType
+	lock  *sync.Mutex
+}
+
+// GetType ...
+func (provider *stubQuoteProvider) GetType() QuoteProviderType {
+	return provider.providerType
+}
+
+// GetQuote ...
+func (provider *stubQuoteProvider) GetQuote(ctx context.Context, symbol string) (*StockInfo, error) {
+	provider.lock.Lock()
+	*provider.calls = append(*provider.calls, provider.providerType)
+	provider.lock.Unlock()
+
+	price, ok := provider.prices[symbol]
+	if !ok {
+		return nil, provider.err
+	}
+
+	if price < 0 {
+		// a quote without any field
+		return nil, nil
+	}
+	return &StockInfo{Symbol: symbol, CurrentPrice: price}, nil
+}
+
+func TestGetStockInfoFailover(t *testing.T) {
+	timeService, err := InitTimeSVC()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		prices   []map[string]float64
+		expected []QuoteProviderType
+		served   QuoteProviderType
+		price    float64
+	}{
+		{"first", []map[string]float64{{"AAPL": 100}, {"AAPL": 101}}, []QuoteProviderType{"first"}, "first", 100},
+		{"error", []map[string]float64{{}, {"AAPL": 101}}, []QuoteProviderType{"first", "second"}, "second", 101},
+		// quotes without price are not served
+		{"zero price", []map[string]float64{{"AAPL": 0}, {"AAPL": 101}}, []QuoteProviderType{"first", "second"}, "second", 101},
+		{"no quote", []map[string]float64{{"AAPL": -1}, {"AAPL": 101}}, []QuoteProviderType{"first", "second"}, "second", 101},
+		{"last", []map[string]float64{{}, {"AAPL": 0}, {"AAPL": 102}}, []QuoteProviderType{"first", "second", "third"}, "third", 102},
+		{"all failed", []map[string]float64{{}, {"AAPL": -1}, {}}, []QuoteProviderType{"first", "second", "third"}, "", 0},
+	}
+
+	providerTypes := []QuoteProviderType{"first", "second", "third"}
+	for _, test := range tests {
+		calls := []QuoteProviderType{}
+		lock := sync.Mutex{}
+
+		providers := []QuoteProvider{}
+		for idx, prices := range test.prices {
+			providers = append(providers, &stubQuoteProvider{
+				providerType: providerTypes[idx],
+				prices:       prices,
+				err:          fmt.Errorf("%s is down", providerTypes[idx]),
+				calls:        &calls,
+				lock:         &lock,
+			})
+		}
+
+		priceService, err := InitPriceSVC(timeService, providers)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		stockInfo, err := priceService.GetStockInfo(context.Background(), "AAPL")
+		if fmt.Sprint(calls) != fmt.Sprint(test.expected) {
+			t.Fatalf("%s: expected providers %v to be asked, got %v", test.name, test.expected, calls)
+		}
+
+		if len(test.served) == 0 {
+			if err == nil {
+				t.Fatalf("%s: expected an error, got %v", test.name, stockInfo)
+			}
+
+			// the error tells why the last provider failed
+			if !strings.HasSuffix(err.Error(), "third is down") {
+				t.Fatalf("%s: expected the error of the last provider, got %v", test.name, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		if stockInfo.Provider != test.served || stockInfo.CurrentPrice != test.price {
+			t.Fatalf("%s: expected %v from %s, got %v from %s", test.name, test.price, test.served, stockInfo.CurrentPrice, stockInfo.Provider)
+		}
+	}
+}
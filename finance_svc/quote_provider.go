@@ -0,0 +1,71 @@
+package finance_svc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// QuoteProviderType is a kind of quote provider
+type QuoteProviderType string
+
+const (
+	// QuoteProviderYahoo gets quotes from yahoo finance
+	QuoteProviderYahoo QuoteProviderType = "yahoo"
+	// QuoteProviderStooq gets quotes from stooq csv
+	QuoteProviderStooq QuoteProviderType = "stooq"
+)
+
+const (
+	// QuoteTypeIndex is the quote type of indexes, e.g., DX-Y.NYB
+	QuoteTypeIndex string = "INDEX"
+)
+
+// QuoteProvider gets the current quote of a stock from a data source
+type QuoteProvider interface {
+	// GetType returns type of the provider
+	GetType() QuoteProviderType
+	// GetQuote returns the current quote of the symbol, the request stops when ctx is cancelled
+	GetQuote(ctx context.Context, symbol string) (*StockInfo, error)
+}
+
+// BatchQuoteProvider is a QuoteProvider able to get quotes of multiple symbols in a request
+type BatchQuoteProvider interface {
+	QuoteProvider
+	// GetQuotes returns quotes of the symbols by symbol, symbols missing in the result are not served
+	GetQuotes(ctx context.Context, symbols []string) (map[string]*StockInfo, error)
+}
+
+// NewQuoteProvider creates a quote provider of the given type
+func NewQuoteProvider(providerType QuoteProviderType) (QuoteProvider, error) {
+	switch providerType {
+	case QuoteProviderYahoo:
+		return NewYahooQuoteProvider(), nil
+	case QuoteProviderStooq:
+		return NewStooqQuoteProvider(), nil
+	default:
+		return nil, fmt.Errorf("unknown quote provider - %s", providerType)
+	}
+}
+
+// NewQuoteProviders creates quote providers from comma separated types in failover order, e.g., "yahoo,stooq"
+func NewQuoteProviders(providerTypes string) ([]QuoteProvider, error) {
+	providers := []QuoteProvider{}
+	for _, field := range strings.Split(providerTypes, ",") {
+		providerType := strings.ToLower(strings.TrimSpace(field))
+		if len(providerType) == 0 {
+			continue
+		}
+
+		provider, err := NewQuoteProvider(QuoteProviderType(providerType))
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, provider)
+	}
+
+	if len(providers) == 0 {
+		return nil, fmt.Errorf("no quote provider is given - %s", providerTypes)
+	}
+	return providers, nil
+}
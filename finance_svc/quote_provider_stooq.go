@@ -0,0 +1,250 @@
+package finance_svc
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	stooqQuoteURL = "https://stooq.com/q/l/"
+	// symbol, date, time, open, high, low, close, volume, previous close, name
+	stooqQuoteFields = "sd2t2ohlcvpn"
+	stooqNoData      = "N/D"
+)
+
+// columns of a stooq quote
+const (
+	stooqColumnSymbol = iota
+	stooqColumnDate
+	stooqColumnTime
+	stooqColumnOpen
+	stooqColumnHigh
+	stooqColumnLow
+	stooqColumnClose
+	stooqColumnVolume
+	stooqColumnPrevClose
+	stooqColumnName
+	stooqColumnCount
+)
+
+var (
+	// yahoo index symbols to stooq index symbols
+	stooqIndexSymbols = map[string]string{
+		"^GSPC": "^spx",
+		"^DJI":  "^dji",
+		"^IXIC": "^ndq",
+	}
+
+	stooqUSSymbolRegex     = regexp.MustCompile(`^[A-Za-z]+([.-][A-Za-z])?$`)
+	stooqCryptoSymbolRegex = regexp.MustCompile(`^([A-Za-z]+)-USD$`)
+	stooqFXSymbolRegex     = regexp.MustCompile(`^([A-Za-z]{6})=X$`)
+)
+
+// StooqQuoteProvider gets quotes from stooq csv.
+// It only serves US stocks, major US indexes, crypto in USD and FX pairs.
+type StooqQuoteProvider struct {
+}
+
+// NewStooqQuoteProvider creates a StooqQuoteProvider
+func NewStooqQuoteProvider() *StooqQuoteProvider {
+	return &StooqQuoteProvider{}
+}
+
+// GetType ...
+func (provider *StooqQuoteProvider) GetType() QuoteProviderType {
+	return QuoteProviderStooq
+}
+
+// GetQuote ...
+func (provider *StooqQuoteProvider) GetQuote(ctx context.Context, symbol string) (*StockInfo, error) {
+	logger := log.WithFields(log.Fields{
+		"package":  "StooqQuoteProvider",
+		"function": "GetQuote",
+	})
+
+	stooqSymbol, err := getStooqSymbol(symbol)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	records, err := fetchStooqQuotes(ctx, []string{stooqSymbol})
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	if len(records) == 0 {
+		return nil, fmt.Errorf("could not get quote - %s, empty result", symbol)
+	}
+
+	stockInfo, err := parseStooqQuote(symbol, records[0])
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	return stockInfo, nil
+}
+
+// GetQuotes gets quotes of all supported symbols in a request
+func (provider *StooqQuoteProvider) GetQuotes(ctx context.Context, symbols []string) (map[string]*StockInfo, error) {
+	logger := log.WithFields(log.Fields{
+		"package":  "StooqQuoteProvider",
+		"function": "GetQuotes",
+	})
+
+	// stooq symbols to requested symbols
+	requestedSymbols := map[string]string{}
+	stooqSymbols := []string{}
+	for _, symbol := range symbols {
+		stooqSymbol, err := getStooqSymbol(symbol)
+		if err != nil {
+			logger.Debug(err)
+			continue
+		}
+
+		requestedSymbols[stooqSymbol] = symbol
+		stooqSymbols = append(stooqSymbols, stooqSymbol)
+	}
+
+	if len(stooqSymbols) == 0 {
+		return nil, fmt.Errorf("no symbol is supported by stooq - %s", strings.Join(symbols, ","))
+	}
+
+	records, err := fetchStooqQuotes(ctx, stooqSymbols)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	stockInfos := map[string]*StockInfo{}
+	for _, record := range records {
+		symbol, ok := requestedSymbols[strings.ToLower(record[stooqColumnSymbol])]
+		if !ok {
+			continue
+		}
+
+		stockInfo, err := parseStooqQuote(symbol, record)
+		if err != nil {
+			logger.Debug(err)
+			continue
+		}
+		stockInfos[symbol] = stockInfo
+	}
+	return stockInfos, nil
+}
+
+// fetchStooqQuotes downloads quotes of stooq symbols, returns csv records without the header line
+func fetchStooqQuotes(ctx context.Context, stooqSymbols []string) ([][]string, error) {
+	query := url.Values{}
+	query.Set("s", strings.Join(stooqSymbols, " "))
+	query.Set("f", stooqQuoteFields)
+	query.Set("h", "")
+	query.Set("e", "csv")
+
+	// stooq separates symbols with '+', which is how spaces are encoded
+	body, err := httpGet(ctx, fmt.Sprintf("%s?%s", stooqQuoteURL, query.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(bytes.NewReader(body))
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	dataRecords := [][]string{}
+	for idx, record := range records {
+		if idx == 0 || len(record) < stooqColumnCount {
+			// header line or a malformed line
+			continue
+		}
+		dataRecords = append(dataRecords, record)
+	}
+	return dataRecords, nil
+}
+
+// getStooqSymbol converts a yahoo symbol to a stooq symbol, e.g., AAPL to aapl.us
+func getStooqSymbol(symbol string) (string, error) {
+	if stooqSymbol, ok := stooqIndexSymbols[symbol]; ok {
+		return stooqSymbol, nil
+	}
+
+	if matches := stooqCryptoSymbolRegex.FindStringSubmatch(symbol); matches != nil {
+		return strings.ToLower(matches[1]) + "usd", nil
+	}
+
+	if matches := stooqFXSymbolRegex.FindStringSubmatch(symbol); matches != nil {
+		return strings.ToLower(matches[1]), nil
+	}
+
+	if stooqUSSymbolRegex.MatchString(symbol) {
+		return strings.ToLower(strings.ReplaceAll(symbol, ".", "-")) + ".us", nil
+	}
+
+	return "", fmt.Errorf("symbol is not supported by stooq - %s", symbol)
+}
+
+// getStooqCurrency returns the currency of prices of a symbol served by stooq,
+// the quote currency for FX pairs and USD for others
+func getStooqCurrency(symbol string) string {
+	if matches := stooqFXSymbolRegex.FindStringSubmatch(symbol); matches != nil {
+		return strings.ToUpper(matches[1][3:])
+	}
+	return "USD"
+}
+
+// parseStooqQuote converts a csv record of stooq to a quote of the symbol
+func parseStooqQuote(symbol string, record []string) (*StockInfo, error) {
+	var err error
+	values := map[int]float64{}
+	for _, column := range []int{stooqColumnOpen, stooqColumnHigh, stooqColumnLow, stooqColumnClose, stooqColumnPrevClose} {
+		value := record[column]
+		if value == stooqNoData {
+			return nil, fmt.Errorf("could not get quote - %s, no data", symbol)
+		}
+
+		values[column], err = strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse quote - %s, %s", symbol, value)
+		}
+	}
+
+	// indexes and crypto may have no volume
+	if volume, err := strconv.ParseFloat(record[stooqColumnVolume], 64); err == nil {
+		values[stooqColumnVolume] = volume
+	}
+
+	stockInfo := &StockInfo{
+		Symbol:       symbol,
+		StockName:    symbol,
+		CurrentPrice: values[stooqColumnClose],
+		DayLow:       values[stooqColumnLow],
+		DayHigh:      values[stooqColumnHigh],
+		Volume:       int(values[stooqColumnVolume]),
+		PriceChange:  values[stooqColumnClose] - values[stooqColumnPrevClose],
+		// stooq has no other fundamentals
+		PreviousClose: values[stooqColumnPrevClose],
+		Open:          values[stooqColumnOpen],
+		Currency:      getStooqCurrency(symbol),
+	}
+
+	if name := strings.TrimSpace(record[stooqColumnName]); len(name) > 0 && name != stooqNoData {
+		stockInfo.StockName = name
+	}
+
+	if values[stooqColumnPrevClose] != 0 {
+		stockInfo.PriceChangePercent = stockInfo.PriceChange / values[stooqColumnPrevClose]
+	}
+	return stockInfo, nil
+}
@@ -0,0 +1,57 @@
+package finance_svc
+
+import (
+	"testing"
+)
+
+func TestParseStooqQuote(t *testing.T) {
+	tests := []struct {
+		name     string
+		symbol   string
+		record   []string
+		expected StockInfo
+		valid    bool
+	}{
+		{
+			"stock",
+			"AAPL",
+			[]string{"AAPL.US", "2021-01-08", "22:00:09", "119.1", "121.2", "118.5", "120.5", "95000000", "118.72", "APPLE"},
+			StockInfo{Symbol: "AAPL", StockName: "APPLE", CurrentPrice: 120.5, DayLow: 118.5, DayHigh: 121.2, Volume: 95000000, PreviousClose: 118.72, Open: 119.1, Currency: "USD"},
+			true,
+		},
+		// indexes have no volume, FX pairs are priced in the quote currency
+		{
+			"no volume",
+			"USDKRW=X",
+			[]string{"USDKRW", "2021-01-08", "22:00:09", "1090", "1095", "1085", "1092", "N/D", "1090", "N/D"},
+			StockInfo{Symbol: "USDKRW=X", StockName: "USDKRW=X", CurrentPrice: 1092, DayLow: 1085, DayHigh: 1095, PreviousClose: 1090, Open: 1090, Currency: "KRW"},
+			true,
+		},
+		{"no data", "AAPL", []string{"AAPL.US", "N/D", "N/D", "N/D", "N/D", "N/D", "N/D", "N/D", "N/D", "N/D"}, StockInfo{}, false},
+		{"malformed", "AAPL", []string{"AAPL.US", "2021-01-08", "22:00:09", "119.1", "x", "118.5", "120.5", "95000000", "118.72", "APPLE"}, StockInfo{}, false},
+	}
+
+	for _, test := range tests {
+		stockInfo, err := parseStooqQuote(test.symbol, test.record)
+		if (err == nil) != test.valid {
+			t.Fatalf("%s: expected valid %v, got error %v", test.name, test.valid, err)
+		}
+
+		if !test.valid {
+			continue
+		}
+
+		// the change is from the previous close
+		actual := *stockInfo
+		actual.PriceChange = 0
+		actual.PriceChangePercent = 0
+		if actual != test.expected {
+			t.Fatalf("%s: expected %+v, got %+v", test.name, test.expected, actual)
+		}
+
+		expectedChange := test.expected.CurrentPrice - test.expected.PreviousClose
+		if stockInfo.PriceChange != expectedChange || stockInfo.PriceChangePercent != expectedChange/test.expected.PreviousClose {
+			t.Fatalf("%s: expected change %v, got %v (%v)", test.name, expectedChange, stockInfo.PriceChange, stockInfo.PriceChangePercent)
+		}
+	}
+}
@@ -0,0 +1,313 @@
+package finance_svc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	yahooQuoteSummaryURL = "https://query2.finance.yahoo.com/v10/finance/quoteSummary/"
+	yahooQuoteURL        = "https://query1.finance.yahoo.com/v7/finance/quote"
+)
+
+// yahooRawValue is a number with its formatted text in quoteSummary
+type yahooRawValue struct {
+	Raw float64 `json:"raw"`
+}
+
+type yahooQuoteSummaryResult struct {
+	QuoteSummary struct {
+		Result []struct {
+			Price struct {
+				LongName                   string        `json:"longName"`
+				ShortName                  string        `json:"shortName"`
+				RegularMarketPrice         yahooRawValue `json:"regularMarketPrice"`
+				RegularMarketDayLow        yahooRawValue `json:"regularMarketDayLow"`
+				RegularMarketDayHigh       yahooRawValue `json:"regularMarketDayHigh"`
+				RegularMarketVolume        yahooRawValue `json:"regularMarketVolume"`
+				RegularMarketChange        yahooRawValue `json:"regularMarketChange"`
+				RegularMarketChangePercent yahooRawValue `json:"regularMarketChangePercent"`
+				PreMarketPrice             yahooRawValue `json:"preMarketPrice"`
+				PreMarketChange            yahooRawValue `json:"preMarketChange"`
+				PreMarketChangePercent     yahooRawValue `json:"preMarketChangePercent"`
+				PostMarketPrice            yahooRawValue `json:"postMarketPrice"`
+				PostMarketChange           yahooRawValue `json:"postMarketChange"`
+				PostMarketChangePercent    yahooRawValue `json:"postMarketChangePercent"`
+				PostMarketTime             int64         `json:"postMarketTime"`
+				RegularMarketPreviousClose yahooRawValue `json:"regularMarketPreviousClose"`
+				RegularMarketOpen          yahooRawValue `json:"regularMarketOpen"`
+				MarketCap                  yahooRawValue `json:"marketCap"`
+				Currency                   string        `json:"currency"`
+				QuoteType                  string        `json:"quoteType"`
+			} `json:"price"`
+			SummaryDetail struct {
+				Bid              yahooRawValue `json:"bid"`
+				Ask              yahooRawValue `json:"ask"`
+				FiftyTwoWeekHigh yahooRawValue `json:"fiftyTwoWeekHigh"`
+				FiftyTwoWeekLow  yahooRawValue `json:"fiftyTwoWeekLow"`
+				TrailingPE       yahooRawValue `json:"trailingPE"`
+				DividendYield    yahooRawValue `json:"dividendYield"`
+				AverageVolume    yahooRawValue `json:"averageVolume"`
+			} `json:"summaryDetail"`
+			CalendarEvents struct {
+				Earnings struct {
+					// EarningsDate is a single date once announced, an estimated window before
+					EarningsDate []yahooRawValue `json:"earningsDate"`
+				} `json:"earnings"`
+			} `json:"calendarEvents"`
+		} `json:"result"`
+		Error *struct {
+			Code        string `json:"code"`
+			Description string `json:"description"`
+		} `json:"error"`
+	} `json:"quoteSummary"`
+}
+
+type yahooQuoteResult struct {
+	QuoteResponse struct {
+		Result []struct {
+			Symbol                     string  `json:"symbol"`
+			LongName                   string  `json:"longName"`
+			ShortName                  string  `json:"shortName"`
+			RegularMarketPrice         float64 `json:"regularMarketPrice"`
+			RegularMarketDayLow        float64 `json:"regularMarketDayLow"`
+			RegularMarketDayHigh       float64 `json:"regularMarketDayHigh"`
+			RegularMarketVolume        int     `json:"regularMarketVolume"`
+			RegularMarketChange        float64 `json:"regularMarketChange"`
+			RegularMarketChangePercent float64 `json:"regularMarketChangePercent"`
+			PreMarketPrice             float64 `json:"preMarketPrice"`
+			PreMarketChange            float64 `json:"preMarketChange"`
+			PreMarketChangePercent     float64 `json:"preMarketChangePercent"`
+			PostMarketPrice            float64 `json:"postMarketPrice"`
+			PostMarketChange           float64 `json:"postMarketChange"`
+			PostMarketChangePercent    float64 `json:"postMarketChangePercent"`
+			PostMarketTime             int64   `json:"postMarketTime"`
+			RegularMarketPreviousClose float64 `json:"regularMarketPreviousClose"`
+			RegularMarketOpen          float64 `json:"regularMarketOpen"`
+			Bid                        float64 `json:"bid"`
+			Ask                        float64 `json:"ask"`
+			FiftyTwoWeekHigh           float64 `json:"fiftyTwoWeekHigh"`
+			FiftyTwoWeekLow            float64 `json:"fiftyTwoWeekLow"`
+			MarketCap                  float64 `json:"marketCap"`
+			TrailingPE                 float64 `json:"trailingPE"`
+			DividendYield              float64 `json:"dividendYield"`
+			AverageDailyVolume3Month   int     `json:"averageDailyVolume3Month"`
+			Currency                   string  `json:"currency"`
+			QuoteType                  string  `json:"quoteType"`
+		} `json:"result"`
+		Error *struct {
+			Code        string `json:"code"`
+			Description string `json:"description"`
+		} `json:"error"`
+	} `json:"quoteResponse"`
+}
+
+// YahooQuoteProvider gets quotes from yahoo finance
+type YahooQuoteProvider struct {
+}
+
+// NewYahooQuoteProvider creates a YahooQuoteProvider
+func NewYahooQuoteProvider() *YahooQuoteProvider {
+	return &YahooQuoteProvider{}
+}
+
+// GetType ...
+func (provider *YahooQuoteProvider) GetType() QuoteProviderType {
+	return QuoteProviderYahoo
+}
+
+// GetQuote ...
+func (provider *YahooQuoteProvider) GetQuote(ctx context.Context, symbol string) (*StockInfo, error) {
+	logger := log.WithFields(log.Fields{
+		"package":  "YahooQuoteProvider",
+		"function": "GetQuote",
+	})
+
+	query := url.Values{}
+	query.Set("modules", "price,summaryDetail")
+
+	body, err := httpGet(ctx, yahooQuoteSummaryURL+url.PathEscape(strings.ToUpper(symbol))+"?"+query.Encode())
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	stockInfo, err := parseYahooQuoteSummary(symbol, body)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	return stockInfo, nil
+}
+
+// parseYahooQuoteSummary converts price and summaryDetail modules of quoteSummary to a quote of the symbol
+func parseYahooQuoteSummary(symbol string, body []byte) (*StockInfo, error) {
+	quote := yahooQuoteSummaryResult{}
+	err := json.Unmarshal(body, &quote)
+	if err != nil {
+		return nil, err
+	}
+
+	if quote.QuoteSummary.Error != nil {
+		return nil, fmt.Errorf("could not get quote - %s, %s", symbol, quote.QuoteSummary.Error.Description)
+	}
+
+	// an empty result lets the next provider serve the quote
+	if len(quote.QuoteSummary.Result) == 0 {
+		return nil, fmt.Errorf("could not get quote - %s, empty result", symbol)
+	}
+
+	price := quote.QuoteSummary.Result[0].Price
+	detail := quote.QuoteSummary.Result[0].SummaryDetail
+	stockInfo := &StockInfo{
+		Symbol:                  symbol,
+		StockName:               symbol,
+		CurrentPrice:            price.RegularMarketPrice.Raw,
+		DayLow:                  price.RegularMarketDayLow.Raw,
+		DayHigh:                 price.RegularMarketDayHigh.Raw,
+		Volume:                  int(price.RegularMarketVolume.Raw),
+		PriceChange:             price.RegularMarketChange.Raw,
+		PriceChangePercent:      price.RegularMarketChangePercent.Raw,
+		PreMarketPrice:          price.PreMarketPrice.Raw,
+		PreMarketChange:         price.PreMarketChange.Raw,
+		PreMarketChangePercent:  price.PreMarketChangePercent.Raw,
+		PostMarketPrice:         price.PostMarketPrice.Raw,
+		PostMarketChange:        price.PostMarketChange.Raw,
+		PostMarketChangePercent: price.PostMarketChangePercent.Raw,
+		PostMarketTime:          parseYahooTime(price.PostMarketTime),
+		PreviousClose:           price.RegularMarketPreviousClose.Raw,
+		Open:                    price.RegularMarketOpen.Raw,
+		Bid:                     detail.Bid.Raw,
+		Ask:                     detail.Ask.Raw,
+		FiftyTwoWeekHigh:        detail.FiftyTwoWeekHigh.Raw,
+		FiftyTwoWeekLow:         detail.FiftyTwoWeekLow.Raw,
+		MarketCap:               price.MarketCap.Raw,
+		PERatio:                 detail.TrailingPE.Raw,
+		DividendYield:           detail.DividendYield.Raw,
+		AverageVolume:           int(detail.AverageVolume.Raw),
+		Currency:                price.Currency,
+		QuoteType:               price.QuoteType,
+	}
+
+	if len(price.LongName) > 0 {
+		stockInfo.StockName = price.LongName
+	} else if len(price.ShortName) > 0 {
+		stockInfo.StockName = price.ShortName
+	}
+	return stockInfo, nil
+}
+
+// GetQuotes gets quotes of the symbols in a request
+func (provider *YahooQuoteProvider) GetQuotes(ctx context.Context, symbols []string) (map[string]*StockInfo, error) {
+	logger := log.WithFields(log.Fields{
+		"package":  "YahooQuoteProvider",
+		"function": "GetQuotes",
+	})
+
+	// yahoo answers with upper-case symbols
+	yahooSymbols := []string{}
+	seen := map[string]bool{}
+	for _, symbol := range symbols {
+		yahooSymbol := strings.ToUpper(symbol)
+		if !seen[yahooSymbol] {
+			seen[yahooSymbol] = true
+			yahooSymbols = append(yahooSymbols, yahooSymbol)
+		}
+	}
+
+	query := url.Values{}
+	query.Set("symbols", strings.Join(yahooSymbols, ","))
+
+	body, err := httpGet(ctx, yahooQuoteURL+"?"+query.Encode())
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	stockInfos, err := parseYahooQuotes(symbols, body)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	return stockInfos, nil
+}
+
+// parseYahooQuotes converts a v7 quote response to quotes by the requested symbols, in the
+// spelling of the request, e.g., aapl for AAPL in the response
+func parseYahooQuotes(symbols []string, body []byte) (map[string]*StockInfo, error) {
+	result := yahooQuoteResult{}
+	err := json.Unmarshal(body, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.QuoteResponse.Error != nil {
+		return nil, fmt.Errorf("could not get quotes - %s, %s", strings.Join(symbols, ","), result.QuoteResponse.Error.Description)
+	}
+
+	// symbols in the response to requested symbols
+	requestedSymbols := map[string][]string{}
+	for _, symbol := range symbols {
+		yahooSymbol := strings.ToUpper(symbol)
+		requestedSymbols[yahooSymbol] = append(requestedSymbols[yahooSymbol], symbol)
+	}
+
+	stockInfos := map[string]*StockInfo{}
+	for _, quote := range result.QuoteResponse.Result {
+		for _, symbol := range requestedSymbols[strings.ToUpper(quote.Symbol)] {
+			stockInfo := &StockInfo{
+				Symbol:       symbol,
+				StockName:    symbol,
+				CurrentPrice: quote.RegularMarketPrice,
+				DayLow:       quote.RegularMarketDayLow,
+				DayHigh:      quote.RegularMarketDayHigh,
+				Volume:       quote.RegularMarketVolume,
+				PriceChange:  quote.RegularMarketChange,
+				// percents in this api, fractions in quoteSummary
+				PriceChangePercent:      quote.RegularMarketChangePercent / 100,
+				PreMarketPrice:          quote.PreMarketPrice,
+				PreMarketChange:         quote.PreMarketChange,
+				PreMarketChangePercent:  quote.PreMarketChangePercent / 100,
+				PostMarketPrice:         quote.PostMarketPrice,
+				PostMarketChange:        quote.PostMarketChange,
+				PostMarketChangePercent: quote.PostMarketChangePercent / 100,
+				PostMarketTime:          parseYahooTime(quote.PostMarketTime),
+				PreviousClose:           quote.RegularMarketPreviousClose,
+				Open:                    quote.RegularMarketOpen,
+				Bid:                     quote.Bid,
+				Ask:                     quote.Ask,
+				FiftyTwoWeekHigh:        quote.FiftyTwoWeekHigh,
+				FiftyTwoWeekLow:         quote.FiftyTwoWeekLow,
+				MarketCap:               quote.MarketCap,
+				PERatio:                 quote.TrailingPE,
+				DividendYield:           quote.DividendYield / 100,
+				AverageVolume:           quote.AverageDailyVolume3Month,
+				Currency:                quote.Currency,
+				QuoteType:               quote.QuoteType,
+			}
+
+			if len(quote.LongName) > 0 {
+				stockInfo.StockName = quote.LongName
+			} else if len(quote.ShortName) > 0 {
+				stockInfo.StockName = quote.ShortName
+			}
+
+			stockInfos[symbol] = stockInfo
+		}
+	}
+	return stockInfos, nil
+}
+
+// parseYahooTime converts unix seconds to a time, zero if not provided
+func parseYahooTime(timestamp int64) time.Time {
+	if timestamp == 0 {
+		return time.Time{}
+	}
+	return time.Unix(timestamp, 0).UTC()
+}
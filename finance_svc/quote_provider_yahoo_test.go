@@ -0,0 +1,163 @@
+package finance_svc
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	yahooQuotesTestBody = `{"quoteResponse":{"result":[
+		{"symbol":"AAPL","longName":"Apple Inc.","regularMarketPrice":120.5,"regularMarketChangePercent":1.5,"currency":"USD"},
+		{"symbol":"^GSPC","shortName":"S&P 500","regularMarketPrice":3800,"currency":"USD","quoteType":"INDEX"},
+		{"symbol":"MSFT","regularMarketPrice":230,"currency":"USD"}
+	],"error":null}}`
+	yahooQuotesErrorTestBody = `{"quoteResponse":{"result":[],"error":{"code":"Bad Request","description":"Missing value for the \"symbols\" argument"}}}`
+)
+
+func TestParseYahooQuotes(t *testing.T) {
+	tests := []struct {
+		name     string
+		symbols  []string
+		body     string
+		expected []string
+		valid    bool
+	}{
+		{"upper-case", []string{"AAPL", "^GSPC"}, yahooQuotesTestBody, []string{"AAPL", "^GSPC"}, true},
+		// yahoo answers with upper-case symbols, quotes are keyed by the requested spelling
+		{"lower-case", []string{"aapl", "^gspc"}, yahooQuotesTestBody, []string{"^gspc", "aapl"}, true},
+		{"both spellings", []string{"aapl", "AAPL"}, yahooQuotesTestBody, []string{"AAPL", "aapl"}, true},
+		{"not in response", []string{"AAPL", "NOPE"}, yahooQuotesTestBody, []string{"AAPL"}, true},
+		{"error", []string{"AAPL"}, yahooQuotesErrorTestBody, nil, false},
+		{"malformed", []string{"AAPL"}, `{"quoteResponse":`, nil, false},
+	}
+
+	for _, test := range tests {
+		stockInfos, err := parseYahooQuotes(test.symbols, []byte(test.body))
+		if (err == nil) != test.valid {
+			t.Fatalf("%s: expected valid %v, got error %v", test.name, test.valid, err)
+		}
+
+		if !test.valid {
+			continue
+		}
+
+		symbols := []string{}
+		for symbol, stockInfo := range stockInfos {
+			if stockInfo.Symbol != symbol {
+				t.Fatalf("%s: quote of %s has symbol %s", test.name, symbol, stockInfo.Symbol)
+			}
+			symbols = append(symbols, symbol)
+		}
+		sort.Strings(symbols)
+
+		if strings.Join(symbols, ",") != strings.Join(test.expected, ",") {
+			t.Fatalf("%s: expected quotes of %v, got %v", test.name, test.expected, symbols)
+		}
+	}
+
+	stockInfos, err := parseYahooQuotes([]string{"aapl"}, []byte(yahooQuotesTestBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// percents in this api are fractions in StockInfo
+	stockInfo := stockInfos["aapl"]
+	if stockInfo.StockName != "Apple Inc." || stockInfo.CurrentPrice != 120.5 || stockInfo.PriceChangePercent != 0.015 {
+		t.Fatalf("unexpected quote - %+v", stockInfo)
+	}
+
+	stockInfos, err = parseYahooQuotes([]string{"^GSPC"}, []byte(yahooQuotesTestBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !IsIndexQuote(stockInfos["^GSPC"]) || stockInfos["^GSPC"].QuoteType != QuoteTypeIndex {
+		t.Fatalf("expected an index quote - %+v", stockInfos["^GSPC"])
+	}
+}
+
+const (
+	yahooQuoteSummaryTestBody = `{"quoteSummary":{"result":[{
+		"price":{"longName":"Apple Inc.","regularMarketPrice":{"raw":120.5,"fmt":"120.50"},"regularMarketChangePercent":{"raw":0.015},
+			"regularMarketVolume":{"raw":95000000},"regularMarketPreviousClose":{"raw":118.72},"regularMarketOpen":{"raw":119.1},
+			"marketCap":{"raw":2.05e12,"fmt":"2.05T"},"postMarketTime":1610150399,"currency":"USD","quoteType":"EQUITY"},
+		"summaryDetail":{"bid":{"raw":120.4},"ask":{"raw":120.6},"fiftyTwoWeekHigh":{"raw":138.79},"fiftyTwoWeekLow":{"raw":53.15},
+			"trailingPE":{"raw":36.5},"dividendYield":{"raw":0.0068},"averageVolume":{"raw":110000000}}
+	}],"error":null}}`
+	yahooFundamentalsTestBody = `{"quoteResponse":{"result":[
+		{"symbol":"AAPL","regularMarketPrice":120.5,"regularMarketChangePercent":1.5,"regularMarketVolume":95000000,
+			"regularMarketPreviousClose":118.72,"regularMarketOpen":119.1,"bid":120.4,"ask":120.6,"fiftyTwoWeekHigh":138.79,"fiftyTwoWeekLow":53.15,
+			"marketCap":2.05e12,"trailingPE":36.5,"dividendYield":0.68,"averageDailyVolume3Month":110000000,"postMarketTime":1610150399,"currency":"USD","quoteType":"EQUITY"}
+	],"error":null}}`
+)
+
+func TestParseYahooFundamentals(t *testing.T) {
+	expected := StockInfo{
+		CurrentPrice:       120.5,
+		PriceChangePercent: 0.015,
+		Volume:             95000000,
+		PreviousClose:      118.72,
+		Open:               119.1,
+		Bid:                120.4,
+		Ask:                120.6,
+		FiftyTwoWeekHigh:   138.79,
+		FiftyTwoWeekLow:    53.15,
+		MarketCap:          2.05e12,
+		PERatio:            36.5,
+		DividendYield:      0.0068,
+		AverageVolume:      110000000,
+		Currency:           "USD",
+		QuoteType:          "EQUITY",
+		PostMarketTime:     time.Unix(1610150399, 0).UTC(),
+	}
+
+	summaryInfo, err := parseYahooQuoteSummary("AAPL", []byte(yahooQuoteSummaryTestBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	quoteInfos, err := parseYahooQuotes([]string{"AAPL"}, []byte(yahooFundamentalsTestBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// both apis serve the same fundamentals, yields are fractions
+	for name, stockInfo := range map[string]*StockInfo{"quoteSummary": summaryInfo, "quote": quoteInfos["AAPL"]} {
+		actual := *stockInfo
+		actual.Symbol = ""
+		actual.StockName = ""
+		actual.PriceChangePercent = math.Round(actual.PriceChangePercent*1e6) / 1e6
+		actual.DividendYield = math.Round(actual.DividendYield*1e6) / 1e6
+
+		if actual != expected {
+			t.Fatalf("%s: expected %+v, got %+v", name, expected, actual)
+		}
+	}
+
+	if summaryInfo.StockName != "Apple Inc." {
+		t.Fatalf("expected the long name, got %s", summaryInfo.StockName)
+	}
+
+	// fields not provided are zero
+	stockInfo, err := parseYahooQuoteSummary("^GSPC", []byte(`{"quoteSummary":{"result":[{"price":{"shortName":"S&P 500","regularMarketPrice":{"raw":3800},"quoteType":"INDEX"}}],"error":null}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stockInfo.StockName != "S&P 500" || stockInfo.CurrentPrice != 3800 || stockInfo.MarketCap != 0 || stockInfo.PERatio != 0 || stockInfo.DividendYield != 0 || stockInfo.Bid != 0 {
+		t.Fatalf("unexpected quote - %+v", stockInfo)
+	}
+
+	for _, body := range []string{
+		`{"quoteSummary":{"result":null,"error":{"code":"Not Found","description":"Quote not found for ticker symbol: NOPE"}}}`,
+		`{"quoteSummary":{"result":[],"error":null}}`,
+		`{"quoteSummary":`,
+	} {
+		if _, err := parseYahooQuoteSummary("NOPE", []byte(body)); err == nil {
+			t.Fatalf("expected an error of %s", body)
+		}
+	}
+}
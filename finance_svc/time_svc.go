@@ -34,6 +34,11 @@ type TimeSVC struct {
 	MarketStartTime      time.Time
 	AfterMarketStartTime time.Time
 	AfterMarketEndTime   time.Time
+
+	// MarketSessionSuffixes maps symbol suffixes to markets
+	MarketSessionSuffixes []marketSessionSuffix
+	DefaultMarketSession  *MarketSession
+	CryptoMarketSession   *MarketSession
 }
 
 func InitTimeSVC() (*TimeSVC, error) {
@@ -78,6 +83,12 @@ func InitTimeSVC() (*TimeSVC, error) {
 		return nil, err
 	}
 
+	sessionSuffixes, defaultSession, cryptoSession, err := initMarketSessions()
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
 	timeSvc := &TimeSVC{
 		NewYorkLocation: newyorkLoc,
 		PhoenixLocation: phoenixLoc,
@@ -86,6 +97,10 @@ func InitTimeSVC() (*TimeSVC, error) {
 		MarketStartTime:      marketStartTime,
 		AfterMarketStartTime: aftermarketStartTime,
 		AfterMarketEndTime:   aftermarketEndTime,
+
+		MarketSessionSuffixes: sessionSuffixes,
+		DefaultMarketSession:  defaultSession,
+		CryptoMarketSession:   cryptoSession,
 	}
 
 	return timeSvc, nil
@@ -124,15 +139,14 @@ func (svc *TimeSVC) GetMarketType(t time.Time) MarketType {
 		return Overnight
 	}
 
-	if inputTime.After(svc.AfterMarketEndTime) || inputTime.Before(svc.PreMarketStartTime) {
+	// sessions include their start and exclude their end, so a bar at 09:30 is in market hours
+	if inputTime.Before(svc.PreMarketStartTime) || !inputTime.Before(svc.AfterMarketEndTime) {
 		return Overnight
-	} else if inputTime.After(svc.PreMarketStartTime) && inputTime.Before(svc.MarketStartTime) {
+	} else if inputTime.Before(svc.MarketStartTime) {
 		return PreMarket
-	} else if inputTime.After(svc.MarketStartTime) && inputTime.Before(svc.AfterMarketStartTime) {
+	} else if inputTime.Before(svc.AfterMarketStartTime) {
 		return DayMarket
-	} else if inputTime.After(svc.AfterMarketStartTime) && inputTime.Before(svc.AfterMarketEndTime) {
-		return AfterMarket
 	} else {
-		return Overnight
+		return AfterMarket
 	}
 }
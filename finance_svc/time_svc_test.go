@@ -0,0 +1,87 @@
+package finance_svc
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetMarketType(t *testing.T) {
+	svc, err := InitTimeSVC()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newyork := func(hour int, min int, sec int) time.Time {
+		return time.Date(2020, 3, 5, hour, min, sec, 0, svc.NewYorkLocation)
+	}
+
+	tests := []struct {
+		name     string
+		time     time.Time
+		expected MarketType
+	}{
+		// sessions include their start and exclude their end
+		{"midnight", newyork(0, 0, 0), Overnight},
+		{"before pre-market", newyork(6, 59, 59), Overnight},
+		{"pre-market start", newyork(7, 0, 0), PreMarket},
+		{"before market", newyork(9, 29, 59), PreMarket},
+		{"market start", newyork(9, 30, 0), DayMarket},
+		{"before market end", newyork(15, 59, 59), DayMarket},
+		{"market end", newyork(16, 0, 0), AfterMarket},
+		{"before after-market end", newyork(16, 59, 59), AfterMarket},
+		{"after-market end", newyork(17, 0, 0), Overnight},
+		{"late night", newyork(23, 59, 59), Overnight},
+		// other time zones are converted to New York time, with daylight saving
+		{"market start in winter UTC", time.Date(2020, 1, 2, 14, 30, 0, 0, time.UTC), DayMarket},
+		{"market start in summer UTC", time.Date(2020, 7, 1, 13, 30, 0, 0, time.UTC), DayMarket},
+		{"before market in summer UTC", time.Date(2020, 7, 1, 13, 29, 59, 0, time.UTC), PreMarket},
+		{"market end in Phoenix", time.Date(2020, 7, 1, 13, 0, 0, 0, svc.PhoenixLocation), AfterMarket},
+	}
+
+	for _, test := range tests {
+		marketType := svc.GetMarketType(test.time)
+		if marketType != test.expected {
+			t.Fatalf("%s: expected %s at %s, got %s", test.name, test.expected, test.time, marketType)
+		}
+	}
+}
+
+func TestIsTradingDay(t *testing.T) {
+	svc, err := InitTimeSVC()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		symbol   string
+		time     time.Time
+		expected bool
+	}{
+		{"friday night", "AAPL", time.Date(2020, 3, 6, 23, 0, 0, 0, svc.NewYorkLocation), true},
+		{"saturday", "AAPL", time.Date(2020, 3, 7, 12, 0, 0, 0, svc.NewYorkLocation), false},
+		{"sunday", "AAPL", time.Date(2020, 3, 8, 12, 0, 0, 0, svc.NewYorkLocation), false},
+		// NYSE holidays, observed on Friday or Monday when on weekends
+		{"good friday", "AAPL", time.Date(2020, 4, 10, 12, 0, 0, 0, svc.NewYorkLocation), false},
+		{"thanksgiving", "AAPL", time.Date(2020, 11, 26, 12, 0, 0, 0, svc.NewYorkLocation), false},
+		{"independence day on saturday", "AAPL", time.Date(2020, 7, 3, 12, 0, 0, 0, svc.NewYorkLocation), false},
+		{"christmas on sunday", "AAPL", time.Date(2022, 12, 26, 12, 0, 0, 0, svc.NewYorkLocation), false},
+		{"juneteenth", "AAPL", time.Date(2023, 6, 19, 12, 0, 0, 0, svc.NewYorkLocation), false},
+		{"juneteenth before 2022", "AAPL", time.Date(2020, 6, 19, 12, 0, 0, 0, svc.NewYorkLocation), true},
+		{"new year's eve before a saturday", "AAPL", time.Date(2021, 12, 31, 12, 0, 0, 0, svc.NewYorkLocation), true},
+		{"day after thanksgiving", "AAPL", time.Date(2020, 11, 27, 12, 0, 0, 0, svc.NewYorkLocation), true},
+		// holidays of other markets are not known
+		{"good friday, futures", "ES=F", time.Date(2020, 4, 10, 12, 0, 0, 0, svc.NewYorkLocation), true},
+		// dates are of the market, it is Saturday in Seoul
+		{"friday in New York, KRX", "005930.KS", time.Date(2020, 3, 6, 12, 0, 0, 0, svc.NewYorkLocation), false},
+		{"sunday open, futures", "ES=F", time.Date(2020, 3, 8, 19, 0, 0, 0, svc.NewYorkLocation), false},
+		{"sunday, crypto", "BTC-USD", time.Date(2020, 3, 8, 12, 0, 0, 0, time.UTC), true},
+	}
+
+	for _, test := range tests {
+		tradingDay := svc.GetMarketSession(test.symbol).IsTradingDay(test.time)
+		if tradingDay != test.expected {
+			t.Fatalf("%s: expected trading day %v, got %v", test.name, test.expected, tradingDay)
+		}
+	}
+}
@@ -9,6 +9,6 @@ require (
 	github.com/leekchan/accounting v1.0.0
 	github.com/patrickmn/go-cache v2.1.0+incompatible
 	github.com/sirupsen/logrus v1.8.1
-	github.com/tonymackay/go-yahoo-finance v1.0.1
+	golang.org/x/image v0.0.0-20201208152932-35266b937fa6
 	golang.org/x/net v0.0.0-20200813134508-3edf25e44fcc // indirect
 )
@@ -0,0 +1,267 @@
+package web_svc
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	adminHTMLFile = "resources/admin.html"
+)
+
+// TemplateChartStatsItem is a tracked chart shown on the admin page
+type TemplateChartStatsItem struct {
+	Key            string
+	Pending        bool
+	LastRenderTime string
+	Duration       string
+	RenderCount    int
+	ErrorCount     int
+	LastError      string
+	LastErrorTime  string
+	FileSize       string
+	Expiration     string
+}
+
+// TemplateChartStatsItems ...
+type TemplateChartStatsItems struct {
+	Charts       []TemplateChartStatsItem
+	PendingCount int
+	// Token is posted with forms of the page
+	Token string
+}
+
+func (svc *WebSVC) getAdminHTMLHandler(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  "WebSVC",
+		"function": "getAdminHTMLHandler",
+	})
+
+	logger.Infof("Page access request from %s to %s", r.RemoteAddr, r.RequestURI)
+
+	w.Header().Set("Content-Type", "text/html")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	// the admin page is a whole document, links in the header are not served by the admin listener
+	err := svc.renderAdminHTML(w)
+	if err != nil {
+		logger.Error(err)
+		w.Write([]byte(err.Error()))
+		return
+	}
+}
+
+// renderAdminHTML lists tracked charts with their render statistics
+func (svc *WebSVC) renderAdminHTML(w io.Writer) error {
+	logger := log.WithFields(log.Fields{
+		"package":  "WebSVC",
+		"function": "renderAdminHTML",
+	})
+
+	// html/template escapes error messages and keys
+	t, err := template.ParseFiles(adminHTMLFile)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	formatTime := func(t time.Time) string {
+		if t.IsZero() {
+			return "-"
+		}
+		return svc.TimeService.ToNewyork(t).Format(timeLayout)
+	}
+
+	data := TemplateChartStatsItems{
+		Charts:       []TemplateChartStatsItem{},
+		PendingCount: len(svc.ChartService.GetPendingRenewals()),
+		Token:        svc.AdminToken,
+	}
+
+	for _, trackedChart := range svc.ChartService.GetTrackedCharts() {
+		stats := trackedChart.Chart.Stats
+		data.Charts = append(data.Charts, TemplateChartStatsItem{
+			Key:            trackedChart.Key,
+			Pending:        trackedChart.Pending,
+			LastRenderTime: formatTime(trackedChart.Chart.LastRenderTime),
+			Duration:       stats.LastDuration.Round(time.Millisecond).String(),
+			RenderCount:    stats.RenderCount,
+			ErrorCount:     stats.ErrorCount,
+			LastError:      stats.LastError,
+			LastErrorTime:  formatTime(stats.LastErrorTime),
+			FileSize:       fmt.Sprintf("%.1f KB", float64(stats.FileSize)/1024),
+			Expiration:     formatTime(trackedChart.Expiration),
+		})
+	}
+
+	return t.Execute(w, data)
+}
+
+// postAdminChartRenewHandler re-renders a tracked chart given by the key form value
+func (svc *WebSVC) postAdminChartRenewHandler(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  "WebSVC",
+		"function": "postAdminChartRenewHandler",
+	})
+
+	err := svc.ChartService.ForceRenewChart(r.FormValue("key"))
+	if err != nil {
+		logger.Error(err)
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	http.Redirect(w, r, "/admin", http.StatusSeeOther)
+}
+
+// postAdminChartEvictHandler stops tracking a chart given by the key form value
+func (svc *WebSVC) postAdminChartEvictHandler(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  "WebSVC",
+		"function": "postAdminChartEvictHandler",
+	})
+
+	err := svc.ChartService.EvictChart(r.FormValue("key"))
+	if err != nil {
+		logger.Error(err)
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	http.Redirect(w, r, "/admin", http.StatusSeeOther)
+}
+
+// newAdminToken returns a random token for forms of admin pages
+func newAdminToken() (string, error) {
+	token := make([]byte, 16)
+	_, err := rand.Read(token)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(token), nil
+}
+
+// requireAdminPost rejects posts not sent by forms of admin pages
+func (svc *WebSVC) requireAdminPost(handler http.HandlerFunc) http.HandlerFunc {
+	return svc.requireAdminHost(svc.requireSameOrigin(svc.requireAdminToken(handler)))
+}
+
+// requireAdminHost rejects requests whose Host does not name the admin listener. Pages of other
+// sites resolving their domain to the listener, i.e., DNS rebinding, send the domain as Host and
+// Origin, so they pass origin checks but not this one.
+func (svc *WebSVC) requireAdminHost(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		logger := log.WithFields(log.Fields{
+			"package":  "WebSVC",
+			"function": "requireAdminHost",
+		})
+
+		if !svc.isAdminHost(r.Host) {
+			logger.Warnf("Request from %s to host %q is rejected, admin pages are served at %s", r.RemoteAddr, r.Host, svc.AdminAddress)
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte("unknown host"))
+			return
+		}
+
+		handler(w, r)
+	}
+}
+
+// isAdminHost returns true if the host is the admin address, or an IP address or localhost with its port.
+// Those are not resolved by DNS of other sites.
+func (svc *WebSVC) isAdminHost(host string) bool {
+	if len(svc.AdminAddress) == 0 {
+		return false
+	}
+
+	if host == svc.AdminAddress {
+		return true
+	}
+
+	hostname, port, err := net.SplitHostPort(host)
+	if err != nil {
+		return false
+	}
+
+	_, adminPort, err := net.SplitHostPort(svc.AdminAddress)
+	if err != nil || port != adminPort {
+		return false
+	}
+	return hostname == "localhost" || net.ParseIP(hostname) != nil
+}
+
+// requireAdminToken rejects posts without the token of admin page forms
+func (svc *WebSVC) requireAdminToken(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		logger := log.WithFields(log.Fields{
+			"package":  "WebSVC",
+			"function": "requireAdminToken",
+		})
+
+		token := r.PostFormValue("token")
+		if len(svc.AdminToken) == 0 || subtle.ConstantTimeCompare([]byte(token), []byte(svc.AdminToken)) != 1 {
+			logger.Warnf("Request from %s to %s is rejected, invalid token", r.RemoteAddr, r.RequestURI)
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte("invalid token, reload the admin page"))
+			return
+		}
+
+		handler(w, r)
+	}
+}
+
+// requireSameOrigin rejects requests whose Origin or Referer is another site, so pages a visitor
+// opens cannot post to admin handlers. Requests with neither are rejected too.
+func (svc *WebSVC) requireSameOrigin(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		logger := log.WithFields(log.Fields{
+			"package":  "WebSVC",
+			"function": "requireSameOrigin",
+		})
+
+		if !isSameOrigin(r) {
+			logger.Warnf("Cross-origin request from %s to %s is rejected - origin %q, referer %q", r.RemoteAddr, r.RequestURI, r.Header.Get("Origin"), r.Referer())
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte("cross-origin request is not allowed"))
+			return
+		}
+
+		handler(w, r)
+	}
+}
+
+// isSameOrigin returns true if the Origin header, or the Referer if there is no Origin,
+// has the host of the request. Origin "null" of sandboxed frames is cross-origin.
+func isSameOrigin(r *http.Request) bool {
+	source := r.Header.Get("Origin")
+	if len(source) == 0 {
+		source = r.Referer()
+	}
+
+	if len(source) == 0 {
+		return false
+	}
+
+	sourceURL, err := url.Parse(source)
+	if err != nil {
+		return false
+	}
+	return len(sourceURL.Host) > 0 && sourceURL.Host == r.Host
+}
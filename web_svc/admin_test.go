@@ -0,0 +1,119 @@
+package web_svc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestRequireSameOrigin(t *testing.T) {
+	tests := []struct {
+		name     string
+		origin   string
+		referer  string
+		expected int
+	}{
+		{"origin", "http://127.0.0.1:8081", "", http.StatusSeeOther},
+		{"referer", "", "http://127.0.0.1:8081/admin", http.StatusSeeOther},
+		// the origin wins over the referer
+		{"cross-origin, same referer", "http://example.com", "http://127.0.0.1:8081/admin", http.StatusForbidden},
+		{"cross-origin", "http://example.com", "", http.StatusForbidden},
+		{"cross-origin referer", "", "http://example.com/admin", http.StatusForbidden},
+		{"other port", "http://127.0.0.1:8080", "", http.StatusForbidden},
+		{"sandboxed frame", "null", "", http.StatusForbidden},
+		{"no origin", "", "", http.StatusForbidden},
+	}
+
+	svc := &WebSVC{}
+	for _, test := range tests {
+		called := false
+		handler := svc.requireSameOrigin(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+			http.Redirect(w, r, "/admin", http.StatusSeeOther)
+		})
+
+		r := httptest.NewRequest(http.MethodPost, "http://127.0.0.1:8081/admin/charts/evict", nil)
+		if len(test.origin) > 0 {
+			r.Header.Set("Origin", test.origin)
+		}
+		if len(test.referer) > 0 {
+			r.Header.Set("Referer", test.referer)
+		}
+
+		w := httptest.NewRecorder()
+		handler(w, r)
+
+		if w.Code != test.expected {
+			t.Fatalf("%s: expected status %d, got %d", test.name, test.expected, w.Code)
+		}
+
+		if called != (test.expected == http.StatusSeeOther) {
+			t.Fatalf("%s: expected the handler called %v, got %v", test.name, test.expected == http.StatusSeeOther, called)
+		}
+	}
+}
+
+func TestRequireAdminPost(t *testing.T) {
+	tests := []struct {
+		name     string
+		host     string
+		origin   string
+		token    string
+		expected int
+	}{
+		{"admin address", "127.0.0.1:8080", "http://127.0.0.1:8080", "secret", http.StatusSeeOther},
+		{"localhost", "localhost:8080", "http://localhost:8080", "secret", http.StatusSeeOther},
+		{"other address", "192.168.0.10:8080", "http://192.168.0.10:8080", "secret", http.StatusSeeOther},
+		// a page of the attacker's domain resolved to the listener is same-origin by headers
+		{"dns rebinding", "attacker.example:8080", "http://attacker.example:8080", "secret", http.StatusForbidden},
+		{"dns rebinding, no token", "attacker.example:8080", "http://attacker.example:8080", "", http.StatusForbidden},
+		{"other port", "127.0.0.1:8081", "http://127.0.0.1:8081", "secret", http.StatusForbidden},
+		{"no port", "localhost", "http://localhost", "secret", http.StatusForbidden},
+		// pages of other sites cannot read the token
+		{"no token", "127.0.0.1:8080", "http://127.0.0.1:8080", "", http.StatusForbidden},
+		{"wrong token", "127.0.0.1:8080", "http://127.0.0.1:8080", "secreT", http.StatusForbidden},
+		{"cross-origin", "127.0.0.1:8080", "http://attacker.example", "secret", http.StatusForbidden},
+	}
+
+	svc := &WebSVC{
+		AdminAddress: "127.0.0.1:8080",
+		AdminToken:   "secret",
+	}
+
+	for _, test := range tests {
+		called := false
+		handler := svc.requireAdminPost(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+			http.Redirect(w, r, "/admin", http.StatusSeeOther)
+		})
+
+		form := url.Values{}
+		form.Set("key", "AAPL_1y_1d.png")
+		if len(test.token) > 0 {
+			form.Set("token", test.token)
+		}
+
+		r := httptest.NewRequest(http.MethodPost, "/admin/charts/evict", strings.NewReader(form.Encode()))
+		r.Host = test.host
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		r.Header.Set("Origin", test.origin)
+
+		w := httptest.NewRecorder()
+		handler(w, r)
+
+		if w.Code != test.expected {
+			t.Fatalf("%s: expected status %d, got %d", test.name, test.expected, w.Code)
+		}
+
+		if called != (test.expected == http.StatusSeeOther) {
+			t.Fatalf("%s: expected the handler called %v, got %v", test.name, test.expected == http.StatusSeeOther, called)
+		}
+	}
+
+	// admin pages are not served without an admin listener
+	if (&WebSVC{}).isAdminHost("127.0.0.1:8080") {
+		t.Fatal("expected no admin host without an admin address")
+	}
+}
@@ -6,6 +6,21 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+// basicChartItems are tickers shown on the basic page
+var basicChartItems = []string{
+	"DBB",
+	"UCO",
+	"UYM",
+	"SLX",
+	"NRGU",
+	"CPER",
+	"LIT",
+	"TIMBER",
+	"CORN",
+	"DBA",
+	"PICK",
+}
+
 func (svc *WebSVC) getBasicHTMLHandler(w http.ResponseWriter, r *http.Request) {
 	logger := log.WithFields(log.Fields{
 		"package":  "WebSVC",
@@ -24,22 +39,7 @@ func (svc *WebSVC) getBasicHTMLHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Tickers?
-	chartItems := []string{
-		"DBB",
-		"UCO",
-		"UYM",
-		"SLX",
-		"NRGU",
-		"CPER",
-		"LIT",
-		"TIMBER",
-		"CORN",
-		"DBA",
-		"PICK",
-	}
-
-	err = svc.renderChartMapDetailHTML(chartItems, w)
+	err = svc.renderChartMapDetailHTML(r.Context(), basicChartItems, w)
 	if err != nil {
 		logger.Error(err)
 		w.Write([]byte(err.Error()))
@@ -1,7 +1,14 @@
 package web_svc
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
 
 	"github.com/gorilla/mux"
 	"github.com/iychoi/stock-svc/finance_svc"
@@ -33,19 +40,227 @@ func (svc *WebSVC) getChartImageHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	w.Header().Set("Content-Type", "image/png")
+	options, err := svc.parseChartOptions(r)
+	if err != nil {
+		logger.Error(err)
+		svc.writeChartError(w, err)
+		return
+	}
+
+	// format by extension, e.g., /chartimg/SOXL/1mo/1d.svg
+	if ext := path.Ext(interval); len(ext) > 0 {
+		format, err := finance_svc.ParseChartFormat(strings.TrimPrefix(ext, "."))
+		if err != nil {
+			logger.Error(err)
+			svc.writeChartError(w, err)
+			return
+		}
+
+		interval = strings.TrimSuffix(interval, ext)
+		options.Format = format
+	}
 
-	bytes, err := svc.ChartService.GetChartData(symbol, finance_svc.ChartPeriod(period), finance_svc.ChartInterval(interval))
+	stockSymbol, err := finance_svc.ParseStockSymbol(symbol)
 	if err != nil {
 		logger.Error(err)
-		w.WriteHeader(500)
+		svc.writeChartError(w, err)
 		return
 	}
 
-	_, err = w.Write(bytes)
+	chartPeriod, err := finance_svc.ParseChartPeriod(period)
 	if err != nil {
 		logger.Error(err)
-		w.WriteHeader(500)
+		svc.writeChartError(w, err)
+		return
+	}
+
+	chartInterval, err := finance_svc.ParseChartInterval(interval)
+	if err != nil {
+		logger.Error(err)
+		svc.writeChartError(w, err)
+		return
+	}
+
+	err = finance_svc.ValidateChartPeriodInterval(chartPeriod, chartInterval)
+	if err != nil {
+		logger.Error(err)
+		svc.writeChartError(w, err)
+		return
+	}
+
+	image, err := svc.ChartService.GetChartImage(r.Context(), stockSymbol, chartPeriod, chartInterval, options)
+	if err != nil {
+		logger.Error(err)
+		svc.writeChartError(w, err)
 		return
 	}
+
+	svc.writeCachedImage(w, r, image.Data, options.Format.GetContentType(), image.ModTime, image.MaxAge)
+}
+
+// writeChartError responds 400 for invalid chart parameters, 504 for render timeouts, 500 otherwise
+func (svc *WebSVC) writeChartError(w http.ResponseWriter, err error) {
+	if errors.Is(err, context.Canceled) {
+		// the client is gone
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+	if finance_svc.IsInvalidChartParamError(err) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	if finance_svc.IsChartTimeoutError(err) || errors.Is(err, context.DeadlineExceeded) {
+		w.WriteHeader(http.StatusGatewayTimeout)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	w.WriteHeader(500)
+}
+
+// parseChartOptions reads chart options from query parameters
+func (svc *WebSVC) parseChartOptions(r *http.Request) (finance_svc.ChartOptions, error) {
+	options := finance_svc.DefaultChartOptions()
+	query := r.URL.Query()
+
+	style, err := finance_svc.ParseChartStyle(query.Get("style"))
+	if err != nil {
+		return options, err
+	}
+	options.Style = style
+
+	format, err := finance_svc.ParseChartFormat(query.Get("format"))
+	if err != nil {
+		return options, err
+	}
+	options.Format = format
+
+	if volume := query.Get("volume"); len(volume) > 0 {
+		showVolume, err := strconv.ParseBool(volume)
+		if err != nil {
+			return options, &finance_svc.InvalidChartParamError{Message: fmt.Sprintf("invalid volume flag - %s", volume)}
+		}
+		options.Volume = showVolume
+	}
+
+	// e.g., ?events=true marks earnings, dividends and splits
+	if events := query.Get("events"); len(events) > 0 {
+		showEvents, err := strconv.ParseBool(events)
+		if err != nil {
+			return options, &finance_svc.InvalidChartParamError{Message: fmt.Sprintf("invalid events flag - %s", events)}
+		}
+		options.Events = showEvents
+	}
+
+	indicators, err := svc.parseChartIndicators(query)
+	if err != nil {
+		return options, err
+	}
+	options.Indicators = indicators
+
+	err = svc.parseChartImageOptions(query, &options, finance_svc.ParseChartSize)
+	if err != nil {
+		return options, err
+	}
+
+	return options, nil
+}
+
+// parseChartIndicators reads indicators from query parameters,
+// e.g., ?sma=20,50&ema=12&bb=20&vwap=true&rsi=14&macd=true
+func (svc *WebSVC) parseChartIndicators(query url.Values) (finance_svc.ChartIndicators, error) {
+	indicators := finance_svc.ChartIndicators{}
+
+	sma, err := finance_svc.ParseIndicatorWindows(query.Get("sma"))
+	if err != nil {
+		return indicators, err
+	}
+	indicators.SMA = sma
+
+	ema, err := finance_svc.ParseIndicatorWindows(query.Get("ema"))
+	if err != nil {
+		return indicators, err
+	}
+	indicators.EMA = ema
+
+	if bb := query.Get("bb"); len(bb) > 0 {
+		window, err := finance_svc.ParseIndicatorWindow(bb)
+		if err != nil {
+			return indicators, err
+		}
+		indicators.Bollinger = window
+	}
+
+	if rsi := query.Get("rsi"); len(rsi) > 0 {
+		window, err := finance_svc.ParseIndicatorWindow(rsi)
+		if err != nil {
+			return indicators, err
+		}
+		indicators.RSI = window
+	}
+
+	if vwap := query.Get("vwap"); len(vwap) > 0 {
+		showVWAP, err := strconv.ParseBool(vwap)
+		if err != nil {
+			return indicators, &finance_svc.InvalidChartParamError{Message: fmt.Sprintf("invalid vwap flag - %s", vwap)}
+		}
+		indicators.VWAP = showVWAP
+	}
+
+	if macd := query.Get("macd"); len(macd) > 0 {
+		showMACD, err := strconv.ParseBool(macd)
+		if err != nil {
+			return indicators, &finance_svc.InvalidChartParamError{Message: fmt.Sprintf("invalid macd flag - %s", macd)}
+		}
+		indicators.MACD = showMACD
+	}
+
+	return indicators, nil
+}
+
+// parseChartImageOptions reads image size, DPI and colors from query parameters,
+// e.g., ?width=290&height=218&dpi=100&theme=dark&up=26a69a&down=ef5350.
+// parseSize checks width and height.
+func (svc *WebSVC) parseChartImageOptions(query url.Values, options *finance_svc.ChartOptions, parseSize func(size string) (int, error)) error {
+	width, err := parseSize(query.Get("width"))
+	if err != nil {
+		return err
+	}
+	options.Width = width
+
+	height, err := parseSize(query.Get("height"))
+	if err != nil {
+		return err
+	}
+	options.Height = height
+
+	dpi, err := finance_svc.ParseChartDPI(query.Get("dpi"))
+	if err != nil {
+		return err
+	}
+	options.DPI = dpi
+
+	theme, err := finance_svc.ParseChartTheme(query.Get("theme"))
+	if err != nil {
+		return err
+	}
+	options.Theme = theme
+
+	upColor, err := finance_svc.ParseChartColor(query.Get("up"))
+	if err != nil {
+		return err
+	}
+	options.UpColor = upColor
+
+	downColor, err := finance_svc.ParseChartColor(query.Get("down"))
+	if err != nil {
+		return err
+	}
+	options.DownColor = downColor
+
+	return nil
 }
@@ -1,11 +1,14 @@
 package web_svc
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"math"
 	"text/template"
+	"time"
 
-	"github.com/leekchan/accounting"
+	"github.com/iychoi/stock-svc/finance_svc"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -14,6 +17,45 @@ const (
 	chartMapDetailHTMLFile = "resources/chartmap_detail.html"
 )
 
+// pageChart is a chart image shown per ticker by a page template
+type pageChart struct {
+	Period   finance_svc.ChartPeriod
+	Interval finance_svc.ChartInterval
+	Format   finance_svc.ChartFormat
+	Width    int
+	Height   int
+}
+
+var (
+	// charts in resources/chartmap.html, also rendered by the warm-up
+	chartMapCharts = []pageChart{
+		{Period: finance_svc.ChartPeriod1Month, Interval: finance_svc.ChartInteval1Day, Format: finance_svc.ChartFormatSVG, Width: 290, Height: 218},
+	}
+	// charts in resources/chartmap_detail.html, also rendered by the warm-up
+	chartMapDetailCharts = []pageChart{
+		{Period: finance_svc.ChartPeriod1Month, Interval: finance_svc.ChartInteval1Day, Format: finance_svc.ChartFormatSVG, Width: 220, Height: 165},
+		{Period: finance_svc.ChartPeriod1Day, Interval: finance_svc.ChartInteval1Min, Format: finance_svc.ChartFormatSVG, Width: 220, Height: 165},
+	}
+)
+
+// TemplateChartImage is a chart image of a ticker in a page
+type TemplateChartImage struct {
+	URL   string
+	Width int
+}
+
+// makeTemplateChartImages returns chart images of the symbol in a page
+func makeTemplateChartImages(symbol string, charts []pageChart) []TemplateChartImage {
+	images := []TemplateChartImage{}
+	for _, chart := range charts {
+		images = append(images, TemplateChartImage{
+			URL:   fmt.Sprintf("/chartimg/%s/%s/%s.%s?width=%d&height=%d", symbol, chart.Period, chart.Interval, chart.Format, chart.Width, chart.Height),
+			Width: chart.Width,
+		})
+	}
+	return images
+}
+
 type TemplateStockChartItem struct {
 	Symbol              string
 	StockName           string
@@ -21,6 +63,26 @@ type TemplateStockChartItem struct {
 	PriceChange         string
 	PriceChangePercent  string
 	PriceChangePositive bool
+	Charts              []TemplateChartImage
+	// ExtendedHours is the name of the extended hours session quoted, empty in regular hours
+	ExtendedHours               string
+	ExtendedPrice               string
+	ExtendedPriceChange         string
+	ExtendedPriceChangePercent  string
+	ExtendedPriceChangePositive bool
+	// fundamentals, empty if not provided
+	PreviousClose    string
+	Open             string
+	Bid              string
+	Ask              string
+	FiftyTwoWeekHigh string
+	FiftyTwoWeekLow  string
+	MarketCap        string
+	PERatio          string
+	DividendYield    string
+	Volume           string
+	AverageVolume    string
+	Currency         string
 }
 
 type TemplateStockChartItems struct {
@@ -28,7 +90,7 @@ type TemplateStockChartItems struct {
 }
 
 // renderChartMapHTML ...
-func (svc *WebSVC) renderChartMapHTML(chartItems []string, w io.Writer) error {
+func (svc *WebSVC) renderChartMapHTML(ctx context.Context, chartItems []string, w io.Writer) error {
 	logger := log.WithFields(log.Fields{
 		"package":  "WebSVC",
 		"function": "renderChartMapHTML",
@@ -42,15 +104,17 @@ func (svc *WebSVC) renderChartMapHTML(chartItems []string, w io.Writer) error {
 
 	// convert data
 	dataItems := []TemplateStockChartItem{}
+	now := time.Now()
 
-	for _, symbol := range chartItems {
-
-		stockInfo, err := svc.PriceService.GetStockInfo(symbol)
-		if err != nil {
-			logger.Error(err)
+	for _, result := range svc.PriceService.GetStockInfos(ctx, chartItems) {
+		if result.Err != nil {
+			logger.Error(result.Err)
 			continue
 		}
 
+		symbol := result.Symbol
+		stockInfo := svc.convertStockInfo(ctx, result.StockInfo)
+
 		changePositive := true
 		if stockInfo.PriceChange < 0 {
 			changePositive = false
@@ -65,17 +129,8 @@ func (svc *WebSVC) renderChartMapHTML(chartItems []string, w io.Writer) error {
 			PriceChangePositive: changePositive,
 		}
 
-		ac := accounting.Accounting{
-			Symbol:    "$",
-			Precision: 2,
-		}
-
-		dataItem.CurrentPrice = ac.FormatMoney(stockInfo.CurrentPrice)
-		if stockInfo.PriceChange > 0 {
-			dataItem.PriceChange = fmt.Sprintf("+%.2f", stockInfo.PriceChange)
-		} else {
-			dataItem.PriceChange = fmt.Sprintf("%.2f", stockInfo.PriceChange)
-		}
+		dataItem.CurrentPrice = formatPrice(stockInfo.CurrentPrice, getPriceCurrency(stockInfo))
+		dataItem.PriceChange = formatPriceChange(stockInfo.PriceChange, getPriceCurrency(stockInfo))
 
 		if stockInfo.PriceChangePercent > 0 {
 			dataItem.PriceChangePercent = fmt.Sprintf("+%.2f%%", stockInfo.PriceChangePercent*100)
@@ -83,6 +138,8 @@ func (svc *WebSVC) renderChartMapHTML(chartItems []string, w io.Writer) error {
 			dataItem.PriceChangePercent = fmt.Sprintf("%.2f%%", stockInfo.PriceChangePercent*100)
 		}
 
+		dataItem.Charts = makeTemplateChartImages(symbol, chartMapCharts)
+		svc.setExtendedHoursQuote(&dataItem, stockInfo, now)
 		dataItems = append(dataItems, dataItem)
 	}
 
@@ -95,7 +152,7 @@ func (svc *WebSVC) renderChartMapHTML(chartItems []string, w io.Writer) error {
 }
 
 // renderChartMapDetailHTML ...
-func (svc *WebSVC) renderChartMapDetailHTML(chartItems []string, w io.Writer) error {
+func (svc *WebSVC) renderChartMapDetailHTML(ctx context.Context, chartItems []string, w io.Writer) error {
 	logger := log.WithFields(log.Fields{
 		"package":  "WebSVC",
 		"function": "renderChartMapDetailHTML",
@@ -109,15 +166,17 @@ func (svc *WebSVC) renderChartMapDetailHTML(chartItems []string, w io.Writer) er
 
 	// convert data
 	dataItems := []TemplateStockChartItem{}
+	now := time.Now()
 
-	for _, symbol := range chartItems {
-
-		stockInfo, err := svc.PriceService.GetStockInfo(symbol)
-		if err != nil {
-			logger.Error(err)
+	for _, result := range svc.PriceService.GetStockInfos(ctx, chartItems) {
+		if result.Err != nil {
+			logger.Error(result.Err)
 			continue
 		}
 
+		symbol := result.Symbol
+		stockInfo := svc.convertStockInfo(ctx, result.StockInfo)
+
 		changePositive := true
 		if stockInfo.PriceChange < 0 {
 			changePositive = false
@@ -132,17 +191,8 @@ func (svc *WebSVC) renderChartMapDetailHTML(chartItems []string, w io.Writer) er
 			PriceChangePositive: changePositive,
 		}
 
-		ac := accounting.Accounting{
-			Symbol:    "$",
-			Precision: 2,
-		}
-
-		dataItem.CurrentPrice = ac.FormatMoney(stockInfo.CurrentPrice)
-		if stockInfo.PriceChange > 0 {
-			dataItem.PriceChange = fmt.Sprintf("+%.2f", stockInfo.PriceChange)
-		} else {
-			dataItem.PriceChange = fmt.Sprintf("%.2f", stockInfo.PriceChange)
-		}
+		dataItem.CurrentPrice = formatPrice(stockInfo.CurrentPrice, getPriceCurrency(stockInfo))
+		dataItem.PriceChange = formatPriceChange(stockInfo.PriceChange, getPriceCurrency(stockInfo))
 
 		if stockInfo.PriceChangePercent > 0 {
 			dataItem.PriceChangePercent = fmt.Sprintf("+%.2f%%", stockInfo.PriceChangePercent*100)
@@ -150,6 +200,9 @@ func (svc *WebSVC) renderChartMapDetailHTML(chartItems []string, w io.Writer) er
 			dataItem.PriceChangePercent = fmt.Sprintf("%.2f%%", stockInfo.PriceChangePercent*100)
 		}
 
+		dataItem.Charts = makeTemplateChartImages(symbol, chartMapDetailCharts)
+		svc.setExtendedHoursQuote(&dataItem, stockInfo, now)
+		svc.setFundamentals(&dataItem, stockInfo)
 		dataItems = append(dataItems, dataItem)
 	}
 
@@ -160,3 +213,143 @@ func (svc *WebSVC) renderChartMapDetailHTML(chartItems []string, w io.Writer) er
 	t.Execute(w, data)
 	return nil
 }
+
+// convertStockInfo converts prices of the quote to the display currency if set, or to the major
+// unit of its own currency, e.g., pence to pounds.
+// The quote is shown in its own currency if it cannot be converted.
+func (svc *WebSVC) convertStockInfo(ctx context.Context, stockInfo *finance_svc.StockInfo) *finance_svc.StockInfo {
+	logger := log.WithFields(log.Fields{
+		"package":  "WebSVC",
+		"function": "convertStockInfo",
+	})
+
+	if len(svc.DisplayCurrency) == 0 {
+		return finance_svc.ToMajorCurrency(stockInfo)
+	}
+
+	converted, err := svc.PriceService.ConvertStockInfo(ctx, stockInfo, svc.DisplayCurrency)
+	if err != nil {
+		logger.Warn(err)
+		return finance_svc.ToMajorCurrency(stockInfo)
+	}
+	return converted
+}
+
+// setExtendedHoursQuote fills the pre-market quote in pre-market hours, and the post-market quote
+// after the market closes until the next pre-market, on trading days
+func (svc *WebSVC) setExtendedHoursQuote(dataItem *TemplateStockChartItem, stockInfo *finance_svc.StockInfo, now time.Time) {
+	// the post-market quote on weekends is of the last trading day, not live
+	if !svc.TimeService.DefaultMarketSession.IsTradingDay(now) {
+		return
+	}
+
+	var price, change, changePercent float64
+	switch svc.TimeService.GetMarketType(now) {
+	case finance_svc.PreMarket:
+		dataItem.ExtendedHours = "Pre-market"
+		price, change, changePercent = stockInfo.PreMarketPrice, stockInfo.PreMarketChange, stockInfo.PreMarketChangePercent
+	case finance_svc.AfterMarket, finance_svc.Overnight:
+		if !svc.isPostMarketQuoteLive(stockInfo, now) {
+			return
+		}
+
+		dataItem.ExtendedHours = "After hours"
+		price, change, changePercent = stockInfo.PostMarketPrice, stockInfo.PostMarketChange, stockInfo.PostMarketChangePercent
+	}
+
+	// not traded in the session, or the provider has no extended hours quotes
+	if price == 0 {
+		dataItem.ExtendedHours = ""
+		return
+	}
+
+	dataItem.ExtendedPrice = formatPrice(price, getPriceCurrency(stockInfo))
+	dataItem.ExtendedPriceChangePositive = change >= 0
+	dataItem.ExtendedPriceChange = formatPriceChange(change, getPriceCurrency(stockInfo))
+
+	if changePercent > 0 {
+		dataItem.ExtendedPriceChangePercent = fmt.Sprintf("+%.2f%%", changePercent*100)
+	} else {
+		dataItem.ExtendedPriceChangePercent = fmt.Sprintf("%.2f%%", changePercent*100)
+	}
+}
+
+// isPostMarketQuoteLive returns true if the post-market quote is of the most recent regular session
+// and the night after the session has not passed, e.g., Friday's quote is not live on Monday
+// before the pre-market. Quotes without a trade time are only checked for the night.
+func (svc *WebSVC) isPostMarketQuoteLive(stockInfo *finance_svc.StockInfo, now time.Time) bool {
+	lastClose := svc.TimeService.DefaultMarketSession.LastClose(now)
+
+	// traded before the close, the quote is of an earlier session
+	if !stockInfo.PostMarketTime.IsZero() && stockInfo.PostMarketTime.Before(lastClose) {
+		return false
+	}
+
+	// post-market quotes are shown until the pre-market of the next day
+	preMarketStart := svc.TimeService.PreMarketStartTime
+	year, month, day := lastClose.AddDate(0, 0, 1).Date()
+	nextPreMarket := time.Date(year, month, day, preMarketStart.Hour(), preMarketStart.Minute(), 0, 0, lastClose.Location())
+	return now.Before(nextPreMarket)
+}
+
+// setFundamentals fills fundamentals shown in the detail page, fields not provided are left empty
+func (svc *WebSVC) setFundamentals(dataItem *TemplateStockChartItem, stockInfo *finance_svc.StockInfo) {
+	formatProvidedPrice := func(price float64) string {
+		if price == 0 {
+			return ""
+		}
+		return formatPrice(price, getPriceCurrency(stockInfo))
+	}
+
+	dataItem.PreviousClose = formatProvidedPrice(stockInfo.PreviousClose)
+	dataItem.Open = formatProvidedPrice(stockInfo.Open)
+	dataItem.Bid = formatProvidedPrice(stockInfo.Bid)
+	dataItem.Ask = formatProvidedPrice(stockInfo.Ask)
+	dataItem.FiftyTwoWeekHigh = formatProvidedPrice(stockInfo.FiftyTwoWeekHigh)
+	dataItem.FiftyTwoWeekLow = formatProvidedPrice(stockInfo.FiftyTwoWeekLow)
+
+	if stockInfo.MarketCap > 0 {
+		dataItem.MarketCap = formatLargeNumber(stockInfo.MarketCap)
+		if format := getCurrencyFormat(getPriceCurrency(stockInfo)); len(format.Symbol) > 0 {
+			dataItem.MarketCap = format.Symbol + dataItem.MarketCap
+		}
+	}
+
+	if stockInfo.PERatio > 0 {
+		dataItem.PERatio = fmt.Sprintf("%.2f", stockInfo.PERatio)
+	}
+
+	if stockInfo.DividendYield > 0 {
+		dataItem.DividendYield = fmt.Sprintf("%.2f%%", stockInfo.DividendYield*100)
+	}
+
+	if stockInfo.Volume > 0 {
+		dataItem.Volume = formatLargeNumber(float64(stockInfo.Volume))
+	}
+
+	if stockInfo.AverageVolume > 0 {
+		dataItem.AverageVolume = formatLargeNumber(float64(stockInfo.AverageVolume))
+	}
+
+	dataItem.Currency = getPriceCurrency(stockInfo)
+}
+
+// formatLargeNumber abbreviates a number with a suffix, e.g., 2.95T, 12.34M
+func formatLargeNumber(value float64) string {
+	units := []struct {
+		Size   float64
+		Suffix string
+	}{
+		{Size: 1e12, Suffix: "T"},
+		{Size: 1e9, Suffix: "B"},
+		{Size: 1e6, Suffix: "M"},
+		{Size: 1e3, Suffix: "K"},
+	}
+
+	for _, unit := range units {
+		if math.Abs(value) >= unit.Size {
+			return fmt.Sprintf("%.2f%s", value/unit.Size, unit.Suffix)
+		}
+	}
+	return fmt.Sprintf("%.0f", value)
+}
@@ -0,0 +1,183 @@
+package web_svc
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/iychoi/stock-svc/finance_svc"
+)
+
+func TestSetExtendedHoursQuote(t *testing.T) {
+	timeService, err := finance_svc.InitTimeSVC()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	svc := &WebSVC{
+		TimeService: timeService,
+	}
+
+	// Fri, Mar 6, 2020 and the weekend in New York
+	newyork := func(date int, hour int) time.Time {
+		return time.Date(2020, 3, date, hour, 0, 0, 0, timeService.NewYorkLocation)
+	}
+
+	stockInfo := &finance_svc.StockInfo{
+		Symbol:                  "AAPL",
+		CurrentPrice:            100,
+		PreMarketPrice:          99,
+		PreMarketChange:         -1,
+		PreMarketChangePercent:  -0.01,
+		PostMarketPrice:         102,
+		PostMarketChange:        2,
+		PostMarketChangePercent: 0.02,
+		Currency:                "USD",
+	}
+
+	tests := []struct {
+		name          string
+		now           time.Time
+		expectedHours string
+		expectedPrice string
+	}{
+		{"pre-market", newyork(6, 8), "Pre-market", "$99.00"},
+		{"market hours", newyork(6, 12), "", ""},
+		{"after hours", newyork(6, 16), "After hours", "$102.00"},
+		{"friday night", newyork(6, 22), "After hours", "$102.00"},
+		// friday's post-market quote is not live on weekends
+		{"saturday", newyork(7, 16), "", ""},
+		{"sunday night", newyork(8, 22), "", ""},
+	}
+
+	for _, test := range tests {
+		dataItem := TemplateStockChartItem{}
+		svc.setExtendedHoursQuote(&dataItem, stockInfo, test.now)
+		if dataItem.ExtendedHours != test.expectedHours || dataItem.ExtendedPrice != test.expectedPrice {
+			t.Fatalf("%s: expected %q %q, got %q %q", test.name, test.expectedHours, test.expectedPrice, dataItem.ExtendedHours, dataItem.ExtendedPrice)
+		}
+	}
+}
+
+func TestSetExtendedHoursQuoteStale(t *testing.T) {
+	timeService, err := finance_svc.InitTimeSVC()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	svc := &WebSVC{
+		TimeService: timeService,
+	}
+
+	// Thu, Mar 5 to Tue, Mar 10, 2020 in New York
+	newyork := func(date int, hour int) time.Time {
+		return time.Date(2020, 3, date, hour, 0, 0, 0, timeService.NewYorkLocation)
+	}
+
+	tests := []struct {
+		name           string
+		now            time.Time
+		postMarketTime time.Time
+		expected       bool
+	}{
+		{"after hours", newyork(6, 16), newyork(6, 16), true},
+		{"friday night", newyork(6, 22), newyork(6, 19), true},
+		{"tuesday before pre-market", newyork(10, 2), newyork(9, 19), true},
+		// the quote of friday is not live after the weekend
+		{"monday before pre-market", newyork(9, 2), newyork(6, 19), false},
+		{"monday before pre-market, no trade time", newyork(9, 2), time.Time{}, false},
+		// not traded after the close yet, the quote is of the day before
+		{"after hours, quote of the day before", newyork(6, 16), newyork(5, 19), false},
+		{"night, quote of the day before", newyork(10, 2), newyork(6, 19), false},
+		{"after hours, no trade time", newyork(6, 16), time.Time{}, true},
+	}
+
+	for _, test := range tests {
+		stockInfo := &finance_svc.StockInfo{
+			Symbol:                  "AAPL",
+			CurrentPrice:            100,
+			PostMarketPrice:         102,
+			PostMarketChange:        2,
+			PostMarketChangePercent: 0.02,
+			PostMarketTime:          test.postMarketTime,
+			Currency:                "USD",
+		}
+
+		dataItem := TemplateStockChartItem{}
+		svc.setExtendedHoursQuote(&dataItem, stockInfo, test.now)
+		if (len(dataItem.ExtendedPrice) > 0) != test.expected {
+			t.Fatalf("%s: expected the post-market quote shown %v, got %q %q", test.name, test.expected, dataItem.ExtendedHours, dataItem.ExtendedPrice)
+		}
+	}
+}
+
+func TestSetFundamentals(t *testing.T) {
+	svc := &WebSVC{}
+
+	tests := []struct {
+		name      string
+		stockInfo finance_svc.StockInfo
+		expected  TemplateStockChartItem
+	}{
+		{
+			"all fields",
+			finance_svc.StockInfo{
+				PreviousClose: 118.72, Open: 119.1, Bid: 120.4, Ask: 120.6, FiftyTwoWeekHigh: 138.79, FiftyTwoWeekLow: 53.15,
+				MarketCap: 2.05e12, PERatio: 36.5, DividendYield: 0.0068, Volume: 95000000, AverageVolume: 110000000, Currency: "USD",
+			},
+			TemplateStockChartItem{
+				PreviousClose: "$118.72", Open: "$119.10", Bid: "$120.40", Ask: "$120.60", FiftyTwoWeekHigh: "$138.79", FiftyTwoWeekLow: "$53.15",
+				MarketCap: "$2.05T", PERatio: "36.50", DividendYield: "0.68%", Volume: "95.00M", AverageVolume: "110.00M", Currency: "USD",
+			},
+		},
+		// fields not provided are not shown
+		{
+			"stooq",
+			finance_svc.StockInfo{PreviousClose: 118.72, Open: 119.1, Volume: 950, Currency: "USD"},
+			TemplateStockChartItem{PreviousClose: "$118.72", Open: "$119.10", Volume: "950", Currency: "USD"},
+		},
+		{"nothing", finance_svc.StockInfo{}, TemplateStockChartItem{}},
+		{
+			"KRW",
+			finance_svc.StockInfo{PreviousClose: 60000, MarketCap: 3.58e14, Currency: "KRW"},
+			TemplateStockChartItem{PreviousClose: "₩60,000", MarketCap: "₩358.00T", Currency: "KRW"},
+		},
+		// index levels are points
+		{
+			"index",
+			finance_svc.StockInfo{PreviousClose: 3800, FiftyTwoWeekHigh: 3900, FiftyTwoWeekLow: 2200, Currency: "USD", QuoteType: finance_svc.QuoteTypeIndex},
+			TemplateStockChartItem{PreviousClose: "3,800.00", FiftyTwoWeekHigh: "3,900.00", FiftyTwoWeekLow: "2,200.00"},
+		},
+	}
+
+	for _, test := range tests {
+		dataItem := TemplateStockChartItem{}
+		svc.setFundamentals(&dataItem, &test.stockInfo)
+		if !reflect.DeepEqual(dataItem, test.expected) {
+			t.Fatalf("%s: expected %+v, got %+v", test.name, test.expected, dataItem)
+		}
+	}
+}
+
+func TestFormatLargeNumber(t *testing.T) {
+	tests := []struct {
+		value    float64
+		expected string
+	}{
+		{2.95e12, "2.95T"},
+		{1e12, "1.00T"},
+		{999.99e9, "999.99B"},
+		{12.345e6, "12.35M"},
+		{1500, "1.50K"},
+		{999, "999"},
+		{0, "0"},
+		{-2.5e9, "-2.50B"},
+	}
+
+	for _, test := range tests {
+		formatted := formatLargeNumber(test.value)
+		if formatted != test.expected {
+			t.Fatalf("%v: expected %s, got %s", test.value, test.expected, formatted)
+		}
+	}
+}
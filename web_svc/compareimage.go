@@ -0,0 +1,73 @@
+package web_svc
+
+import (
+	"net/http"
+
+	"github.com/iychoi/stock-svc/finance_svc"
+	log "github.com/sirupsen/logrus"
+)
+
+// getCompareChartImageHandler serves a chart comparing symbols,
+// e.g., /chartimg/compare?symbols=SOXL,SOXX&period=6mo&interval=1d
+func (svc *WebSVC) getCompareChartImageHandler(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  "WebSVC",
+		"function": "getCompareChartImageHandler",
+	})
+
+	query := r.URL.Query()
+
+	options, err := svc.parseChartOptions(r)
+	if err != nil {
+		logger.Error(err)
+		svc.writeChartError(w, err)
+		return
+	}
+
+	symbols, err := finance_svc.ParseCompareSymbols(query.Get("symbols"))
+	if err != nil {
+		logger.Error(err)
+		svc.writeChartError(w, err)
+		return
+	}
+
+	period := query.Get("period")
+	if len(period) == 0 {
+		period = string(finance_svc.ChartPeriod6Month)
+	}
+
+	chartPeriod, err := finance_svc.ParseChartPeriod(period)
+	if err != nil {
+		logger.Error(err)
+		svc.writeChartError(w, err)
+		return
+	}
+
+	interval := query.Get("interval")
+	if len(interval) == 0 {
+		interval = string(finance_svc.ChartInteval1Day)
+	}
+
+	chartInterval, err := finance_svc.ParseChartInterval(interval)
+	if err != nil {
+		logger.Error(err)
+		svc.writeChartError(w, err)
+		return
+	}
+
+	err = finance_svc.ValidateChartPeriodInterval(chartPeriod, chartInterval)
+	if err != nil {
+		logger.Error(err)
+		svc.writeChartError(w, err)
+		return
+	}
+
+	image, err := svc.ChartService.GetCompareChartImage(r.Context(), symbols, chartPeriod, chartInterval, options)
+	if err != nil {
+		logger.Error(err)
+		svc.writeChartError(w, err)
+		return
+	}
+
+	svc.writeCachedImage(w, r, image.Data, options.Format.GetContentType(), image.ModTime, image.MaxAge)
+}
@@ -6,6 +6,16 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+// cryptoChartItems are tickers shown on the crypto page
+var cryptoChartItems = []string{
+	"BTC-USD",
+	"ETH-USD",
+	"DOGE-USD",
+	"XRP-USD",
+	"ADA-USD",
+	"BNB-USD",
+}
+
 func (svc *WebSVC) getCryptoHTMLHandler(w http.ResponseWriter, r *http.Request) {
 	logger := log.WithFields(log.Fields{
 		"package":  "WebSVC",
@@ -24,17 +34,7 @@ func (svc *WebSVC) getCryptoHTMLHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Tickers?
-	chartItems := []string{
-		"BTC-USD",
-		"ETH-USD",
-		"DOGE-USD",
-		"XRP-USD",
-		"ADA-USD",
-		"BNB-USD",
-	}
-
-	err = svc.renderChartMapDetailHTML(chartItems, w)
+	err = svc.renderChartMapDetailHTML(r.Context(), cryptoChartItems, w)
 	if err != nil {
 		logger.Error(err)
 		w.Write([]byte(err.Error()))
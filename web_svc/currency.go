@@ -0,0 +1,91 @@
+package web_svc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/iychoi/stock-svc/finance_svc"
+	"github.com/leekchan/accounting"
+)
+
+// currencyFormat is how prices in a currency are shown
+type currencyFormat struct {
+	Symbol    string
+	Precision int
+}
+
+var (
+	currencyFormats = map[string]currencyFormat{
+		"USD": {Symbol: "$", Precision: 2},
+		"KRW": {Symbol: "₩", Precision: 0},
+		"EUR": {Symbol: "€", Precision: 2},
+		"GBP": {Symbol: "£", Precision: 2},
+		"JPY": {Symbol: "¥", Precision: 0},
+		"CNY": {Symbol: "CN¥", Precision: 2},
+		"HKD": {Symbol: "HK$", Precision: 2},
+		"TWD": {Symbol: "NT$", Precision: 2},
+		"CAD": {Symbol: "CA$", Precision: 2},
+	}
+
+	// displayCurrencies are currencies pages can convert prices to
+	displayCurrencies = []string{"USD", "KRW"}
+)
+
+// parseDisplayCurrency checks a display currency, empty shows prices in their own currency
+func parseDisplayCurrency(currency string) (string, error) {
+	if len(currency) == 0 {
+		return "", nil
+	}
+
+	currency = strings.ToUpper(currency)
+	for _, displayCurrency := range displayCurrencies {
+		if currency == displayCurrency {
+			return currency, nil
+		}
+	}
+	return "", fmt.Errorf("display currency must be one of %s - %s", strings.Join(displayCurrencies, ", "), currency)
+}
+
+// getPriceCurrency returns the currency prices of the quote are shown in.
+// Index levels are points, so they are shown without a currency.
+func getPriceCurrency(stockInfo *finance_svc.StockInfo) string {
+	if finance_svc.IsIndexQuote(stockInfo) {
+		return ""
+	}
+	return stockInfo.Currency
+}
+
+// getCurrencyFormat returns the format of a currency. Other currencies are shown with their
+// code, and prices of unknown currency without any symbol.
+// Codes are compared as is, minor units like GBp must not be shown as their major currency.
+func getCurrencyFormat(currency string) currencyFormat {
+	if format, ok := currencyFormats[currency]; ok {
+		return format
+	}
+	return currencyFormat{Symbol: currency, Precision: 2}
+}
+
+// formatPrice formats a price with the symbol and precision of the currency, e.g., $1,234.56, ₩1,234,567
+func formatPrice(price float64, currency string) string {
+	format := getCurrencyFormat(currency)
+
+	ac := accounting.Accounting{
+		Symbol:    format.Symbol,
+		Precision: format.Precision,
+	}
+
+	if _, ok := currencyFormats[currency]; !ok && len(currency) > 0 {
+		// e.g., 1,234.56 CHF
+		ac.Format = "%v %s"
+	}
+	return ac.FormatMoney(price)
+}
+
+// formatPriceChange formats a price change with a sign and the precision of the currency, e.g., +1.23
+func formatPriceChange(change float64, currency string) string {
+	precision := getCurrencyFormat(currency).Precision
+	if change > 0 {
+		return fmt.Sprintf("+%.*f", precision, change)
+	}
+	return fmt.Sprintf("%.*f", precision, change)
+}
@@ -0,0 +1,105 @@
+package web_svc
+
+import (
+	"testing"
+
+	"github.com/iychoi/stock-svc/finance_svc"
+)
+
+func TestFormatPrice(t *testing.T) {
+	tests := []struct {
+		price    float64
+		currency string
+		expected string
+	}{
+		{1234.567, "USD", "$1,234.57"},
+		{0.5, "USD", "$0.50"},
+		{1234567.4, "KRW", "₩1,234,567"},
+		{72.5, "GBP", "£72.50"},
+		{1234.6, "JPY", "¥1,235"},
+		{12.3, "HKD", "HK$12.30"},
+		// other currencies are shown with their code
+		{1234.5, "CHF", "1,234.50 CHF"},
+		// minor units are not shown as their major currency
+		{7250, "GBp", "7,250.00 GBp"},
+		// index levels and quotes of unknown currency have no symbol
+		{3012.346, "", "3,012.35"},
+	}
+
+	for _, test := range tests {
+		formatted := formatPrice(test.price, test.currency)
+		if formatted != test.expected {
+			t.Fatalf("%v %s: expected %q, got %q", test.price, test.currency, test.expected, formatted)
+		}
+	}
+}
+
+func TestFormatPriceChange(t *testing.T) {
+	tests := []struct {
+		change   float64
+		currency string
+		expected string
+	}{
+		{1.234, "USD", "+1.23"},
+		{-1.234, "USD", "-1.23"},
+		{0, "USD", "0.00"},
+		{1300.4, "KRW", "+1300"},
+		{-1300.4, "KRW", "-1300"},
+		{2.5, "", "+2.50"},
+	}
+
+	for _, test := range tests {
+		formatted := formatPriceChange(test.change, test.currency)
+		if formatted != test.expected {
+			t.Fatalf("%v %s: expected %q, got %q", test.change, test.currency, test.expected, formatted)
+		}
+	}
+}
+
+func TestParseDisplayCurrency(t *testing.T) {
+	tests := []struct {
+		currency string
+		expected string
+		valid    bool
+	}{
+		{"", "", true},
+		{"USD", "USD", true},
+		{"krw", "KRW", true},
+		{"EUR", "", false},
+		{"dollar", "", false},
+	}
+
+	for _, test := range tests {
+		currency, err := parseDisplayCurrency(test.currency)
+		if (err == nil) != test.valid {
+			t.Fatalf("%q: expected valid %v, got error %v", test.currency, test.valid, err)
+		}
+
+		if currency != test.expected {
+			t.Fatalf("%q: expected %q, got %q", test.currency, test.expected, currency)
+		}
+	}
+}
+
+func TestGetPriceCurrency(t *testing.T) {
+	tests := []struct {
+		symbol    string
+		currency  string
+		quoteType string
+		expected  string
+	}{
+		{"AAPL", "USD", "EQUITY", "USD"},
+		{"VOD.L", "GBp", "", "GBp"},
+		// index levels are points
+		{"^GSPC", "USD", "", ""},
+		{"^KS11", "KRW", "INDEX", ""},
+		{"DX-Y.NYB", "USD", "INDEX", ""},
+	}
+
+	for _, test := range tests {
+		currency := getPriceCurrency(&finance_svc.StockInfo{Symbol: test.symbol, Currency: test.currency, QuoteType: test.quoteType})
+		if currency != test.expected {
+			t.Fatalf("%s: expected %q, got %q", test.symbol, test.expected, currency)
+		}
+	}
+}
@@ -6,6 +6,33 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+// etfChartItems are tickers shown on the etf page
+var etfChartItems = []string{
+	"FNGU",
+	"SOXL",
+	"TQQQ",
+	"UDOW",
+	"UPRO",
+	"URTY",
+	"TECL",
+	"LABU",
+	"BNKU",
+	"ICLN",
+	"CURE",
+	"KRBN",
+	"JETS",
+	"NRGU",
+	"RETL",
+	"DFEN",
+	"KORU",
+	"NAIL",
+	"TPOR",
+	"VTV",
+	"DRN",
+	"XLB",
+	"DBB",
+}
+
 func (svc *WebSVC) getEtfHTMLHandler(w http.ResponseWriter, r *http.Request) {
 	logger := log.WithFields(log.Fields{
 		"package":  "WebSVC",
@@ -24,34 +51,7 @@ func (svc *WebSVC) getEtfHTMLHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Tickers?
-	chartItems := []string{
-		"FNGU",
-		"SOXL",
-		"TQQQ",
-		"UDOW",
-		"UPRO",
-		"URTY",
-		"TECL",
-		"LABU",
-		"BNKU",
-		"ICLN",
-		"CURE",
-		"KRBN",
-		"JETS",
-		"NRGU",
-		"RETL",
-		"DFEN",
-		"KORU",
-		"NAIL",
-		"TPOR",
-		"VTV",
-		"DRN",
-		"XLB",
-		"DBB",
-	}
-
-	err = svc.renderChartMapDetailHTML(chartItems, w)
+	err = svc.renderChartMapDetailHTML(r.Context(), etfChartItems, w)
 	if err != nil {
 		logger.Error(err)
 		w.Write([]byte(err.Error()))
@@ -6,6 +6,20 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+// faangChartItems are tickers shown on the faang page
+var faangChartItems = []string{
+	"GOOG",
+	"FB",
+	"AMZN",
+	"AAPL",
+	"NVDA",
+	"TSLA",
+	"NFLX",
+	"BABA",
+	"BIDU",
+	"TWTR",
+}
+
 func (svc *WebSVC) getFaangHTMLHandler(w http.ResponseWriter, r *http.Request) {
 	logger := log.WithFields(log.Fields{
 		"package":  "WebSVC",
@@ -24,21 +38,7 @@ func (svc *WebSVC) getFaangHTMLHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Tickers?
-	chartItems := []string{
-		"GOOG",
-		"FB",
-		"AMZN",
-		"AAPL",
-		"NVDA",
-		"TSLA",
-		"NFLX",
-		"BABA",
-		"BIDU",
-		"TWTR",
-	}
-
-	err = svc.renderChartMapDetailHTML(chartItems, w)
+	err = svc.renderChartMapDetailHTML(r.Context(), faangChartItems, w)
 	if err != nil {
 		logger.Error(err)
 		w.Write([]byte(err.Error()))
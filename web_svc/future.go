@@ -6,6 +6,14 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+// futureChartItems are tickers shown on the future page
+var futureChartItems = []string{
+	"YM=F",
+	"ES=F",
+	"NQ=F",
+	"RTY=F",
+}
+
 func (svc *WebSVC) getFutureHTMLHandler(w http.ResponseWriter, r *http.Request) {
 	logger := log.WithFields(log.Fields{
 		"package":  "WebSVC",
@@ -24,15 +32,7 @@ func (svc *WebSVC) getFutureHTMLHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Tickers?
-	chartItems := []string{
-		"YM=F",
-		"ES=F",
-		"NQ=F",
-		"RTY=F",
-	}
-
-	err = svc.renderChartMapHTML(chartItems, w)
+	err = svc.renderChartMapHTML(r.Context(), futureChartItems, w)
 	if err != nil {
 		logger.Error(err)
 		w.Write([]byte(err.Error()))
@@ -6,6 +6,30 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+// growthChartItems are tickers shown on the growth page
+var growthChartItems = []string{
+	"U",
+	"PYPL",
+	"PLTR",
+	"DOCU",
+	"SNAP",
+	"TDOC",
+	"ADBE",
+	"ROKU",
+	"SPOT",
+	"ETSY",
+	"ZG",
+	"EXPE",
+	"ABNB",
+	"UBER",
+	"DIS",
+	"SNOW",
+	"COIN",
+	"AGC",
+	"CHPT",
+	"PAYC",
+}
+
 func (svc *WebSVC) getGrowthHTMLHandler(w http.ResponseWriter, r *http.Request) {
 	logger := log.WithFields(log.Fields{
 		"package":  "WebSVC",
@@ -24,31 +48,7 @@ func (svc *WebSVC) getGrowthHTMLHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Tickers?
-	chartItems := []string{
-		"U",
-		"PYPL",
-		"PLTR",
-		"DOCU",
-		"SNAP",
-		"TDOC",
-		"ADBE",
-		"ROKU",
-		"SPOT",
-		"ETSY",
-		"ZG",
-		"EXPE",
-		"ABNB",
-		"UBER",
-		"DIS",
-		"SNOW",
-		"COIN",
-		"AGC",
-		"CHPT",
-		"PAYC",
-	}
-
-	err = svc.renderChartMapDetailHTML(chartItems, w)
+	err = svc.renderChartMapDetailHTML(r.Context(), growthChartItems, w)
 	if err != nil {
 		logger.Error(err)
 		w.Write([]byte(err.Error()))
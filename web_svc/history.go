@@ -0,0 +1,151 @@
+package web_svc
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/iychoi/stock-svc/finance_svc"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	historyFormatJSON = "json"
+	historyFormatCSV  = "csv"
+)
+
+// getHistoryHandler serves price bars of a symbol,
+// e.g., /api/history/SOXL?period=1y&interval=1d&format=csv or /api/history/SOXL.csv
+func (svc *WebSVC) getHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  "WebSVC",
+		"function": "getHistoryHandler",
+	})
+
+	varMap := mux.Vars(r)
+	symbol, ok := varMap["symbol"]
+	if !ok {
+		w.WriteHeader(500)
+		return
+	}
+
+	query := r.URL.Query()
+
+	format := strings.ToLower(query.Get("format"))
+	// format by extension, e.g., /api/history/SOXL.csv
+	if ext := path.Ext(symbol); ext == "."+historyFormatJSON || ext == "."+historyFormatCSV {
+		format = strings.TrimPrefix(ext, ".")
+		symbol = strings.TrimSuffix(symbol, ext)
+	}
+
+	if len(format) == 0 {
+		format = historyFormatJSON
+	}
+
+	if format != historyFormatJSON && format != historyFormatCSV {
+		svc.writeChartError(w, &finance_svc.InvalidChartParamError{Message: "unknown history format - " + format})
+		return
+	}
+
+	stockSymbol, err := finance_svc.ParseStockSymbol(symbol)
+	if err != nil {
+		logger.Error(err)
+		svc.writeChartError(w, err)
+		return
+	}
+
+	period := query.Get("period")
+	if len(period) == 0 {
+		period = string(finance_svc.ChartPeriod1Year)
+	}
+
+	chartPeriod, err := finance_svc.ParseChartPeriod(period)
+	if err != nil {
+		logger.Error(err)
+		svc.writeChartError(w, err)
+		return
+	}
+
+	interval := query.Get("interval")
+	if len(interval) == 0 {
+		interval = string(finance_svc.ChartInteval1Day)
+	}
+
+	chartInterval, err := finance_svc.ParseChartInterval(interval)
+	if err != nil {
+		logger.Error(err)
+		svc.writeChartError(w, err)
+		return
+	}
+
+	err = finance_svc.ValidateChartPeriodInterval(chartPeriod, chartInterval)
+	if err != nil {
+		logger.Error(err)
+		svc.writeChartError(w, err)
+		return
+	}
+
+	data, err := svc.HistoryService.GetHistory(r.Context(), stockSymbol, chartPeriod, chartInterval)
+	if err != nil {
+		logger.Error(err)
+		svc.writeChartError(w, err)
+		return
+	}
+
+	if format == historyFormatCSV {
+		err = svc.writeHistoryCSV(w, data)
+	} else {
+		err = svc.writeHistoryJSON(w, data)
+	}
+
+	if err != nil {
+		logger.Error(err)
+		return
+	}
+}
+
+func (svc *WebSVC) writeHistoryJSON(w http.ResponseWriter, data *finance_svc.OHLCVData) error {
+	bytes, err := json.Marshal(data)
+	if err != nil {
+		w.WriteHeader(500)
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, err = w.Write(bytes)
+	return err
+}
+
+func (svc *WebSVC) writeHistoryCSV(w http.ResponseWriter, data *finance_svc.OHLCVData) error {
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", "attachment; filename=\""+strings.TrimPrefix(data.Symbol, "^")+"_"+string(data.Period)+"_"+string(data.Interval)+".csv\"")
+
+	writer := csv.NewWriter(w)
+	err := writer.Write([]string{"time", "open", "high", "low", "close", "adj_close", "volume"})
+	if err != nil {
+		return err
+	}
+
+	for _, bar := range data.Bars {
+		err = writer.Write([]string{
+			bar.Time.Format(time.RFC3339),
+			strconv.FormatFloat(bar.Open, 'f', -1, 64),
+			strconv.FormatFloat(bar.High, 'f', -1, 64),
+			strconv.FormatFloat(bar.Low, 'f', -1, 64),
+			strconv.FormatFloat(bar.Close, 'f', -1, 64),
+			strconv.FormatFloat(bar.AdjClose, 'f', -1, 64),
+			strconv.FormatInt(bar.Volume, 10),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,29 @@
+package web_svc
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	// fear & greed index images are updated by the provider during the day
+	indexImageMaxAge = 10 * time.Minute // 10 min
+)
+
+// writeCachedImage writes an image with ETag, Last-Modified and Cache-Control headers.
+// It responds 304 Not Modified when If-None-Match or If-Modified-Since matches the image.
+// A zero modTime omits Last-Modified.
+func (svc *WebSVC) writeCachedImage(w http.ResponseWriter, r *http.Request, data []byte, contentType string, modTime time.Time, maxAge time.Duration) {
+	hash := sha1.Sum(data)
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("ETag", fmt.Sprintf("\"%s\"", hex.EncodeToString(hash[:])))
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int64(maxAge/time.Second)))
+
+	// handles If-None-Match, If-Modified-Since and Range
+	http.ServeContent(w, r, "", modTime, bytes.NewReader(data))
+}
@@ -0,0 +1,74 @@
+package web_svc
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWriteCachedImage(t *testing.T) {
+	svc := &WebSVC{}
+
+	data := []byte("<svg></svg>")
+	hash := sha1.Sum(data)
+	etag := "\"" + hex.EncodeToString(hash[:]) + "\""
+
+	modTime := time.Date(2020, 3, 5, 21, 20, 0, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		modTime         time.Time
+		ifNoneMatch     string
+		ifModifiedSince time.Time
+		expectedStatus  int
+	}{
+		{"no validators", modTime, "", time.Time{}, http.StatusOK},
+		{"etag matches", modTime, etag, time.Time{}, http.StatusNotModified},
+		{"one of etags matches", modTime, "\"other\", " + etag, time.Time{}, http.StatusNotModified},
+		{"etag changed", modTime, "\"other\"", time.Time{}, http.StatusOK},
+		{"not modified since", modTime, "", modTime, http.StatusNotModified},
+		{"not modified since later", modTime, "", modTime.Add(time.Hour), http.StatusNotModified},
+		{"modified since", modTime, "", modTime.Add(-time.Hour), http.StatusOK},
+		// If-None-Match wins over If-Modified-Since
+		{"etag changed, not modified since", modTime, "\"other\"", modTime, http.StatusOK},
+		// images without a modification time are validated by etag only
+		{"no modification time", time.Time{}, "", modTime, http.StatusOK},
+		{"no modification time, etag matches", time.Time{}, etag, time.Time{}, http.StatusNotModified},
+	}
+
+	for _, test := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/chartimg/AAPL/1mo/1d.svg", nil)
+		if len(test.ifNoneMatch) > 0 {
+			r.Header.Set("If-None-Match", test.ifNoneMatch)
+		}
+		if !test.ifModifiedSince.IsZero() {
+			r.Header.Set("If-Modified-Since", test.ifModifiedSince.Format(http.TimeFormat))
+		}
+
+		w := httptest.NewRecorder()
+		svc.writeCachedImage(w, r, data, "image/svg+xml", test.modTime, time.Minute)
+
+		if w.Code != test.expectedStatus {
+			t.Fatalf("%s: expected status %d, got %d", test.name, test.expectedStatus, w.Code)
+		}
+
+		if w.Header().Get("ETag") != etag || w.Header().Get("Cache-Control") != "public, max-age=60" {
+			t.Fatalf("%s: unexpected cache headers - %v", test.name, w.Header())
+		}
+
+		if test.expectedStatus == http.StatusOK {
+			if w.Body.String() != string(data) || w.Header().Get("Content-Type") != "image/svg+xml" {
+				t.Fatalf("%s: unexpected image - %s %q", test.name, w.Header().Get("Content-Type"), w.Body.String())
+			}
+
+			if !test.modTime.IsZero() && w.Header().Get("Last-Modified") != test.modTime.Format(http.TimeFormat) {
+				t.Fatalf("%s: unexpected Last-Modified - %s", test.name, w.Header().Get("Last-Modified"))
+			}
+		} else if w.Body.Len() > 0 {
+			t.Fatalf("%s: expected no body, got %q", test.name, w.Body.String())
+		}
+	}
+}
@@ -6,6 +6,29 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+// indexChartItems are tickers shown on the index page
+var indexChartItems = []string{
+	"^TNX",
+	"DX-Y.NYB",
+	"^VIX",
+	"^GSPC",
+	"^DJI",
+	"^IXIC",
+	"^RUT",
+	"^KS11",
+	"BTC-USD",
+	"ETH-USD",
+	"FNGU",
+	"SOXL",
+	"BNKU",
+	"TQQQ",
+	"UPRO",
+	"URTY",
+	"TECL",
+	"LABU",
+	"ICLN",
+}
+
 func (svc *WebSVC) getIndexHTMLHandler(w http.ResponseWriter, r *http.Request) {
 	logger := log.WithFields(log.Fields{
 		"package":  "WebSVC",
@@ -24,30 +47,7 @@ func (svc *WebSVC) getIndexHTMLHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Tickers?
-	chartItems := []string{
-		"^TNX",
-		"DX-Y.NYB",
-		"^VIX",
-		"^GSPC",
-		"^DJI",
-		"^IXIC",
-		"^RUT",
-		"^KS11",
-		"BTC-USD",
-		"ETH-USD",
-		"FNGU",
-		"SOXL",
-		"BNKU",
-		"TQQQ",
-		"UPRO",
-		"URTY",
-		"TECL",
-		"LABU",
-		"ICLN",
-	}
-
-	err = svc.renderChartMapHTML(chartItems, w)
+	err = svc.renderChartMapHTML(r.Context(), indexChartItems, w)
 	if err != nil {
 		logger.Error(err)
 		w.Write([]byte(err.Error()))
@@ -2,6 +2,7 @@ package web_svc
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gorilla/mux"
 	log "github.com/sirupsen/logrus"
@@ -20,8 +21,6 @@ func (svc *WebSVC) getIndexImageHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	w.Header().Set("Content-Type", "image/png")
-
 	bytes, err := svc.FeerGreedIndexService.GetIndexData(index)
 	if err != nil {
 		logger.Error(err)
@@ -29,10 +28,6 @@ func (svc *WebSVC) getIndexImageHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	_, err = w.Write(bytes)
-	if err != nil {
-		logger.Error(err)
-		w.WriteHeader(500)
-		return
-	}
+	// the provider does not tell when the image changed, ETag validates it
+	svc.writeCachedImage(w, r, bytes, "image/png", time.Time{}, indexImageMaxAge)
 }
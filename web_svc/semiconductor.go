@@ -6,6 +6,22 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+// semiconductorChartItems are tickers shown on the semiconductor page
+var semiconductorChartItems = []string{
+	"NVDA",
+	"TXN",
+	"AVGO",
+	"QCOM",
+	"INTC",
+	"AMAT",
+	"LRCX",
+	"ASML",
+	"ADI",
+	"MU",
+	"TSM",
+	"TER",
+}
+
 func (svc *WebSVC) getSemiconductorHTMLHandler(w http.ResponseWriter, r *http.Request) {
 	logger := log.WithFields(log.Fields{
 		"package":  "WebSVC",
@@ -24,23 +40,7 @@ func (svc *WebSVC) getSemiconductorHTMLHandler(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	// Tickers?
-	chartItems := []string{
-		"NVDA",
-		"TXN",
-		"AVGO",
-		"QCOM",
-		"INTC",
-		"AMAT",
-		"LRCX",
-		"ASML",
-		"ADI",
-		"MU",
-		"TSM",
-		"TER",
-	}
-
-	err = svc.renderChartMapDetailHTML(chartItems, w)
+	err = svc.renderChartMapDetailHTML(r.Context(), semiconductorChartItems, w)
 	if err != nil {
 		logger.Error(err)
 		w.Write([]byte(err.Error()))
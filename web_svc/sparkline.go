@@ -0,0 +1,100 @@
+package web_svc
+
+import (
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/iychoi/stock-svc/finance_svc"
+	log "github.com/sirupsen/logrus"
+)
+
+// getSparklineHandler serves a small trend line of a symbol colored by the day's direction,
+// e.g., /sparkline/SOXL.svg?period=1d&interval=5m&width=120&height=32&theme=dark
+func (svc *WebSVC) getSparklineHandler(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  "WebSVC",
+		"function": "getSparklineHandler",
+	})
+
+	varMap := mux.Vars(r)
+	symbol, ok := varMap["symbol"]
+	if !ok {
+		w.WriteHeader(500)
+		return
+	}
+
+	query := r.URL.Query()
+
+	options := finance_svc.DefaultChartOptions()
+
+	format, err := finance_svc.ParseChartFormat(query.Get("format"))
+	if err != nil {
+		logger.Error(err)
+		svc.writeChartError(w, err)
+		return
+	}
+	options.Format = format
+
+	// format by extension, e.g., /sparkline/SOXL.svg, symbols may contain dots, e.g., 005930.KS
+	ext := strings.ToLower(path.Ext(symbol))
+	if ext == "."+string(finance_svc.ChartFormatPNG) || ext == "."+string(finance_svc.ChartFormatSVG) {
+		options.Format = finance_svc.ChartFormat(strings.TrimPrefix(ext, "."))
+		symbol = symbol[:len(symbol)-len(ext)]
+	}
+
+	err = svc.parseChartImageOptions(query, &options, finance_svc.ParseSparklineSize)
+	if err != nil {
+		logger.Error(err)
+		svc.writeChartError(w, err)
+		return
+	}
+
+	stockSymbol, err := finance_svc.ParseStockSymbol(symbol)
+	if err != nil {
+		logger.Error(err)
+		svc.writeChartError(w, err)
+		return
+	}
+
+	period := query.Get("period")
+	if len(period) == 0 {
+		period = string(finance_svc.ChartPeriod1Day)
+	}
+
+	chartPeriod, err := finance_svc.ParseChartPeriod(period)
+	if err != nil {
+		logger.Error(err)
+		svc.writeChartError(w, err)
+		return
+	}
+
+	interval := query.Get("interval")
+	if len(interval) == 0 {
+		interval = string(finance_svc.ChartInteval5Min)
+	}
+
+	chartInterval, err := finance_svc.ParseChartInterval(interval)
+	if err != nil {
+		logger.Error(err)
+		svc.writeChartError(w, err)
+		return
+	}
+
+	err = finance_svc.ValidateChartPeriodInterval(chartPeriod, chartInterval)
+	if err != nil {
+		logger.Error(err)
+		svc.writeChartError(w, err)
+		return
+	}
+
+	image, err := svc.ChartService.GetSparklineImage(r.Context(), stockSymbol, chartPeriod, chartInterval, options)
+	if err != nil {
+		logger.Error(err)
+		svc.writeChartError(w, err)
+		return
+	}
+
+	svc.writeCachedImage(w, r, image.Data, options.Format.GetContentType(), image.ModTime, image.MaxAge)
+}
@@ -0,0 +1,218 @@
+package web_svc
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/iychoi/stock-svc/finance_svc"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	warmUpWorkers = 4
+)
+
+// warmUpPage is a page and the charts its template shows per ticker
+type warmUpPage struct {
+	Name   string
+	Items  []string
+	Charts []pageChart
+}
+
+// getWarmUpPages returns pages served by the page handlers, the index page first
+func getWarmUpPages() []warmUpPage {
+	return []warmUpPage{
+		{Name: "index", Items: indexChartItems, Charts: chartMapCharts},
+		{Name: "etf", Items: etfChartItems, Charts: chartMapDetailCharts},
+		{Name: "faang", Items: faangChartItems, Charts: chartMapDetailCharts},
+		{Name: "semiconductor", Items: semiconductorChartItems, Charts: chartMapDetailCharts},
+		{Name: "crypto", Items: cryptoChartItems, Charts: chartMapDetailCharts},
+		{Name: "future", Items: futureChartItems, Charts: chartMapCharts},
+		{Name: "growth", Items: growthChartItems, Charts: chartMapDetailCharts},
+		{Name: "basic", Items: basicChartItems, Charts: chartMapDetailCharts},
+	}
+}
+
+// WarmUpStatus is progress of pre-fetching quotes and charts of all pages
+type WarmUpStatus struct {
+	Running      bool      `json:"running"`
+	Done         bool      `json:"done"`
+	StartTime    time.Time `json:"start_time"`
+	FinishTime   time.Time `json:"finish_time"`
+	TotalQuotes  int       `json:"total_quotes"`
+	DoneQuotes   int       `json:"done_quotes"`
+	FailedQuotes int       `json:"failed_quotes"`
+	TotalCharts  int       `json:"total_charts"`
+	DoneCharts   int       `json:"done_charts"`
+	FailedCharts int       `json:"failed_charts"`
+}
+
+// warmUpTask pre-fetches a chart of a symbol
+type warmUpTask struct {
+	Symbol string
+	Chart  *pageChart
+}
+
+// getWarmUpTasks returns symbols of quotes and charts shown in pages, without duplicates
+func getWarmUpTasks(pages []warmUpPage) ([]string, []warmUpTask) {
+	quoteSymbols := []string{}
+	chartTasks := []warmUpTask{}
+	seen := map[string]bool{}
+	for _, page := range pages {
+		for _, symbol := range page.Items {
+			if !seen[symbol] {
+				seen[symbol] = true
+				quoteSymbols = append(quoteSymbols, symbol)
+			}
+
+			for idx := range page.Charts {
+				chart := &page.Charts[idx]
+				key := fmt.Sprintf("%s/%s/%s/%s/%dx%d", symbol, chart.Period, chart.Interval, chart.Format, chart.Width, chart.Height)
+				if !seen[key] {
+					seen[key] = true
+					chartTasks = append(chartTasks, warmUpTask{Symbol: symbol, Chart: chart})
+				}
+			}
+		}
+	}
+	return quoteSymbols, chartTasks
+}
+
+// startWarmUp pre-fetches quotes and charts of all pages in background
+func (svc *WebSVC) startWarmUp() {
+	logger := log.WithFields(log.Fields{
+		"package":  "WebSVC",
+		"function": "startWarmUp",
+	})
+
+	quoteSymbols, chartTasks := getWarmUpTasks(getWarmUpPages())
+
+	svc.WarmUpLock.Lock()
+	svc.WarmUp = WarmUpStatus{
+		Running:     true,
+		StartTime:   time.Now(),
+		TotalQuotes: len(quoteSymbols),
+		TotalCharts: len(chartTasks),
+	}
+	svc.WarmUpLock.Unlock()
+
+	logger.Infof("Warming up %d quotes and %d charts", len(quoteSymbols), len(chartTasks))
+
+	go func() {
+		// quotes first, they are cheap and make pages usable
+		svc.warmUpQuotes(quoteSymbols)
+
+		tasks := make(chan warmUpTask)
+		wg := sync.WaitGroup{}
+		for i := 0; i < warmUpWorkers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for task := range tasks {
+					svc.runWarmUpTask(task)
+				}
+			}()
+		}
+
+	feed:
+		for _, task := range chartTasks {
+			select {
+			case tasks <- task:
+			case <-svc.WarmUpContext.Done():
+				break feed
+			}
+		}
+		close(tasks)
+		wg.Wait()
+
+		svc.WarmUpLock.Lock()
+		svc.WarmUp.Running = false
+		svc.WarmUp.Done = svc.WarmUpContext.Err() == nil
+		svc.WarmUp.FinishTime = time.Now()
+		status := svc.WarmUp
+		svc.WarmUpLock.Unlock()
+
+		logger.Infof("Warm-up finished in %s, %d/%d quotes, %d/%d charts", status.FinishTime.Sub(status.StartTime), status.DoneQuotes-status.FailedQuotes, status.TotalQuotes, status.DoneCharts-status.FailedCharts, status.TotalCharts)
+	}()
+}
+
+// warmUpQuotes pre-fetches quotes of the symbols in a batch
+func (svc *WebSVC) warmUpQuotes(symbols []string) {
+	logger := log.WithFields(log.Fields{
+		"package":  "WebSVC",
+		"function": "warmUpQuotes",
+	})
+
+	results := svc.PriceService.GetStockInfos(svc.WarmUpContext, symbols)
+
+	svc.WarmUpLock.Lock()
+	defer svc.WarmUpLock.Unlock()
+
+	for _, result := range results {
+		svc.WarmUp.DoneQuotes++
+		if result.Err != nil {
+			logger.Warnf("could not warm up %s - %s", result.Symbol, result.Err)
+			svc.WarmUp.FailedQuotes++
+		}
+	}
+}
+
+func (svc *WebSVC) runWarmUpTask(task warmUpTask) {
+	logger := log.WithFields(log.Fields{
+		"package":  "WebSVC",
+		"function": "runWarmUpTask",
+	})
+
+	options := finance_svc.DefaultChartOptions()
+	options.Format = task.Chart.Format
+	options.Width = task.Chart.Width
+	options.Height = task.Chart.Height
+
+	_, err := svc.ChartService.GetChartData(svc.WarmUpContext, task.Symbol, task.Chart.Period, task.Chart.Interval, options)
+	if err != nil {
+		logger.Warnf("could not warm up %s - %s", task.Symbol, err)
+	}
+
+	svc.WarmUpLock.Lock()
+	defer svc.WarmUpLock.Unlock()
+
+	svc.WarmUp.DoneCharts++
+	if err != nil {
+		svc.WarmUp.FailedCharts++
+	}
+}
+
+// GetWarmUpStatus returns progress of the warm-up
+func (svc *WebSVC) GetWarmUpStatus() WarmUpStatus {
+	svc.WarmUpLock.Lock()
+	defer svc.WarmUpLock.Unlock()
+
+	return svc.WarmUp
+}
+
+// getWarmUpStatusHandler serves progress of the warm-up as json, e.g., /api/status/warmup
+func (svc *WebSVC) getWarmUpStatusHandler(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(log.Fields{
+		"package":  "WebSVC",
+		"function": "getWarmUpStatusHandler",
+	})
+
+	bytes, err := json.Marshal(svc.GetWarmUpStatus())
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(500)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	_, err = w.Write(bytes)
+	if err != nil {
+		logger.Error(err)
+		return
+	}
+}
@@ -0,0 +1,35 @@
+package web_svc
+
+import (
+	"testing"
+
+	"github.com/iychoi/stock-svc/finance_svc"
+)
+
+func TestGetWarmUpTasks(t *testing.T) {
+	png := pageChart{Period: finance_svc.ChartPeriod1Month, Interval: finance_svc.ChartInteval1Day, Format: finance_svc.ChartFormatPNG, Width: 400, Height: 300}
+	svg := png
+	svg.Format = finance_svc.ChartFormatSVG
+	large := png
+	large.Width = 800
+
+	tests := []struct {
+		name           string
+		pages          []warmUpPage
+		expectedQuotes int
+		expectedCharts int
+	}{
+		{"single page", []warmUpPage{{Items: []string{"AAPL", "MSFT"}, Charts: []pageChart{png}}}, 2, 2},
+		{"symbol in pages", []warmUpPage{{Items: []string{"AAPL"}, Charts: []pageChart{png}}, {Items: []string{"AAPL"}, Charts: []pageChart{png}}}, 1, 1},
+		// charts differing only in format are rendered separately
+		{"formats", []warmUpPage{{Items: []string{"AAPL"}, Charts: []pageChart{png}}, {Items: []string{"AAPL"}, Charts: []pageChart{svg}}}, 1, 2},
+		{"sizes", []warmUpPage{{Items: []string{"AAPL"}, Charts: []pageChart{png, large}}}, 1, 2},
+	}
+
+	for _, test := range tests {
+		quoteSymbols, chartTasks := getWarmUpTasks(test.pages)
+		if len(quoteSymbols) != test.expectedQuotes || len(chartTasks) != test.expectedCharts {
+			t.Fatalf("%s: expected %d quotes and %d charts, got %d and %d", test.name, test.expectedQuotes, test.expectedCharts, len(quoteSymbols), len(chartTasks))
+		}
+	}
+}
@@ -1,9 +1,11 @@
 package web_svc
 
 import (
+	"context"
 	"io"
 	"io/ioutil"
 	"net/http"
+	"sync"
 
 	"github.com/gorilla/mux"
 	"github.com/iychoi/stock-svc/finance_svc"
@@ -27,24 +29,56 @@ type WebSVC struct {
 	ChartService          *finance_svc.ChartSVC
 	PriceService          *finance_svc.PriceSVC
 	FeerGreedIndexService *finance_svc.FearGreedIndexSVC
+	HistoryService        *finance_svc.HistorySVC
+	// DisplayCurrency is the currency prices are converted to in pages, empty for their own currency.
+	// Indexes are not converted, but only those starting with ^ or of quote type INDEX are known,
+	// e.g., DX-Y.NYB quoted by a provider without quote types is converted like a price.
+	DisplayCurrency string
 
 	WebServer *http.Server
+	// AdminRouter serves admin pages on a separate listener, e.g., localhost only
+	AdminRouter *mux.Router
+	AdminServer *http.Server
+	// AdminAddress is the address of the admin listener, requests naming other hosts are rejected
+	AdminAddress string
+	// AdminToken is posted with forms of admin pages, pages of other sites cannot read it
+	AdminToken string
+
+	WarmUp     WarmUpStatus
+	WarmUpLock sync.Mutex
+	// WarmUpContext is cancelled when the service closes
+	WarmUpContext context.Context
+	WarmUpCancel  context.CancelFunc
 }
 
 // InitWebSVC ...
-func InitWebSVC(timeService *finance_svc.TimeSVC, chartService *finance_svc.ChartSVC, priceService *finance_svc.PriceSVC, feerGreedService *finance_svc.FearGreedIndexSVC) (*WebSVC, error) {
+func InitWebSVC(timeService *finance_svc.TimeSVC, chartService *finance_svc.ChartSVC, priceService *finance_svc.PriceSVC, feerGreedService *finance_svc.FearGreedIndexSVC, historyService *finance_svc.HistorySVC, displayCurrency string, adminAddress string) (*WebSVC, error) {
 	logger := log.WithFields(log.Fields{
 		"package":  "WebSVC",
 		"function": "InitWebSVC",
 	})
 
+	displayCurrency, err := parseDisplayCurrency(displayCurrency)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	warmUpContext, warmUpCancel := context.WithCancel(context.Background())
+
 	webSVC := &WebSVC{
 		Router:                mux.NewRouter(),
 		TimeService:           timeService,
 		ChartService:          chartService,
 		PriceService:          priceService,
 		FeerGreedIndexService: feerGreedService,
+		HistoryService:        historyService,
+		DisplayCurrency:       displayCurrency,
 		WebServer:             nil,
+		AdminRouter:           mux.NewRouter(),
+		AdminServer:           nil,
+		WarmUpContext:         warmUpContext,
+		WarmUpCancel:          warmUpCancel,
 	}
 
 	webSVC.addHandlers()
@@ -63,6 +97,36 @@ func InitWebSVC(timeService *finance_svc.TimeSVC, chartService *finance_svc.Char
 		}
 	}()
 
+	// admin pages are not served on the public listener
+	if len(adminAddress) > 0 {
+		adminToken, err := newAdminToken()
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+
+		webSVC.AdminAddress = adminAddress
+		webSVC.AdminToken = adminToken
+		webSVC.addAdminHandlers()
+
+		adminServer := &http.Server{
+			Addr:    adminAddress,
+			Handler: webSVC.AdminRouter,
+		}
+
+		webSVC.AdminServer = adminServer
+
+		go func() {
+			err := adminServer.ListenAndServe()
+			if err != nil {
+				logger.Error(err)
+			}
+		}()
+	}
+
+	// render charts of pages before the first visit
+	webSVC.startWarmUp()
+
 	return webSVC, nil
 }
 
@@ -73,6 +137,16 @@ func (svc *WebSVC) Close() error {
 		"function": "Close",
 	})
 
+	svc.WarmUpCancel()
+
+	if svc.AdminServer != nil {
+		err := svc.AdminServer.Close()
+		if err != nil {
+			logger.Error(err)
+			return err
+		}
+	}
+
 	err := svc.WebServer.Close()
 	if err != nil {
 		logger.Error(err)
@@ -95,9 +169,23 @@ func (svc *WebSVC) addHandlers() {
 	svc.Router.HandleFunc("/basic", svc.getBasicHTMLHandler).Methods("GET")
 
 	// stock images
+	svc.Router.HandleFunc("/chartimg/compare", svc.getCompareChartImageHandler).Methods("GET")
 	svc.Router.HandleFunc("/chartimg/{symbol}/{period}/{interval}", svc.getChartImageHandler).Methods("GET")
+	// sparklines for compact tiles
+	svc.Router.HandleFunc("/sparkline/{symbol}", svc.getSparklineHandler).Methods("GET")
 	// index images
 	svc.Router.HandleFunc("/indeximg/{index}", svc.getIndexImageHandler).Methods("GET")
+	// price history
+	svc.Router.HandleFunc("/api/history/{symbol}", svc.getHistoryHandler).Methods("GET")
+	// service status
+	svc.Router.HandleFunc("/api/status/warmup", svc.getWarmUpStatusHandler).Methods("GET")
+}
+
+// addAdminHandlers registers admin pages to the admin router
+func (svc *WebSVC) addAdminHandlers() {
+	svc.AdminRouter.HandleFunc("/admin", svc.requireAdminHost(svc.getAdminHTMLHandler)).Methods("GET")
+	svc.AdminRouter.HandleFunc("/admin/charts/renew", svc.requireAdminPost(svc.postAdminChartRenewHandler)).Methods("POST")
+	svc.AdminRouter.HandleFunc("/admin/charts/evict", svc.requireAdminPost(svc.postAdminChartEvictHandler)).Methods("POST")
 }
 
 // writeHTMLHeader ...